@@ -0,0 +1,209 @@
+package backtest
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/nexus-bot-swarm/candles"
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// Engine replays a historical series against a set of strategies
+// Each step first moves the pool (price observation or recorded swap),
+// then every bot gets a chance to trade on the updated pool
+type Engine struct {
+	pool     *domain.Pool
+	accounts []*account
+	history  *candles.Series
+	now      time.Time // time of the current step, the pool clock
+}
+
+// account is the backtest bookkeeping for one bot
+// Balances and P&L live in the bot portfolio, drawdown is tracked here
+type account struct {
+	id          int
+	strategy    swarm.Strategy
+	portfolio   *swarm.Portfolio
+	peakValue   float64
+	maxDrawdown float64
+}
+
+// NewEngine creates an engine over the given pool
+// Every strategy becomes one bot funded with balanceA/balanceB
+func NewEngine(pool *domain.Pool, strategies []swarm.Strategy, balanceA, balanceB *big.Int) *Engine {
+	price := pool.PriceAInB()
+
+	accounts := make([]*account, len(strategies))
+	for i, s := range strategies {
+		portfolio := swarm.NewPortfolio(balanceA, balanceB, price)
+		accounts[i] = &account{
+			id:        i + 1,
+			strategy:  s,
+			portfolio: portfolio,
+			peakValue: portfolio.Value(price),
+		}
+	}
+
+	return &Engine{
+		pool:     pool,
+		accounts: accounts,
+	}
+}
+
+// SetPriceHistory records the pool price of every step into series,
+// and hands it to the strategies that read past prices
+func (e *Engine) SetPriceHistory(series *candles.Series) {
+	e.history = series
+	for _, acc := range e.accounts {
+		if h, ok := acc.strategy.(swarm.HistoryAware); ok {
+			h.SetHistory(series)
+		}
+	}
+}
+
+// Run replays all steps and returns the report
+func (e *Engine) Run(steps []Step) (*Report, error) {
+	report := &Report{
+		Steps:      len(steps),
+		StartPrice: e.pool.PriceAInB(),
+	}
+	if len(steps) > 0 {
+		report.Start = steps[0].Time
+		report.End = steps[len(steps)-1].Time
+
+		// swap events and the TWAP oracle follow the replayed time
+		e.now = steps[0].Time
+		e.pool.SetClock(func() time.Time { return e.now })
+	}
+
+	for i, step := range steps {
+		e.now = step.Time
+		if err := e.apply(step); err != nil {
+			return nil, fmt.Errorf("step %d (%s): %w", i, step.Time.Format(time.RFC3339), err)
+		}
+		if e.history != nil {
+			e.history.Observe(step.Time, e.pool.PriceAInB())
+		}
+
+		for _, acc := range e.accounts {
+			e.trade(acc)
+		}
+
+		price := e.pool.PriceAInB()
+		for _, acc := range e.accounts {
+			acc.mark(price)
+		}
+	}
+
+	report.EndPrice = e.pool.PriceAInB()
+	for _, acc := range e.accounts {
+		report.Bots = append(report.Bots, acc.result(report.EndPrice))
+	}
+	return report, nil
+}
+
+// apply moves the pool according to a historical step
+func (e *Engine) apply(step Step) error {
+	if step.Swap != nil {
+		_, err := swarm.Execute(e.pool, step.Swap)
+		return err
+	}
+	return MoveToPrice(e.pool, step.Price)
+}
+
+// trade lets one bot act on the pool
+// Orders the bot cannot afford are rejected by its portfolio
+func (e *Engine) trade(acc *account) {
+	order := acc.strategy.Next(e.pool)
+	if order == nil {
+		return
+	}
+	order.BotID = acc.id
+	_, _ = acc.portfolio.Swap(e.pool, order)
+}
+
+// mark updates peak value and drawdown at the current price
+func (a *account) mark(priceAInB float64) {
+	v := a.portfolio.Value(priceAInB)
+	if v > a.peakValue {
+		a.peakValue = v
+	}
+	if a.peakValue > 0 {
+		if dd := (a.peakValue - v) / a.peakValue; dd > a.maxDrawdown {
+			a.maxDrawdown = dd
+		}
+	}
+}
+
+func (a *account) result(priceAInB float64) BotResult {
+	snap := a.portfolio.Snapshot(priceAInB)
+
+	pnlPct := 0.0
+	if initial := a.portfolio.Value(priceAInB) - snap.PnL; initial > 0 {
+		pnlPct = snap.PnL / initial * 100
+	}
+
+	return BotResult{
+		BotID:       a.id,
+		Strategy:    a.strategy.Name(),
+		Trades:      snap.Trades,
+		Rejected:    snap.Rejected,
+		BalanceA:    snap.BalanceA.String(),
+		BalanceB:    snap.BalanceB.String(),
+		Realized:    snap.Realized,
+		PnL:         snap.PnL,
+		PnLPercent:  pnlPct,
+		MaxDrawdown: a.maxDrawdown * 100,
+	}
+}
+
+// MoveToPrice trades against the pool as an external arbitrageur
+// until the spot price of A in B matches the target price
+// Constant product means the new reserves are A' = sqrt(k/p), B' = sqrt(k*p)
+// A price the pool cannot reach without leaving its reserve bounds is clamped:
+// the pool moves as far as MinimumLiquidity and MaxReserve allow
+func MoveToPrice(pool *domain.Pool, price float64) error {
+	if price <= 0 {
+		return fmt.Errorf("price must be positive")
+	}
+
+	snap := pool.Snapshot()
+	k := new(big.Float).SetInt(snap.K)
+	p := big.NewFloat(price)
+
+	targetA, _ := new(big.Float).Sqrt(new(big.Float).Quo(k, p)).Int(nil)
+	if delta := new(big.Int).Sub(targetA, snap.ReserveA); delta.Sign() > 0 {
+		// A is too expensive: sell A into the pool
+		return swapAtMost(pool, domain.AToB, delta, snap.ReserveA, snap.ReserveB)
+	}
+
+	targetB, _ := new(big.Float).Sqrt(new(big.Float).Mul(k, p)).Int(nil)
+	if delta := new(big.Int).Sub(targetB, snap.ReserveB); delta.Sign() > 0 {
+		// A is too cheap: buy A with B
+		return swapAtMost(pool, domain.BToA, delta, snap.ReserveB, snap.ReserveA)
+	}
+	return nil
+}
+
+// swapAtMost swaps amountIn, reduced to the largest input the pool accepts:
+// reserveIn stays at most MaxReserve and reserveOut at least MinimumLiquidity
+// With out = reserveOut*in / (reserveIn+in), the floor holds while
+// in <= reserveIn * (reserveOut - MinimumLiquidity) / MinimumLiquidity
+func swapAtMost(pool *domain.Pool, direction domain.Direction, amountIn, reserveIn, reserveOut *big.Int) error {
+	minimum := big.NewInt(domain.MinimumLiquidity)
+	limit := new(big.Int).Sub(reserveOut, minimum)
+	limit.Mul(limit, reserveIn).Quo(limit, minimum)
+	if room := new(big.Int).Sub(domain.MaxReserve, reserveIn); room.Cmp(limit) < 0 {
+		limit = room
+	}
+	if amountIn.Cmp(limit) > 0 {
+		amountIn = limit
+	}
+	if amountIn.Sign() <= 0 {
+		return nil
+	}
+	_, err := pool.Swap(0, direction, amountIn)
+	return err
+}
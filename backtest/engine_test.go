@@ -0,0 +1,156 @@
+package backtest
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexus-bot-swarm/candles"
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+func TestMoveToPrice(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000000), big.NewInt(2000000000))
+
+	for _, target := range []float64{2.5, 1.5, 2.0} {
+		if err := MoveToPrice(pool, target); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := pool.PriceAInB(); math.Abs(got-target)/target > 1e-6 {
+			t.Errorf("expected price %f, got %f", target, got)
+		}
+	}
+}
+
+func TestMoveToPrice_Clamped(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+
+	// a 1e9 price jump would take the ETH reserve far below the minimum
+	if err := MoveToPrice(pool, 1e9); err != nil {
+		t.Fatalf("unreachable price should be clamped, got %v", err)
+	}
+	reserveA, _ := pool.Reserves()
+	if reserveA.Cmp(big.NewInt(domain.MinimumLiquidity)) < 0 {
+		t.Errorf("reserve A %s dropped below the minimum liquidity", reserveA)
+	}
+	if high := pool.PriceAInB(); high < 1000 {
+		t.Errorf("pool should move towards the target, price is %f", high)
+	}
+
+	if err := MoveToPrice(pool, 1e-12); err != nil {
+		t.Fatalf("unreachable price should be clamped, got %v", err)
+	}
+	_, reserveB := pool.Reserves()
+	if reserveB.Cmp(big.NewInt(domain.MinimumLiquidity)) < 0 {
+		t.Errorf("reserve B %s dropped below the minimum liquidity", reserveB)
+	}
+}
+
+func TestEngine_Run_PriceSeries(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000000), big.NewInt(2000000000))
+
+	strategies := []swarm.Strategy{
+		swarm.NewRandomStrategy(100, rand.New(rand.NewSource(1))),
+		swarm.NewMeanReversionStrategy(2.0, 0.01, 1000),
+	}
+	engine := NewEngine(pool, strategies, big.NewInt(1000000), big.NewInt(2000000))
+
+	start := time.Unix(1704067200, 0)
+	prices := []float64{2.0, 2.1, 2.2, 2.05, 1.9, 1.8, 2.0}
+	steps := make([]Step, len(prices))
+	for i, p := range prices {
+		steps[i] = Step{Time: start.Add(time.Duration(i) * time.Minute), Price: p}
+	}
+
+	report, err := engine.Run(steps)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Steps != len(prices) {
+		t.Errorf("expected %d steps, got %d", len(prices), report.Steps)
+	}
+	if len(report.Bots) != 2 {
+		t.Fatalf("expected 2 bot results, got %d", len(report.Bots))
+	}
+
+	random := report.Bots[0]
+	if random.Trades+random.Rejected != len(prices) {
+		t.Errorf("random bot should act every step, got %d trades + %d rejected", random.Trades, random.Rejected)
+	}
+
+	meanRev := report.Bots[1]
+	if meanRev.Trades == 0 {
+		t.Error("mean reversion bot should trade on 10% price moves")
+	}
+	if meanRev.MaxDrawdown < 0 {
+		t.Errorf("drawdown should not be negative, got %f", meanRev.MaxDrawdown)
+	}
+}
+
+func TestEngine_Run_PriceHistory(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000000), big.NewInt(2000000000))
+	engine := NewEngine(pool, []swarm.Strategy{swarm.NewMomentumStrategy(2, 4, 1000)}, big.NewInt(1000000), big.NewInt(2000000))
+	history := candles.NewSeries(time.Minute, 0)
+	engine.SetPriceHistory(history)
+
+	start := time.Unix(1704067200, 0)
+	prices := []float64{2.0, 2.0, 2.0, 2.2, 2.4, 2.6}
+	steps := make([]Step, len(prices))
+	for i, p := range prices {
+		steps[i] = Step{Time: start.Add(time.Duration(i) * time.Minute), Price: p}
+	}
+
+	report, err := engine.Run(steps)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if history.Len() != len(prices) {
+		t.Errorf("expected one candle per step, got %d", history.Len())
+	}
+	// the pool oracle follows the replayed time
+	if _, _, err := pool.TWAP(5 * time.Minute); err != nil {
+		t.Errorf("unexpected twap error over the replayed window: %v", err)
+	}
+	// the uptrend shows after 4 candles: the momentum bot buys A
+	if report.Bots[0].Trades == 0 {
+		t.Error("momentum bot should trade once the history is long enough")
+	}
+}
+
+func TestEngine_Run_RejectsUnaffordableOrders(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+
+	// mean reversion wants to sell 1000 A but the bot holds none
+	strategies := []swarm.Strategy{swarm.NewMeanReversionStrategy(2.0, 0.01, 1000)}
+	engine := NewEngine(pool, strategies, big.NewInt(0), big.NewInt(0))
+
+	report, err := engine.Run([]Step{{Time: time.Unix(0, 0), Price: 3.0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Bots[0].Trades != 0 || report.Bots[0].Rejected != 1 {
+		t.Errorf("expected 0 trades and 1 rejection, got %d and %d", report.Bots[0].Trades, report.Bots[0].Rejected)
+	}
+}
+
+func TestReport_WriteText(t *testing.T) {
+	report := &Report{
+		Steps: 1,
+		Bots:  []BotResult{{BotID: 1, Strategy: "random", Trades: 3}},
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "random") {
+		t.Errorf("expected strategy name in output, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,194 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// Step is one entry of a historical series
+// It is either a price observation (Price > 0) or a recorded swap (Swap != nil)
+type Step struct {
+	Time  time.Time
+	Price float64
+	Swap  *swarm.Order
+}
+
+// jsonStep is the JSONL wire format of a Step
+// {"timestamp": "2024-01-01T00:00:00Z", "price": 2.01}
+// {"timestamp": 1704067200, "direction": "A->B", "amount_in": "100"}
+type jsonStep struct {
+	Timestamp json.RawMessage `json:"timestamp"`
+	Price     float64         `json:"price"`
+	Direction string          `json:"direction"`
+	AmountIn  string          `json:"amount_in"`
+}
+
+// LoadFile loads a history file, picking the format from the extension
+// .csv and .jsonl (or .ndjson) are supported
+func LoadFile(path string) ([]Step, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return LoadCSV(f)
+	case ".jsonl", ".ndjson":
+		return LoadJSONL(f)
+	default:
+		return nil, fmt.Errorf("unsupported history format: %s", path)
+	}
+}
+
+// LoadCSV reads a history from CSV with a header row
+// Price series use columns timestamp,price
+// Swap histories use columns timestamp,direction,amount_in
+func LoadCSV(r io.Reader) ([]Step, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["timestamp"]; !ok {
+		return nil, fmt.Errorf("csv is missing a timestamp column")
+	}
+	_, hasPrice := columns["price"]
+	_, hasAmount := columns["amount_in"]
+	if !hasPrice && !hasAmount {
+		return nil, fmt.Errorf("csv needs a price or amount_in column")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var steps []Step
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		step, err := parseStep(field(record, "timestamp"), field(record, "price"), field(record, "direction"), field(record, "amount_in"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// LoadJSONL reads a history with one JSON object per line
+func LoadJSONL(r io.Reader) ([]Step, error) {
+	scanner := bufio.NewScanner(r)
+
+	var steps []Step
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var js jsonStep
+		if err := json.Unmarshal([]byte(text), &js); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		price := ""
+		if js.Price != 0 {
+			price = strconv.FormatFloat(js.Price, 'f', -1, 64)
+		}
+		timestamp := strings.Trim(string(js.Timestamp), `"`)
+
+		step, err := parseStep(timestamp, price, js.Direction, js.AmountIn)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		steps = append(steps, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read jsonl: %w", err)
+	}
+	return steps, nil
+}
+
+// parseStep builds a step from raw string fields
+func parseStep(timestamp, price, direction, amountIn string) (Step, error) {
+	ts, err := parseTimestamp(timestamp)
+	if err != nil {
+		return Step{}, err
+	}
+
+	if price != "" {
+		p, err := strconv.ParseFloat(price, 64)
+		if err != nil || p <= 0 {
+			return Step{}, fmt.Errorf("invalid price: %q", price)
+		}
+		return Step{Time: ts, Price: p}, nil
+	}
+
+	if amountIn == "" {
+		return Step{}, fmt.Errorf("step has neither price nor amount_in")
+	}
+	amount, ok := new(big.Int).SetString(amountIn, 10)
+	if !ok || amount.Sign() <= 0 {
+		return Step{}, fmt.Errorf("invalid amount_in: %q", amountIn)
+	}
+	dir, err := parseDirection(direction)
+	if err != nil {
+		return Step{}, err
+	}
+	return Step{Time: ts, Swap: &swarm.Order{Direction: dir, AmountIn: amount}}, nil
+}
+
+// parseTimestamp accepts unix seconds or RFC3339
+func parseTimestamp(s string) (time.Time, error) {
+	if s == "" || s == "null" {
+		return time.Time{}, fmt.Errorf("missing timestamp")
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	ts, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp: %q", s)
+	}
+	return ts, nil
+}
+
+func parseDirection(s string) (swarm.Direction, error) {
+	switch strings.ToLower(s) {
+	case "a->b", "atob", "sell":
+		return swarm.AToB, nil
+	case "b->a", "btoa", "buy":
+		return swarm.BToA, nil
+	default:
+		return 0, fmt.Errorf("invalid direction: %q", s)
+	}
+}
@@ -0,0 +1,82 @@
+package backtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+func TestLoadCSV_Prices(t *testing.T) {
+	data := `timestamp,price
+1704067200,2.0
+2024-01-01T00:01:00Z,2.1
+`
+	steps, err := LoadCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(steps))
+	}
+	if steps[0].Price != 2.0 || steps[1].Price != 2.1 {
+		t.Errorf("unexpected prices: %f, %f", steps[0].Price, steps[1].Price)
+	}
+	if steps[1].Time.Sub(steps[0].Time).Seconds() != 60 {
+		t.Errorf("expected 60s between steps, got %s", steps[1].Time.Sub(steps[0].Time))
+	}
+}
+
+func TestLoadCSV_Swaps(t *testing.T) {
+	data := `timestamp,direction,amount_in
+1704067200,A->B,100
+1704067201,B->A,250
+`
+	steps, err := LoadCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if steps[0].Swap == nil || steps[0].Swap.Direction != swarm.AToB {
+		t.Errorf("expected first step to be an A->B swap")
+	}
+	if steps[1].Swap == nil || steps[1].Swap.AmountIn.Int64() != 250 {
+		t.Errorf("expected second step to swap 250")
+	}
+}
+
+func TestLoadCSV_MissingColumns(t *testing.T) {
+	_, err := LoadCSV(strings.NewReader("timestamp,volume\n1,2\n"))
+	if err == nil {
+		t.Error("expected error for csv without price or amount_in")
+	}
+}
+
+func TestLoadJSONL(t *testing.T) {
+	data := `{"timestamp": 1704067200, "price": 2.0}
+
+{"timestamp": "2024-01-01T00:00:01Z", "direction": "sell", "amount_in": "42"}
+`
+	steps, err := LoadJSONL(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(steps))
+	}
+	if steps[0].Price != 2.0 {
+		t.Errorf("expected price 2.0, got %f", steps[0].Price)
+	}
+	if steps[1].Swap == nil || steps[1].Swap.AmountIn.Int64() != 42 {
+		t.Error("expected swap of 42")
+	}
+}
+
+func TestLoadJSONL_InvalidLine(t *testing.T) {
+	_, err := LoadJSONL(strings.NewReader(`{"timestamp": 1, "price": -3}`))
+	if err == nil {
+		t.Error("expected error for negative price")
+	}
+}
@@ -0,0 +1,56 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Report is the outcome of a backtest run
+type Report struct {
+	Steps      int         `json:"steps"`
+	Start      time.Time   `json:"start"`
+	End        time.Time   `json:"end"`
+	StartPrice float64     `json:"start_price"`
+	EndPrice   float64     `json:"end_price"`
+	Bots       []BotResult `json:"bots"`
+}
+
+// BotResult holds per-bot performance
+// PnL is expressed in TokenB, marked at the final pool price
+type BotResult struct {
+	BotID       int     `json:"bot_id"`
+	Strategy    string  `json:"strategy"`
+	Trades      int     `json:"trades"`
+	Rejected    int     `json:"rejected"`
+	BalanceA    string  `json:"balance_a"`
+	BalanceB    string  `json:"balance_b"`
+	Realized    float64 `json:"realized_pnl"`
+	PnL         float64 `json:"pnl"`
+	PnLPercent  float64 `json:"pnl_percent"`
+	MaxDrawdown float64 `json:"max_drawdown_percent"`
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report as a human readable table
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Backtest: %d steps (%s -> %s)\n",
+		r.Steps, r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339))
+	fmt.Fprintf(w, "Price: %.6f -> %.6f\n\n", r.StartPrice, r.EndPrice)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BOT\tSTRATEGY\tTRADES\tREJECTED\tREALIZED\tPNL\tPNL %\tMAX DD %")
+	for _, b := range r.Bots {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%.2f\t%.2f\t%.4f\t%.4f\n",
+			b.BotID, b.Strategy, b.Trades, b.Rejected, b.Realized, b.PnL, b.PnLPercent, b.MaxDrawdown)
+	}
+	return tw.Flush()
+}
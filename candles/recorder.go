@@ -0,0 +1,146 @@
+package candles
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/nexus-bot-swarm/domain"
+)
+
+// DefaultIntervals are the candle intervals recorded when none are configured
+var DefaultIntervals = []time.Duration{time.Second, time.Minute, 5 * time.Minute}
+
+// Recorder builds candles at several intervals from the swaps of a pool
+type Recorder struct {
+	series []*Series
+}
+
+// NewRecorder creates a recorder with one series per interval
+// keeping up to max candles each (0 = DefaultMaxCandles)
+func NewRecorder(intervals []time.Duration, max int) (*Recorder, error) {
+	if len(intervals) == 0 {
+		return nil, fmt.Errorf("at least one candle interval is required")
+	}
+
+	r := &Recorder{}
+	seen := make(map[time.Duration]bool)
+	for _, interval := range intervals {
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid candle interval: %s", interval)
+		}
+		if seen[interval] {
+			return nil, fmt.Errorf("duplicate candle interval: %s", interval)
+		}
+		seen[interval] = true
+		r.series = append(r.series, NewSeries(interval, max))
+	}
+	return r, nil
+}
+
+// Add folds a swap event into every series
+func (r *Recorder) Add(e domain.SwapEvent) {
+	for _, s := range r.series {
+		s.Add(e)
+	}
+}
+
+// Run consumes a pool subscription until it is closed
+func (r *Recorder) Run(sub *domain.Subscription) {
+	for e := range sub.Events() {
+		r.Add(e)
+	}
+}
+
+// Series returns the series of an interval, or nil if it is not recorded
+func (r *Recorder) Series(interval time.Duration) *Series {
+	for _, s := range r.series {
+		if s.interval == interval {
+			return s
+		}
+	}
+	return nil
+}
+
+// Finest returns the series with the shortest interval
+func (r *Recorder) Finest() *Series {
+	finest := r.series[0]
+	for _, s := range r.series[1:] {
+		if s.interval < finest.interval {
+			finest = s
+		}
+	}
+	return finest
+}
+
+// WriteFiles writes one CSV per interval, named candles-<interval>-<start>.csv
+// e.g. candles-5m-20250101-120000.csv
+func (r *Recorder) WriteFiles(dir string, start time.Time) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create candle dir: %w", err)
+	}
+
+	var paths []string
+	for _, s := range r.series {
+		path := filepath.Join(dir, fmt.Sprintf("candles-%s-%s.csv", label(s.interval), start.Format("20060102-150405")))
+		file, err := os.Create(path)
+		if err != nil {
+			return paths, fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		if err := s.WriteCSV(file); err != nil {
+			file.Close()
+			return paths, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if err := file.Close(); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// WriteCSV writes the candles with a header row, oldest first
+func (s *Series) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"start", "open", "high", "low", "close", "volume_a", "volume_b", "swaps"}); err != nil {
+		return err
+	}
+	for _, c := range s.Candles() {
+		err := cw.Write([]string{
+			c.Start.UTC().Format(time.RFC3339),
+			formatPrice(c.Open),
+			formatPrice(c.High),
+			formatPrice(c.Low),
+			formatPrice(c.Close),
+			c.VolumeA.String(),
+			c.VolumeB.String(),
+			strconv.Itoa(c.Swaps),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// label formats an interval for file names: 1s, 5m, 1h rather than 5m0s
+func label(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return d.String()
+}
+
+func formatPrice(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
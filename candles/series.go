@@ -0,0 +1,188 @@
+package candles
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/nexus-bot-swarm/domain"
+)
+
+// DefaultMaxCandles is how many candles a series keeps per interval
+const DefaultMaxCandles = 1000
+
+// Candle is the OHLCV summary of the pool over one interval
+// Prices are TokenA in TokenB, volumes are the amounts of each token that
+// went through the pool in either direction
+type Candle struct {
+	Start   time.Time `json:"start"`
+	Open    float64   `json:"open"`
+	High    float64   `json:"high"`
+	Low     float64   `json:"low"`
+	Close   float64   `json:"close"`
+	VolumeA *big.Int  `json:"volume_a"`
+	VolumeB *big.Int  `json:"volume_b"`
+	Swaps   int       `json:"swaps"`
+}
+
+// Series builds the candles of a single interval, safe for concurrent use
+// The last candle is the one in progress. Intervals without swaps are filled
+// with flat candles at the previous close, so moving averages stay time-based
+type Series struct {
+	mu       sync.RWMutex
+	interval time.Duration
+	max      int
+	candles  []Candle
+}
+
+// NewSeries creates a series keeping up to max candles (0 = DefaultMaxCandles)
+func NewSeries(interval time.Duration, max int) *Series {
+	if max <= 0 {
+		max = DefaultMaxCandles
+	}
+	return &Series{interval: interval, max: max}
+}
+
+// Interval returns the candle duration
+func (s *Series) Interval() time.Duration {
+	return s.interval
+}
+
+// Add folds a swap event into the series
+// Events older than the current candle are counted in the current candle
+func (s *Series) Add(e domain.SwapEvent) {
+	price := e.PriceAInB
+	volA, volB := e.AmountIn, e.AmountOut
+	if e.Direction == domain.BToA {
+		volA, volB = e.AmountOut, e.AmountIn
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.update(e.Time, price)
+	c.VolumeA.Add(c.VolumeA, volA)
+	c.VolumeB.Add(c.VolumeB, volB)
+	c.Swaps++
+}
+
+// Observe records a price seen at t without volume, e.g. a backtest step
+func (s *Series) Observe(t time.Time, price float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.update(t, price)
+}
+
+// update applies a price to the candle of t and returns it
+// Called with s.mu held
+func (s *Series) update(t time.Time, price float64) *Candle {
+	start := t.Truncate(s.interval)
+	if n := len(s.candles); n == 0 || start.After(s.candles[n-1].Start) {
+		s.open(start, price)
+	}
+
+	c := &s.candles[len(s.candles)-1]
+	c.High = max(c.High, price)
+	c.Low = min(c.Low, price)
+	c.Close = price
+	return c
+}
+
+// open appends the candle starting at start, filling the gap since the last one
+func (s *Series) open(start time.Time, price float64) {
+	if n := len(s.candles); n > 0 {
+		last := s.candles[n-1]
+		// no point filling more flat candles than the series keeps
+		from := last.Start.Add(s.interval)
+		if gap := int(start.Sub(from) / s.interval); gap > s.max {
+			from = start.Add(-time.Duration(s.max) * s.interval)
+		}
+		for t := from; t.Before(start); t = t.Add(s.interval) {
+			s.append(newCandle(t, last.Close))
+		}
+		// the candle opens where the previous one closed
+		price = last.Close
+	}
+	s.append(newCandle(start, price))
+}
+
+func (s *Series) append(c Candle) {
+	if len(s.candles) == s.max {
+		copy(s.candles, s.candles[1:])
+		s.candles = s.candles[:len(s.candles)-1]
+	}
+	s.candles = append(s.candles, c)
+}
+
+func newCandle(start time.Time, price float64) Candle {
+	return Candle{
+		Start:   start,
+		Open:    price,
+		High:    price,
+		Low:     price,
+		Close:   price,
+		VolumeA: new(big.Int),
+		VolumeB: new(big.Int),
+	}
+}
+
+// Len returns the number of candles kept
+func (s *Series) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.candles)
+}
+
+// Candles returns a copy of every candle, oldest first
+func (s *Series) Candles() []Candle {
+	return s.Last(0)
+}
+
+// Last returns a copy of the last n candles, oldest first (0 = all)
+func (s *Series) Last(n int) []Candle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if n <= 0 || n > len(s.candles) {
+		n = len(s.candles)
+	}
+	result := make([]Candle, n)
+	for i, c := range s.candles[len(s.candles)-n:] {
+		c.VolumeA = new(big.Int).Set(c.VolumeA)
+		c.VolumeB = new(big.Int).Set(c.VolumeB)
+		result[i] = c
+	}
+	return result
+}
+
+// Closes returns the close prices of the last n candles, oldest first (0 = all)
+func (s *Series) Closes(n int) []float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if n <= 0 || n > len(s.candles) {
+		n = len(s.candles)
+	}
+	closes := make([]float64, n)
+	for i, c := range s.candles[len(s.candles)-n:] {
+		closes[i] = c.Close
+	}
+	return closes
+}
+
+// SMA returns the simple moving average of the last n closes
+// ok is false until the series has n candles
+func (s *Series) SMA(n int) (avg float64, ok bool) {
+	if n <= 0 {
+		return 0, false
+	}
+	closes := s.Closes(n)
+	if len(closes) < n {
+		return 0, false
+	}
+	var sum float64
+	for _, c := range closes {
+		sum += c
+	}
+	return sum / float64(n), true
+}
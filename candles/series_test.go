@@ -0,0 +1,184 @@
+package candles
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexus-bot-swarm/domain"
+)
+
+var t0 = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// event builds a swap event leaving the pool at price (reserveB / 1000)
+func event(at time.Duration, direction domain.Direction, in, out, reserveB int64) domain.SwapEvent {
+	return domain.SwapEvent{
+		Direction: direction,
+		AmountIn:  big.NewInt(in),
+		AmountOut: big.NewInt(out),
+		ReserveA:  big.NewInt(1000),
+		ReserveB:  big.NewInt(reserveB),
+		PriceAInB: float64(reserveB) / 1000,
+		Time:      t0.Add(at),
+	}
+}
+
+func TestSeries_OHLCV(t *testing.T) {
+	s := NewSeries(time.Minute, 0)
+	s.Add(event(0, domain.AToB, 10, 20, 2000))
+	s.Add(event(10*time.Second, domain.BToA, 30, 12, 3000))
+	s.Add(event(20*time.Second, domain.AToB, 5, 8, 1000))
+	s.Add(event(50*time.Second, domain.AToB, 1, 2, 1500))
+
+	candles := s.Candles()
+	if len(candles) != 1 {
+		t.Fatalf("expected 1 candle, got %d", len(candles))
+	}
+	c := candles[0]
+	if !c.Start.Equal(t0) || c.Open != 2 || c.High != 3 || c.Low != 1 || c.Close != 1.5 || c.Swaps != 4 {
+		t.Errorf("unexpected candle: %+v", c)
+	}
+	// A in: 10+5+1, A out: 12 / B out: 20+8+2, B in: 30
+	if c.VolumeA.Int64() != 28 || c.VolumeB.Int64() != 60 {
+		t.Errorf("unexpected volumes: %s A, %s B", c.VolumeA, c.VolumeB)
+	}
+}
+
+func TestSeries_FillsGaps(t *testing.T) {
+	s := NewSeries(time.Second, 0)
+	s.Add(event(0, domain.AToB, 1, 1, 2000))
+	s.Add(event(3500*time.Millisecond, domain.AToB, 1, 1, 4000))
+
+	candles := s.Candles()
+	if len(candles) != 4 {
+		t.Fatalf("expected 4 candles, got %d", len(candles))
+	}
+	for i, c := range candles[1:3] {
+		if c.Open != 2 || c.Close != 2 || c.Swaps != 0 || c.VolumeA.Sign() != 0 {
+			t.Errorf("gap candle %d should be flat at the previous close: %+v", i+1, c)
+		}
+	}
+	last := candles[3]
+	if !last.Start.Equal(t0.Add(3*time.Second)) || last.Open != 2 || last.Close != 4 || last.High != 4 {
+		t.Errorf("unexpected last candle: %+v", last)
+	}
+}
+
+func TestSeries_KeepsMax(t *testing.T) {
+	s := NewSeries(time.Second, 3)
+	for i := 0; i < 5; i++ {
+		s.Add(event(time.Duration(i)*time.Second, domain.AToB, 1, 1, int64(1000*(i+1))))
+	}
+	// a long pause only keeps the last max candles
+	s.Add(event(time.Hour, domain.AToB, 1, 1, 9000))
+
+	candles := s.Candles()
+	if len(candles) != 3 {
+		t.Fatalf("expected 3 candles, got %d", len(candles))
+	}
+	if candles[0].Close != 5 || candles[2].Close != 9 || !candles[2].Start.Equal(t0.Add(time.Hour)) {
+		t.Errorf("unexpected candles: %+v", candles)
+	}
+}
+
+func TestSeries_SMA(t *testing.T) {
+	s := NewSeries(time.Second, 0)
+	if _, ok := s.SMA(2); ok {
+		t.Error("SMA of an empty series should not be ok")
+	}
+
+	for i, reserveB := range []int64{1000, 2000, 3000, 6000} {
+		s.Add(event(time.Duration(i)*time.Second, domain.AToB, 1, 1, reserveB))
+	}
+
+	if avg, ok := s.SMA(3); !ok || avg != 11.0/3 {
+		t.Errorf("expected SMA(3) = %f, got %f (%v)", 11.0/3, avg, ok)
+	}
+	if _, ok := s.SMA(5); ok {
+		t.Error("SMA longer than the series should not be ok")
+	}
+	if closes := s.Closes(2); len(closes) != 2 || closes[0] != 3 || closes[1] != 6 {
+		t.Errorf("unexpected closes: %v", closes)
+	}
+}
+
+func TestSeries_Observe(t *testing.T) {
+	s := NewSeries(time.Minute, 0)
+	s.Observe(t0, 2)
+	s.Observe(t0.Add(30*time.Second), 2.5)
+	s.Observe(t0.Add(time.Minute), 1.8)
+
+	candles := s.Candles()
+	if len(candles) != 2 || candles[0].High != 2.5 || candles[0].Swaps != 0 || candles[1].Close != 1.8 {
+		t.Errorf("unexpected candles: %+v", candles)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	if _, err := NewRecorder(nil, 0); err == nil {
+		t.Error("expected error without intervals")
+	}
+	if _, err := NewRecorder([]time.Duration{time.Second, time.Second}, 0); err == nil {
+		t.Error("expected error for duplicate intervals")
+	}
+
+	r, err := NewRecorder([]time.Duration{time.Minute, time.Second}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Finest().Interval() != time.Second || r.Series(5*time.Minute) != nil {
+		t.Error("unexpected series lookup")
+	}
+
+	// feed it from a real pool
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	sub := pool.Subscribe(16)
+	done := make(chan struct{})
+	go func() {
+		r.Run(sub)
+		close(done)
+	}()
+
+	pool.Swap(1, domain.AToB, big.NewInt(1000))
+	pool.Swap(2, domain.BToA, big.NewInt(500))
+	sub.Close()
+	<-done
+
+	var swaps int
+	for _, c := range r.Series(time.Minute).Candles() {
+		swaps += c.Swaps
+	}
+	if swaps != 2 {
+		t.Errorf("expected 2 swaps recorded, got %d", swaps)
+	}
+	last := r.Series(time.Minute).Last(1)[0]
+	if last.Close != pool.PriceAInB() {
+		t.Errorf("last close %f should match the pool price %f", last.Close, pool.PriceAInB())
+	}
+}
+
+func TestSeries_WriteCSV(t *testing.T) {
+	s := NewSeries(time.Second, 0)
+	s.Add(event(0, domain.AToB, 10, 20, 2000))
+	s.Add(event(time.Second, domain.BToA, 30, 12, 2500))
+
+	var buf bytes.Buffer
+	if err := s.WriteCSV(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "start,open,high,low,close,volume_a,volume_b,swaps\n" +
+		"2025-01-01T12:00:00Z,2,2,2,2,10,20,1\n" +
+		"2025-01-01T12:00:01Z,2,2.5,2,2.5,12,30,1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected csv:\n%s", got)
+	}
+
+	dir := t.TempDir()
+	r, _ := NewRecorder([]time.Duration{time.Second, time.Minute}, 0)
+	paths, err := r.WriteFiles(dir, t0)
+	if err != nil || len(paths) != 2 || !strings.HasSuffix(paths[1], "candles-1m-20250101-120000.csv") {
+		t.Errorf("unexpected files: %v (%v)", paths, err)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nexus-bot-swarm/backtest"
+	"github.com/nexus-bot-swarm/candles"
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// runBacktest replays a price/swap history against the bot strategies
+// Usage: bot backtest -file history.csv -strategies random,mean-reversion
+func runBacktest(args []string) error {
+	fs := flag.NewFlagSet("backtest", flag.ExitOnError)
+	file := fs.String("file", "", "history file (.csv or .jsonl)")
+	strategyList := fs.String("strategies", "random,mean-reversion", "comma separated strategies, one bot each")
+	seed := fs.Int64("seed", 1, "random seed for reproducible runs")
+	reserveA := fs.Int64("reserve-a", 1000000000, "initial pool reserve of token A")
+	balanceA := fs.Int64("balance-a", 1000000, "initial bot balance of token A")
+	balanceB := fs.Int64("balance-b", 2000000, "initial bot balance of token B")
+	candle := fs.Duration("candle", time.Minute, "candle interval of the price history read by momentum/mean-reversion")
+	output := fs.String("output", "text", "report format: text or json")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		return errors.New("invalid arguments: -file is required")
+	}
+	if *candle <= 0 {
+		return errors.New("invalid arguments: -candle must be positive")
+	}
+
+	steps, err := backtest.LoadFile(*file)
+	if err != nil {
+		return fmt.Errorf("failed to load history: %w", err)
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("failed to load history: %s is empty", *file)
+	}
+
+	// start the pool at the first observed price (defaults to 2 B per A)
+	startPrice := 2.0
+	if steps[0].Price > 0 {
+		startPrice = steps[0].Price
+	}
+	resA := big.NewInt(*reserveA)
+	resB, _ := new(big.Float).Mul(new(big.Float).SetInt(resA), big.NewFloat(startPrice)).Int(nil)
+	pool, err := domain.NewPool("ETH", "USDC", resA, resB)
+	if err != nil {
+		return fmt.Errorf("invalid pool: %w", err)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	var strategies []swarm.Strategy
+	for _, name := range strings.Split(*strategyList, ",") {
+		s, err := swarm.NewStrategy(strings.TrimSpace(name), rng)
+		if err != nil {
+			return fmt.Errorf("invalid strategy: %w", err)
+		}
+		strategies = append(strategies, s)
+	}
+
+	engine := backtest.NewEngine(pool, strategies, big.NewInt(*balanceA), big.NewInt(*balanceB))
+	engine.SetPriceHistory(candles.NewSeries(*candle, 0))
+	report, err := engine.Run(steps)
+	if err != nil {
+		return fmt.Errorf("backtest failed: %w", err)
+	}
+
+	switch *output {
+	case "json":
+		err = report.WriteJSON(os.Stdout)
+	case "text":
+		err = report.WriteText(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown output format: %s", *output)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
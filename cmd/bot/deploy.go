@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/joho/godotenv"
+	"github.com/nexus-bot-swarm/internal/config"
+	"github.com/nexus-bot-swarm/internal/deploy"
+	"github.com/nexus-bot-swarm/internal/logging"
+)
+
+// runDeploy deploys a compiled contract from contracts/ with the configured key
+// Usage: bot deploy -contract ConstantProductPair -args 0xTokenA,0xTokenB
+func runDeploy(args []string) error {
+	fs := flag.NewFlagSet("deploy", flag.ExitOnError)
+	contract := fs.String("contract", "KevzToken", "contract name: <dir>/<name>.abi and <dir>/<name>.bin")
+	dir := fs.String("dir", "contracts", "directory of the compiled contracts")
+	ctorArgs := fs.String("args", "", "comma-separated constructor arguments")
+	envFile := fs.String("env", ".env", "env file updated with the deployed address")
+	envKey := fs.String("env-key", "", "env var set to the deployed address (default TOKEN_ADDRESS for KevzToken, none otherwise)")
+	timeout := fs.Duration("timeout", 2*time.Minute, "max wait for the deployment receipt")
+	_ = fs.Parse(args)
+
+	_ = godotenv.Load(*envFile)
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.PrivateKey == "" {
+		return errors.New("invalid config: deploying sends a tx: set NEXUS_PRIVATE_KEY")
+	}
+	logger, err := logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("invalid logging config: %w", err)
+	}
+	slog.SetDefault(logger)
+
+	key := *envKey
+	if key == "" && *contract == "KevzToken" {
+		key = "TOKEN_ADDRESS"
+	}
+
+	// fail on a missing .bin or bad arguments before touching the network
+	artifact, err := deploy.LoadArtifact(*dir, *contract)
+	if err != nil {
+		return fmt.Errorf("failed to load contract: %w", err)
+	}
+	var values []string
+	if *ctorArgs != "" {
+		values = strings.Split(*ctorArgs, ",")
+	}
+	params, err := artifact.ParseArgs(values)
+	if err != nil {
+		return fmt.Errorf("invalid constructor arguments: %w", err)
+	}
+	privateKey, err := crypto.HexToECDSA(cfg.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("invalid NEXUS_PRIVATE_KEY: %w", err)
+	}
+
+	client := newNexusClient(cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to nexus: %w", err)
+	}
+	defer client.Close()
+
+	slog.Info("deploying contract", "contract", *contract, "chain_id", client.ChainID().Int64(),
+		"from", crypto.PubkeyToAddress(privateKey.PublicKey).Hex(), "bytecode_bytes", len(artifact.Bytecode))
+	result, err := deploy.Deploy(ctx, client.Backend(), privateKey, client.ChainID(), artifact, params...)
+	if err != nil {
+		return fmt.Errorf("deployment failed: %w", err)
+	}
+	slog.Info("contract deployed", "contract", *contract, "address", result.Address.Hex(),
+		"tx_hash", result.TxHash.Hex(), "block", result.Block, "gas_used", result.GasUsed)
+
+	if key == "" {
+		return nil
+	}
+	if err := deploy.SetEnv(*envFile, key, result.Address.Hex()); err != nil {
+		return fmt.Errorf("failed to update env file: %w", err)
+	}
+	slog.Info("env file updated", "file", *envFile, "key", key)
+	return nil
+}
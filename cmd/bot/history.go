@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/nexus-bot-swarm/internal/adapters/bolt"
+	"github.com/nexus-bot-swarm/ports"
+)
+
+// runHistory prints the transactions recorded in the tx store
+// Usage: bot history -status pending -bot 2 -limit 50
+func runHistory(args []string) error {
+	_ = godotenv.Load()
+
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	defaultDB := os.Getenv("TX_DB_PATH")
+	if defaultDB == "" {
+		defaultDB = "data/txs.db"
+	}
+	dbPath := fs.String("db", defaultDB, "tx store path")
+	status := fs.String("status", "", "only txs with this status (pending, confirmed, reverted, failed, dropped)")
+	botID := fs.Int("bot", 0, "only txs of this bot")
+	limit := fs.Int("limit", 100, "most recent N txs (0 = all)")
+	output := fs.String("output", "text", "output format: text or json")
+	_ = fs.Parse(args)
+
+	store, err := bolt.Open(*dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open tx store: %w", err)
+	}
+	defer store.Close()
+
+	txs, err := store.Txs(ports.TxFilter{BotID: *botID, Status: ports.TxStatus(*status), Limit: *limit})
+	if err != nil {
+		return fmt.Errorf("failed to read tx store: %w", err)
+	}
+
+	switch *output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(txs)
+	case "text":
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSENT\tBOT\tNONCE\tSTATUS\tBLOCK\tHASH\tERROR")
+		for _, tx := range txs {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\t%d\t%s\t%s\n",
+				tx.ID, tx.SentAt.Format("2006-01-02 15:04:05"), tx.BotID, tx.Nonce, tx.Status, tx.BlockNumber, tx.Hash, tx.Error)
+		}
+		err = tw.Flush()
+	default:
+		err = fmt.Errorf("unknown output format: %s", *output)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/nexus-bot-swarm/internal/config"
+	"github.com/nexus-bot-swarm/internal/logging"
+	"github.com/nexus-bot-swarm/loadtest"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// runLoadTest drives real transactions against Nexus following a load profile
+// Usage: bot loadtest -profile ramp:from=0.1,to=2,duration=10m -max-bots 50
+func runLoadTest(args []string) error {
+	fs := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	profileSpec := fs.String("profile", "", "load profile, e.g. ramp:from=0.1,to=2,duration=10m (ramp, step, spike, soak)")
+	maxBots := fs.Int("max-bots", 50, "maximum number of bots")
+	tick := fs.Duration("tick", loadtest.DefaultTick, "how often the target rate is re-evaluated and sampled")
+	drain := fs.Duration("drain", loadtest.DefaultDrain, "how long to wait for pending receipts at the end")
+	output := fs.String("output", "text", "report format: text or json")
+	_ = fs.Parse(args)
+
+	if *profileSpec == "" {
+		return errors.New("invalid arguments: -profile is required")
+	}
+	profile, err := loadtest.ParseProfile(*profileSpec)
+	if err != nil {
+		return fmt.Errorf("invalid profile: %w", err)
+	}
+
+	_ = godotenv.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.PrivateKey == "" || cfg.WalletAddress == "" {
+		return errors.New("invalid config: load tests send real txs: set NEXUS_PRIVATE_KEY and WALLET_ADDRESS")
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("invalid logging config: %w", err)
+	}
+	slog.SetDefault(logger)
+
+	client := newNexusClient(cfg)
+	connectCtx, connectCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer connectCancel()
+
+	if err := client.Connect(connectCtx); err != nil {
+		return fmt.Errorf("failed to connect to nexus: %w", err)
+	}
+	defer client.Close()
+
+	startNonce, err := client.GetNonce(connectCtx, cfg.WalletAddress)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	// token transfers go through the registry, as in the swarm
+	registry, err := resolveTokens(connectCtx, cfg, client)
+	if err != nil {
+		return err
+	}
+	transfers, err := transferAmounts(cfg, registry)
+	if err != nil {
+		return fmt.Errorf("invalid token transfers: %w", err)
+	}
+
+	// bots still trade on the first simulated pool, the runner only cares
+	// about real txs
+	pool, err := newSimulatedPool(cfg.SimulatedPools()[0], registry)
+	if err != nil {
+		return fmt.Errorf("invalid pool: %w", err)
+	}
+	botSwarm := swarm.NewSwarmWithClient(0, pool, client, cfg.PrivateKey, cfg.WalletAddress, cfg.TokenAddress, startNonce)
+	if len(transfers) > 0 {
+		if err := botSwarm.SetTransferAmounts(transfers...); err != nil {
+			return fmt.Errorf("invalid token transfers: %w", err)
+		}
+	}
+	if err := fundBots(botSwarm, cfg); err != nil {
+		return err
+	}
+
+	recorder := loadtest.NewRecorder()
+	botSwarm.SetLogger(logger, cfg.LogSwapEvery)
+	botSwarm.SetObserver(recorder)
+
+	// Ctrl+C aborts the run without a report
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errCh, err := botSwarm.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start swarm: %w", err)
+	}
+	go func() {
+		for err := range errCh {
+			if errors.Is(err, context.Canceled) {
+				continue
+			}
+			slog.Warn("bot error", "error", err, "error_class", swarm.ErrorClass(err))
+		}
+	}()
+
+	slog.Info("starting load test",
+		"profile", *profileSpec, "duration", profile.Duration(), "max_bots", *maxBots, "start_nonce", startNonce)
+
+	runner := loadtest.NewRunner(botSwarm, profile, recorder, *maxBots)
+	runner.SetTiming(*tick, *drain)
+	runner.SetLogger(logger)
+
+	report, err := runner.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("load test failed: %w", err)
+	}
+
+	// the runner already waited for receipts, only the bot loops are left
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer stopCancel()
+	if _, err := botSwarm.Stop(stopCtx, false); err != nil {
+		slog.Warn("swarm did not stop cleanly", "error", err)
+	}
+
+	switch *output {
+	case "json":
+		err = report.WriteJSON(os.Stdout)
+	case "text":
+		err = report.WriteText(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown output format: %s", *output)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -2,133 +2,750 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"math/big"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/nexus-bot-swarm/candles"
 	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/internal/adapters/bolt"
 	"github.com/nexus-bot-swarm/internal/adapters/nexus"
+	"github.com/nexus-bot-swarm/internal/adapters/uniswap"
+	"github.com/nexus-bot-swarm/internal/api"
 	"github.com/nexus-bot-swarm/internal/config"
+	"github.com/nexus-bot-swarm/internal/logging"
+	"github.com/nexus-bot-swarm/internal/metrics"
+	"github.com/nexus-bot-swarm/internal/nonce"
+	"github.com/nexus-bot-swarm/internal/report"
+	"github.com/nexus-bot-swarm/internal/tokens"
+	"github.com/nexus-bot-swarm/ports"
 	"github.com/nexus-bot-swarm/swarm"
 )
 
 func main() {
-	log.Println("🚀 Starting Nexus Bot Swarm...")
+	if err := run(os.Args[1:]); err != nil {
+		slog.Error("bot failed", "error", err, "error_class", swarm.ErrorClass(err))
+		os.Exit(1)
+	}
+}
 
-	// Load .env file (ignore error if not exists)
-	_ = godotenv.Load()
+// run dispatches to a subcommand or runs the swarm
+// Errors are returned rather than exiting, so deferred closes run first
+func run(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "backtest":
+			return runBacktest(args[1:])
+		case "loadtest":
+			return runLoadTest(args[1:])
+		case "history":
+			return runHistory(args[1:])
+		case "deploy":
+			return runDeploy(args[1:])
+		}
+	}
+	return runSwarm()
+}
 
-	// Load configuration
-	cfg, err := config.Load()
+// runSwarm runs the bot swarm until Ctrl+C
+func runSwarm() error {
+	cfg, logger, err := loadConfig()
 	if err != nil {
-		log.Fatalf("❌ Failed to load config: %v", err)
+		return err
 	}
-	log.Printf("📋 Config loaded: RPC=%s, ChainID=%d, Bots=%d", cfg.RPCURL, cfg.ExpectedChainID, cfg.BotCount)
+	slog.Info("starting nexus bot swarm",
+		"rpc_urls", cfg.RPCURLs, "chain_id", cfg.ExpectedChainID, "bots", cfg.BotCount, "config_file", cfg.File)
+
+	// Create Nexus client (instrumented when metrics are enabled)
+	nexusClient := newNexusClient(cfg)
+	var client ports.BlockchainClient = nexusClient
 
-	// Create Nexus client
-	client := nexus.NewClient(cfg.RPCURL, cfg.ExpectedChainID)
+	var m *metrics.Metrics
+	if cfg.MetricsAddr != "" {
+		m = metrics.New()
+		client = metrics.InstrumentClient(client, m)
+	}
 
 	// Connect with timeout
 	connectCtx, connectCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer connectCancel()
 
-	if err := client.Connect(connectCtx); err != nil {
-		log.Fatalf("❌ Failed to connect to Nexus: %v", err)
+	if err := connect(connectCtx, client, nexusClient.URL()); err != nil {
+		return err
 	}
 	defer client.Close()
 
-	log.Printf("✅ Connected to Nexus Testnet (Chain ID: %d)", client.ChainID().Int64())
+	// token reads and on-chain pool calls bypass the BlockchainClient port,
+	// they are instrumented separately
+	var tokenReader tokens.Reader = nexusClient
+	var backend uniswap.Backend = nexusClient.Backend()
+	if m != nil {
+		tokenReader = metrics.InstrumentTokenReader(tokenReader, m)
+		backend = metrics.InstrumentBackend(backend, m)
+	}
 
-	// Show current block to prove connection works
-	blockNum, err := client.BlockNumber(connectCtx)
+	// Token registry: the config and tokens file, checked and completed on
+	// chain, and TOKEN_ADDRESS. Bots transfer tokens by symbol through it
+	registry, err := resolveTokens(connectCtx, cfg, tokenReader)
 	if err != nil {
-		log.Fatalf("❌ Failed to get block number: %v", err)
+		return err
 	}
-	log.Printf("📦 Current block: %d", blockNum)
 
-	// Create simulated AMM pool
-	// Initial reserves: 1000 ETH, 2000 USDC (in wei-like units)
-	initialReserveA := big.NewInt(1000000000) // 1 billion units
-	initialReserveB := big.NewInt(2000000000) // 2 billion units
-	pool := domain.NewPool("ETH", "USDC", initialReserveA, initialReserveB)
-	log.Printf("💱 AMM Pool created: %s/%s (ReserveA=%s, ReserveB=%s)",
-		pool.TokenA, pool.TokenB, pool.ReserveA.String(), pool.ReserveB.String())
-	log.Printf("💰 Initial price: 1 %s = %.4f %s", pool.TokenA, pool.PriceAInB(), pool.TokenB)
+	pools, onchainPool, err := buildPools(connectCtx, cfg, backend, registry, logger)
+	if err != nil {
+		return err
+	}
+	if m != nil {
+		for _, pool := range pools {
+			m.RegisterPool(pool)
+		}
+		defer serveMetrics(cfg.MetricsAddr, m).Close()
+	}
 
 	// Create and start swarm
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if onchainPool != nil {
+		// snapshots follow the swaps of other traders on the pair
+		go onchainPool.Run(ctx)
+	}
+
+	// tx history: recover txs left pending by a previous run
+	var store ports.TxStore
+	if cfg.PrivateKey != "" && cfg.WalletAddress != "" && cfg.TxDBPath != "" {
+		db, err := bolt.Open(cfg.TxDBPath)
+		if err != nil {
+			return fmt.Errorf("failed to open tx store: %w", err)
+		}
+		defer db.Close()
+		store = db
+		slog.Info("tx store opened", "path", cfg.TxDBPath)
+	}
+
+	botSwarm, inFlight, err := buildSwarm(connectCtx, cfg, client, store, registry, pools)
+	if err != nil {
+		return err
+	}
+	if onchainPool != nil && botSwarm.NonceManager() != nil {
+		// swaps and bot transfers come from the same wallet
+		onchainPool.SetNonceManager(botSwarm.NonceManager())
+	}
+	botSwarm.SetLogger(logger, cfg.LogSwapEvery)
+	if err := fundBots(botSwarm, cfg); err != nil {
+		return err
+	}
+
+	runs, observers := buildObservers(cfg, botSwarm, m, store, logger)
+	botSwarm.SetObserver(observers)
+	if onchainPool != nil {
+		// swaps are txs of the bots too: metrics, tx store and report
+		onchainPool.SetObserver(observers)
+	}
+
+	if err := startCandles(cfg, botSwarm, runs); err != nil {
+		return err
+	}
+	startedAt := time.Now()
+
+	errCh, err := botSwarm.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start swarm: %w", err)
+	}
+
+	// keep tracking receipts of txs sent before the restart
+	if len(inFlight) > 0 {
+		txs := make([]swarm.TxInfo, len(inFlight))
+		for i, tx := range inFlight {
+			txs[i] = swarm.StoredTxInfo(tx)
+		}
+		botSwarm.TrackReceipts(txs)
+	}
+
+	if cfg.AdminAddr != "" {
+		defer serveAdmin(cfg.AdminAddr, botSwarm).Close()
+	}
+
+	final := shutdown(cfg, botSwarm, errCh)
+	cancel()
+
+	logFinalState(final)
+	for _, run := range runs {
+		run.writeFiles(final.Bots, startedAt)
+	}
+
+	slog.Info("goodbye")
+	return nil
+}
+
+// loadConfig loads the .env file and the config, and installs the
+// structured logger of the config as the default one
+func loadConfig() (*config.Config, *slog.Logger, error) {
+	// Load .env file (ignore error if not exists)
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid logging config: %w", err)
+	}
+	slog.SetDefault(logger)
+	return cfg, logger, nil
+}
+
+// connect connects the client and shows the current block to prove the
+// connection works. The client is closed again on failure
+func connect(ctx context.Context, client ports.BlockchainClient, url string) error {
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to nexus: %w", err)
+	}
+	slog.Info("connected to nexus", "rpc_url", url, "chain_id", client.ChainID().Int64())
 
+	blockNum, err := client.BlockNumber(ctx)
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+	slog.Info("current block", "block", blockNum)
+	return nil
+}
+
+// buildPools opens the AMM pools of the config: a deployed UniswapV2 pair,
+// returned as well to run it, or simulated ones
+// The swarm only depends on domain.AMM
+func buildPools(ctx context.Context, cfg *config.Config, backend uniswap.Backend, registry *domain.TokenRegistry, logger *slog.Logger) ([]domain.AMM, *uniswap.Pool, error) {
+	var pools []domain.AMM
+	var onchainPool *uniswap.Pool
+	if cfg.PoolPairAddress != "" {
+		var err error
+		onchainPool, err = uniswap.NewPool(ctx, backend,
+			cfg.PoolRouterAddress, cfg.PoolPairAddress, cfg.PoolTokenA, cfg.PrivateKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open on-chain pool: %w", err)
+		}
+		onchainPool.SetSlippage(cfg.PoolSlippageBps)
+		onchainPool.SetLogger(logger)
+		// pool tokens listed in the tokens file keep their registered symbol
+		tokenA, tokenB := onchainPool.TokenInfo()
+		for _, token := range []domain.Token{tokenA, tokenB} {
+			if _, err := registry.ByAddress(token.Address); err == nil {
+				continue
+			}
+			if err := registry.Register(token); err != nil {
+				return nil, nil, fmt.Errorf("pool token conflicts with the token registry: %w", err)
+			}
+		}
+		pools = append(pools, onchainPool)
+		slog.Info("trading on-chain pool", "router", cfg.PoolRouterAddress, "pair", onchainPool.Address())
+	} else {
+		for _, poolCfg := range cfg.SimulatedPools() {
+			simulatedPool, err := newSimulatedPool(poolCfg, registry)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid pool %s/%s: %w", poolCfg.SymbolA, poolCfg.SymbolB, err)
+			}
+			pools = append(pools, simulatedPool)
+		}
+	}
+
+	for _, token := range registry.Tokens() {
+		slog.Info("token registered", "symbol", token.Symbol, "name", token.Name,
+			"decimals", token.Decimals, "address", token.Address)
+	}
+	for _, pool := range pools {
+		initial := pool.Snapshot()
+		initialA, initialB := initial.ReserveAmounts()
+		slog.Info("amm pool created",
+			"pair", initial.TokenA+"/"+initial.TokenB,
+			"reserve_a", initialA.String(),
+			"reserve_b", initialB.String(),
+			"price_a_in_b", initial.UnitPriceAInB())
+	}
+	return pools, onchainPool, nil
+}
+
+// buildSwarm creates the swarm trading the pools: sending real txs when the
+// config has a wallet, simulation only otherwise. In real tx mode it also
+// returns the txs a previous run left pending in the store
+func buildSwarm(ctx context.Context, cfg *config.Config, client ports.BlockchainClient, store ports.TxStore,
+	registry *domain.TokenRegistry, pools []domain.AMM) (*swarm.Swarm, []ports.StoredTx, error) {
 	var botSwarm *swarm.Swarm
+	var inFlight []ports.StoredTx
 	if cfg.PrivateKey != "" && cfg.WalletAddress != "" {
-		// get current nonce from RPC
-		startNonce, err := client.GetNonce(connectCtx, cfg.WalletAddress)
+		// startNonce gets the current nonce of a wallet from RPC, past the
+		// txs a previous run left pending in the tx store
+		startNonce := func(wallet string) (uint64, error) {
+			next, err := client.GetNonce(ctx, wallet)
+			if err != nil {
+				return 0, fmt.Errorf("failed to get nonce: %w", err)
+			}
+			if store == nil {
+				return next, nil
+			}
+			rec, err := nonce.Recover(store, wallet, next)
+			if err != nil {
+				return 0, fmt.Errorf("failed to recover nonce state: %w", err)
+			}
+			inFlight = append(inFlight, rec.InFlight...)
+			slog.Info("nonce state recovered", "wallet", wallet,
+				"in_flight", len(rec.InFlight), "dropped", len(rec.Dropped))
+			return rec.Start, nil
+		}
+		swarmNonce, err := startNonce(cfg.WalletAddress)
 		if err != nil {
-			log.Fatalf("❌ Failed to get nonce: %v", err)
+			return nil, nil, err
 		}
-		log.Printf("🔢 Starting nonce: %d", startNonce)
+		slog.Info("starting nonce", "nonce", swarmNonce)
 
 		// real TX mode with nonce manager
-		botSwarm = swarm.NewSwarmWithClient(cfg.BotCount, pool, client, cfg.PrivateKey, cfg.WalletAddress, cfg.TokenAddress, startNonce)
-		log.Printf("🤖 Swarm started with %d bots (REAL TX MODE). Press Ctrl+C to stop...", cfg.BotCount)
-
-		if cfg.TokenAddress != "" {
-			log.Printf("🪙 Token Mode: Bots will transfer KEVZ tokens")
-			log.Printf("📝 Token contract: %s", cfg.TokenAddress)
+		botSwarm = swarm.NewSwarmWithClient(cfg.BotCount, pools[0], client, cfg.PrivateKey, cfg.WalletAddress, cfg.TokenAddress, swarmNonce)
+		if err := configureSwarm(botSwarm, cfg); err != nil {
+			return nil, nil, fmt.Errorf("invalid bot settings: %w", err)
+		}
 
-			// Show initial token balance
-			tokenBalance, err := client.TokenBalance(connectCtx, cfg.TokenAddress, cfg.WalletAddress)
-			if err != nil {
-				log.Printf("⚠️ Could not get token balance: %v", err)
-			} else {
-				// Convert from wei to tokens (18 decimals)
-				tokenBalanceFloat := new(big.Float).SetInt(tokenBalance)
-				divisor := new(big.Float).SetInt(big.NewInt(1000000000000000000))
-				tokenBalanceFloat.Quo(tokenBalanceFloat, divisor)
-				log.Printf("💰 Token balance: %s KEVZ", tokenBalanceFloat.Text('f', 2))
+		// bots sending from their own wallet, each wallet is recovered once
+		recovered := map[string]bool{strings.ToLower(cfg.WalletAddress): true}
+		for _, bot := range cfg.Bots {
+			if bot.WalletAddress == "" {
+				continue
 			}
-		} else {
-			log.Printf("💸 NEX Mode: Bots will send 1 wei to self")
+			var next uint64
+			if !recovered[strings.ToLower(bot.WalletAddress)] {
+				recovered[strings.ToLower(bot.WalletAddress)] = true
+				if next, err = startNonce(bot.WalletAddress); err != nil {
+					return nil, nil, err
+				}
+			}
+			if err := botSwarm.SetBotWallet(bot.ID, bot.PrivateKey, bot.WalletAddress, next); err != nil {
+				return nil, nil, fmt.Errorf("invalid bot wallet: %w", err)
+			}
+			slog.Info("bot wallet", "bot_id", bot.ID, "wallet", bot.WalletAddress)
+		}
+		slog.Info("swarm mode", "mode", "real_tx", "tx_interval", botSwarm.TxInterval())
+
+		if err := setTransfers(ctx, cfg, botSwarm, client, registry); err != nil {
+			return nil, nil, err
 		}
-		log.Printf("⏰ Transactions every 10 seconds")
 	} else {
 		// simulation only
-		botSwarm = swarm.NewSwarm(cfg.BotCount, pool)
-		log.Printf("🤖 Swarm started with %d bots (SIMULATION MODE). Press Ctrl+C to stop...", cfg.BotCount)
-		log.Println("ℹ️  Set NEXUS_PRIVATE_KEY and WALLET_ADDRESS in .env for real TX")
+		botSwarm = swarm.NewSwarm(cfg.BotCount, pools[0])
+		if err := configureSwarm(botSwarm, cfg); err != nil {
+			return nil, nil, fmt.Errorf("invalid bot settings: %w", err)
+		}
+		slog.Info("swarm mode", "mode", "simulation",
+			"hint", "set NEXUS_PRIVATE_KEY and WALLET_ADDRESS in .env for real TX")
+	}
+
+	if len(pools) > 1 {
+		// bots take the pools in turn by ID
+		if err := botSwarm.SetPools(pools...); err != nil {
+			return nil, nil, fmt.Errorf("invalid pools: %w", err)
+		}
+	}
+	return botSwarm, inFlight, nil
+}
+
+// setTransfers sets the token transfers of the bots, and shows the wallet
+// balance of each token. Without any, bots send 1 wei NEX to self
+func setTransfers(ctx context.Context, cfg *config.Config, s *swarm.Swarm, client ports.BlockchainClient, registry *domain.TokenRegistry) error {
+	transfers, err := transferAmounts(cfg, registry)
+	if err != nil {
+		return fmt.Errorf("invalid token transfers: %w", err)
+	}
+	if len(transfers) == 0 {
+		slog.Info("bots will send 1 wei NEX to self")
+		return nil
+	}
+	if err := s.SetTransferAmounts(transfers...); err != nil {
+		return fmt.Errorf("invalid token transfers: %w", err)
+	}
+	for _, transfer := range transfers {
+		token := transfer.Token
+		slog.Info("bots will transfer tokens", "token", token.Address, "symbol", token.Symbol,
+			"decimals", token.Decimals, "amount", transfer.String())
+
+		// Show initial token balance
+		tokenBalance, err := client.TokenBalance(ctx, token.Address, cfg.WalletAddress)
+		if err != nil {
+			slog.Warn("could not get token balance", "token", token.Address, "error", err, "error_class", swarm.ErrorClass(err))
+		} else {
+			slog.Info("token balance", "token", token.Symbol, "amount", token.Amount(tokenBalance).Text(2))
+		}
+	}
+	return nil
+}
+
+// buildObservers returns the observers of the swarm events: metrics, tx
+// history and a run report per pool. The candles and report of each pool
+// go to a directory per pair when there are several
+func buildObservers(cfg *config.Config, s *swarm.Swarm, m *metrics.Metrics, store ports.TxStore, logger *slog.Logger) ([]*poolRun, swarm.MultiObserver) {
+	pools := s.Pools()
+	runs := make([]*poolRun, len(pools))
+	for i, pool := range pools {
+		runs[i] = &poolRun{pool: pool, dir: cfg.ReportDir}
+		if len(pools) > 1 && cfg.ReportDir != "" {
+			tokenA, tokenB := pool.Tokens()
+			runs[i].dir = filepath.Join(cfg.ReportDir, tokenA+"-"+tokenB)
+		}
+	}
+
+	observers := swarm.MultiObserver{}
+	if m != nil {
+		observers = append(observers, m)
 	}
+	if cfg.ReportDir != "" {
+		for _, run := range runs {
+			run.collector = report.NewCollector(run.pool)
+			observers = append(observers, s.PoolObserver(run.pool, run.collector))
+		}
+	}
+	if store != nil {
+		observers = append(observers, swarm.NewHistoryRecorder(store, logger))
+	}
+	return runs, observers
+}
 
-	errCh := botSwarm.Start(ctx)
+// startCandles builds the OHLCV candles of each pool from its swaps, read
+// by the momentum/mean-reversion strategies of the bots trading it
+func startCandles(cfg *config.Config, s *swarm.Swarm, runs []*poolRun) error {
+	if len(cfg.CandleIntervals) == 0 {
+		return nil
+	}
+	for _, run := range runs {
+		if err := run.recordCandles(cfg.CandleIntervals); err != nil {
+			return err
+		}
+		if err := s.SetPoolPriceHistory(run.pool, run.history.Finest()); err != nil {
+			return fmt.Errorf("failed to set price history: %w", err)
+		}
+	}
+	return nil
+}
 
-	// Wait for shutdown signal
+// shutdown waits for a shutdown signal, logging bot errors meanwhile, then
+// stops the bots, finishes in-flight sends and waits for their receipts
+// A second signal abandons the wait
+func shutdown(cfg *config.Config, s *swarm.Swarm, errCh <-chan error) *swarm.Snapshot {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
-	// Main loop: wait for signal or errors
 	go func() {
 		for err := range errCh {
 			if err != nil && err != context.Canceled {
-				log.Printf("⚠️ Bot error: %v", err)
+				slog.Warn("bot error", "error", err, "error_class", swarm.ErrorClass(err))
 			}
 		}
 	}()
 
+	slog.Info("swarm running, press Ctrl+C to stop")
 	<-sigCh
-	log.Println("\n🛑 Shutdown signal received...")
+	slog.Info("shutdown signal received")
 
-	// Cancel context to stop all bots
-	cancel()
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer stopCancel()
+	go func() {
+		<-sigCh
+		slog.Warn("second signal received, abandoning pending receipts")
+		stopCancel()
+	}()
+
+	final, err := s.Stop(stopCtx, cfg.ShutdownWaitReceipts)
+	if err != nil {
+		slog.Warn("shutdown incomplete", "pending_txs", final.PendingTxs, "error", err)
+	}
+	return final
+}
+
+// logFinalState shows the final state of each pool, and the bots trading it
+// with their P&L marked at its final price
+func logFinalState(final *swarm.Snapshot) {
+	slog.Info("final swarm state", "pending_txs", final.PendingTxs)
+	for _, snap := range final.Pools {
+		pair := snap.TokenA + "/" + snap.TokenB
+		finalA, finalB := snap.ReserveAmounts()
+		slog.Info("final pool state",
+			"pair", pair,
+			"reserve_a", finalA.String(),
+			"reserve_b", finalB.String(),
+			"price_a_in_b", snap.UnitPriceAInB())
+
+		tokenA, tokenB := snap.TokenInfo()
+		for _, bot := range final.Bots {
+			if bot.Pool != pair {
+				continue
+			}
+			slog.Info("bot result",
+				"bot_id", bot.ID,
+				"pair", pair,
+				"balance_a", tokenA.Amount(bot.Portfolio.BalanceA).String(),
+				"balance_b", tokenB.Amount(bot.Portfolio.BalanceB).String(),
+				"trades", bot.Portfolio.Trades,
+				"rejected", bot.Portfolio.Rejected,
+				"realized_pnl", bot.Portfolio.Realized,
+				"pnl", bot.Portfolio.PnL)
+		}
+	}
+}
+
+// newNexusClient creates a client for the configured RPC endpoints: the
+// first one, then the others as fallbacks
+func newNexusClient(cfg *config.Config) *nexus.Client {
+	client := nexus.NewClient(cfg.RPCURLs[0], cfg.ExpectedChainID)
+	client.SetFallbackURLs(cfg.RPCURLs[1:]...)
+	return client
+}
+
+// resolveTokens builds the token registry from the config tokens, the tokens
+// file and TOKEN_ADDRESS, checked and completed on chain
+func resolveTokens(ctx context.Context, cfg *config.Config, reader tokens.Reader) (*domain.TokenRegistry, error) {
+	var entries []tokens.Entry
+	for _, token := range cfg.Tokens {
+		entries = append(entries, tokens.Entry(token))
+	}
+	if cfg.TokensFile != "" {
+		fileEntries, err := tokens.LoadFile(cfg.TokensFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tokens: %w", err)
+		}
+		entries = append(entries, fileEntries...)
+	}
+	registry, err := tokens.Resolve(ctx, reader, entries, cfg.TokenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tokens: %w", err)
+	}
+	return registry, nil
+}
+
+// newSimulatedPool creates a simulated pool of the config. Its tokens take
+// their decimals from the registry, and are raw units when not listed there
+func newSimulatedPool(poolCfg config.PoolConfig, registry *domain.TokenRegistry) (*domain.Pool, error) {
+	tokenA, tokenB := poolToken(registry, poolCfg.SymbolA), poolToken(registry, poolCfg.SymbolB)
+	reserveA, err := configAmount(poolCfg.ReserveA, tokenA)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reserve_a: %w", err)
+	}
+	reserveB, err := configAmount(poolCfg.ReserveB, tokenB)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reserve_b: %w", err)
+	}
+
+	pool, err := domain.NewPool(tokenA.Symbol, tokenB.Symbol, reserveA.Raw(), reserveB.Raw())
+	if err != nil {
+		return nil, err
+	}
+	pool.SetDecimals(tokenA.Decimals, tokenB.Decimals)
+	return pool, nil
+}
+
+// poolToken returns the registered token of a simulated pool symbol, or a
+// token without decimals
+func poolToken(registry *domain.TokenRegistry, symbol string) domain.Token {
+	if token, err := registry.Lookup(symbol); err == nil {
+		return token
+	}
+	return domain.Token{Symbol: symbol}
+}
+
+// fundBots gives every bot the configured balances of the tokens of the
+// pool it trades
+func fundBots(s *swarm.Swarm, cfg *config.Config) error {
+	for _, pool := range s.Pools() {
+		tokenA, tokenB := pool.Snapshot().TokenInfo()
+		balanceA, err := configAmount(cfg.BotBalanceA, tokenA)
+		if err != nil {
+			return fmt.Errorf("invalid BOT_BALANCE_A: %w", err)
+		}
+		balanceB, err := configAmount(cfg.BotBalanceB, tokenB)
+		if err != nil {
+			return fmt.Errorf("invalid BOT_BALANCE_B: %w", err)
+		}
+		if err := s.FundPoolBots(pool, balanceA, balanceB); err != nil {
+			return fmt.Errorf("failed to fund bots: %w", err)
+		}
+		slog.Info("bots funded", "pair", tokenA.Symbol+"/"+tokenB.Symbol,
+			"balance_a", balanceA.String(), "balance_b", balanceB.String())
+	}
+	return nil
+}
 
-	// Wait briefly for bots to finish
-	time.Sleep(100 * time.Millisecond)
+// poolRun holds the candle recorder and report collector of a pool, and the
+// directory their files are written to
+type poolRun struct {
+	pool domain.AMM
+	dir  string
 
-	// Show final state
-	log.Printf("📊 Final pool state: ReserveA=%s, ReserveB=%s",
-		pool.ReserveA.String(), pool.ReserveB.String())
-	log.Printf("💰 Final price: 1 %s = %.4f %s", pool.TokenA, pool.PriceAInB(), pool.TokenB)
+	history     *candles.Recorder
+	historySub  *domain.Subscription
+	historyDone chan struct{}
+	collector   *report.Collector
+}
 
-	log.Println("👋 Goodbye!")
+// recordCandles starts building the OHLCV candles of the pool swaps
+func (r *poolRun) recordCandles(intervals []time.Duration) error {
+	history, err := candles.NewRecorder(intervals, 0)
+	if err != nil {
+		return fmt.Errorf("invalid candle intervals: %w", err)
+	}
+	r.history = history
+	r.historySub = r.pool.Subscribe(1024)
+	r.historyDone = make(chan struct{})
+	go func() {
+		history.Run(r.historySub)
+		close(r.historyDone)
+	}()
+	return nil
+}
+
+// writeFiles stops the candle recorder, then writes the candles and the run
+// report of the pool, with the final statuses of the bots trading it
+// Nothing is written without a report directory
+func (r *poolRun) writeFiles(bots []swarm.BotStatus, startedAt time.Time) {
+	tokenA, tokenB := r.pool.Tokens()
+	pair := tokenA + "/" + tokenB
+	if r.history != nil {
+		r.historySub.Close()
+		<-r.historyDone
+		if dropped := r.historySub.Dropped(); dropped > 0 {
+			slog.Warn("candle recorder fell behind", "pair", pair, "dropped_swaps", dropped)
+		}
+		if r.dir != "" {
+			paths, err := r.history.WriteFiles(r.dir, startedAt)
+			if err != nil {
+				slog.Error("failed to write candles", "pair", pair, "error", err)
+			} else {
+				slog.Info("candles written", "pair", pair, "files", paths)
+			}
+		}
+	}
+
+	if r.collector != nil {
+		var traders []swarm.BotStatus
+		for _, bot := range bots {
+			if bot.Pool == pair {
+				traders = append(traders, bot)
+			}
+		}
+		paths, err := r.collector.Report(traders).WriteFiles(r.dir)
+		if err != nil {
+			slog.Error("failed to write run report", "pair", pair, "error", err)
+		} else {
+			slog.Info("run report written", "pair", pair, "files", paths)
+		}
+	}
+}
+
+// configAmount reads a pool reserve or bot balance of the config: whole
+// tokens when it carries the symbol ("1000 ETH"), raw units otherwise
+func configAmount(value string, token domain.Token) (domain.TokenAmount, error) {
+	if len(strings.Fields(value)) == 2 {
+		return domain.ParseTokenAmount(value, token)
+	}
+	raw, ok := new(big.Int).SetString(strings.TrimSpace(value), 10)
+	if !ok {
+		return domain.TokenAmount{}, fmt.Errorf("%w: %q", domain.ErrInvalidAmount, value)
+	}
+	return token.Amount(raw), nil
+}
+
+// configureSwarm applies the bot settings of the config to a new swarm:
+// cadence, limits, default strategy and per-bot strategies
+func configureSwarm(s *swarm.Swarm, cfg *config.Config) error {
+	if err := s.SetSwapInterval(cfg.SwapInterval); err != nil {
+		return err
+	}
+	if err := s.SetTxInterval(cfg.TxInterval); err != nil {
+		return err
+	}
+	if err := s.SetMaxBots(cfg.MaxBots); err != nil {
+		return err
+	}
+	if err := s.SetDefaultStrategy(cfg.BotStrategy); err != nil {
+		return err
+	}
+	for _, bot := range cfg.Bots {
+		if bot.Strategy == "" {
+			continue
+		}
+		strategy, err := swarm.NewStrategy(bot.Strategy, nil)
+		if err != nil {
+			return err
+		}
+		if err := s.SetBotStrategy(bot.ID, strategy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transferAmounts resolves the bot ERC20 transfers through the registry:
+// TOKEN_TRANSFERS by symbol, otherwise TOKEN_TRANSFER_AMOUNT of TOKEN_ADDRESS
+// Empty when the bots send NEX
+func transferAmounts(cfg *config.Config, registry *domain.TokenRegistry) ([]domain.TokenAmount, error) {
+	if len(cfg.TokenTransfers) == 0 {
+		if cfg.TokenAddress == "" {
+			return nil, nil
+		}
+		token, err := registry.ByAddress(cfg.TokenAddress)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := domain.ParseTokenAmount(cfg.TokenTransferAmount, token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TOKEN_TRANSFER_AMOUNT: %w", err)
+		}
+		return []domain.TokenAmount{amount}, nil
+	}
+
+	amounts := make([]domain.TokenAmount, 0, len(cfg.TokenTransfers))
+	for _, transfer := range cfg.TokenTransfers {
+		amount, err := registry.ParseAmount(transfer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TOKEN_TRANSFERS: %w", err)
+		}
+		if amount.Token.Address == "" {
+			return nil, fmt.Errorf("invalid TOKEN_TRANSFERS: %s has no contract address", amount.Token.Symbol)
+		}
+		amounts = append(amounts, amount)
+	}
+	return amounts, nil
+}
+
+// serveAdmin exposes the admin API to operate the running swarm
+// It serves in the background until the returned server is closed
+func serveAdmin(addr string, s *swarm.Swarm) *http.Server {
+	srv := &http.Server{Addr: addr, Handler: api.NewServer(s)}
+	slog.Info("admin api listening", "addr", addr)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("admin api stopped", "error", err)
+		}
+	}()
+	return srv
+}
+
+// serveMetrics exposes the Prometheus endpoint
+// It serves in the background until the returned server is closed
+func serveMetrics(addr string, m *metrics.Metrics) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	slog.Info("metrics endpoint listening", "addr", addr, "path", "/metrics")
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server stopped", "error", err)
+		}
+	}()
+	return srv
 }
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.20;
+
+interface IERC20 {
+    function transfer(address to, uint256 amount) external returns (bool);
+    function transferFrom(address from, address to, uint256 amount) external returns (bool);
+}
+
+/**
+ * @title ConstantProductPair
+ * @dev x * y = k pool for two ERC20 tokens, with the same math as domain.Pool:
+ *      no swap fee, exact-in outputs rounded down, exact-out inputs rounded up.
+ *      Direction 0 sells TokenA for TokenB, 1 sells TokenB for TokenA.
+ *      Liquidity shares are tracked internally (not an ERC20).
+ */
+contract ConstantProductPair {
+    address public immutable tokenA;
+    address public immutable tokenB;
+
+    uint256 public reserveA;
+    uint256 public reserveB;
+
+    uint256 public totalSupply;
+    mapping(address => uint256) public balanceOf;
+
+    uint256 private unlocked = 1;
+
+    event Swap(address indexed trader, uint8 direction, uint256 amountIn, uint256 amountOut, uint256 reserveA, uint256 reserveB);
+    event Mint(address indexed provider, uint256 amountA, uint256 amountB, uint256 liquidity);
+    event Burn(address indexed provider, uint256 amountA, uint256 amountB, uint256 liquidity);
+
+    modifier lock() {
+        require(unlocked == 1, "Locked");
+        unlocked = 0;
+        _;
+        unlocked = 1;
+    }
+
+    constructor(address _tokenA, address _tokenB) {
+        require(_tokenA != address(0) && _tokenB != address(0), "Zero address");
+        require(_tokenA != _tokenB, "Identical tokens");
+        tokenA = _tokenA;
+        tokenB = _tokenB;
+    }
+
+    function getReserves() public view returns (uint256, uint256) {
+        return (reserveA, reserveB);
+    }
+
+    // out = reserveOut * amountIn / (reserveIn + amountIn), rounded down
+    function getAmountOut(uint8 direction, uint256 amountIn) public view returns (uint256) {
+        require(amountIn > 0, "Amount must be positive");
+        (uint256 reserveIn, uint256 reserveOut) = _reserves(direction);
+        return (reserveOut * amountIn) / (reserveIn + amountIn);
+    }
+
+    // in = reserveIn * amountOut / (reserveOut - amountOut), rounded up
+    function getAmountIn(uint8 direction, uint256 amountOut) public view returns (uint256) {
+        require(amountOut > 0, "Amount must be positive");
+        (uint256 reserveIn, uint256 reserveOut) = _reserves(direction);
+        require(amountOut < reserveOut, "Insufficient liquidity");
+        uint256 numerator = reserveIn * amountOut;
+        uint256 denominator = reserveOut - amountOut;
+        return (numerator + denominator - 1) / denominator;
+    }
+
+    function swap(uint8 direction, uint256 amountIn, uint256 minAmountOut) external lock returns (uint256 amountOut) {
+        amountOut = getAmountOut(direction, amountIn);
+        require(amountOut >= minAmountOut, "Insufficient output amount");
+        _swap(direction, amountIn, amountOut);
+    }
+
+    function swapExactOut(uint8 direction, uint256 amountOut, uint256 maxAmountIn) external lock returns (uint256 amountIn) {
+        amountIn = getAmountIn(direction, amountOut);
+        require(amountIn <= maxAmountIn, "Excessive input amount");
+        _swap(direction, amountIn, amountOut);
+    }
+
+    // The first deposit sets the price and mints sqrt(amountA * amountB) shares.
+    // Later deposits take both amounts and mint shares for the smaller side,
+    // the excess goes to the existing providers.
+    function addLiquidity(uint256 amountA, uint256 amountB) external lock returns (uint256 liquidity) {
+        require(amountA > 0 && amountB > 0, "Amounts must be positive");
+        if (totalSupply == 0) {
+            liquidity = _sqrt(amountA * amountB);
+        } else {
+            liquidity = _min((amountA * totalSupply) / reserveA, (amountB * totalSupply) / reserveB);
+        }
+        require(liquidity > 0, "Insufficient liquidity minted");
+
+        _pull(tokenA, amountA);
+        _pull(tokenB, amountB);
+        reserveA += amountA;
+        reserveB += amountB;
+        totalSupply += liquidity;
+        balanceOf[msg.sender] += liquidity;
+        emit Mint(msg.sender, amountA, amountB, liquidity);
+    }
+
+    function removeLiquidity(uint256 liquidity) external lock returns (uint256 amountA, uint256 amountB) {
+        require(liquidity > 0, "Amount must be positive");
+        require(balanceOf[msg.sender] >= liquidity, "Insufficient balance");
+        amountA = (liquidity * reserveA) / totalSupply;
+        amountB = (liquidity * reserveB) / totalSupply;
+        require(amountA > 0 && amountB > 0, "Insufficient liquidity burned");
+
+        balanceOf[msg.sender] -= liquidity;
+        totalSupply -= liquidity;
+        reserveA -= amountA;
+        reserveB -= amountB;
+        _push(tokenA, amountA);
+        _push(tokenB, amountB);
+        emit Burn(msg.sender, amountA, amountB, liquidity);
+    }
+
+    function _swap(uint8 direction, uint256 amountIn, uint256 amountOut) private {
+        require(amountOut > 0, "Insufficient output amount");
+        if (direction == 0) {
+            _pull(tokenA, amountIn);
+            _push(tokenB, amountOut);
+            reserveA += amountIn;
+            reserveB -= amountOut;
+        } else {
+            _pull(tokenB, amountIn);
+            _push(tokenA, amountOut);
+            reserveB += amountIn;
+            reserveA -= amountOut;
+        }
+        emit Swap(msg.sender, direction, amountIn, amountOut, reserveA, reserveB);
+    }
+
+    function _reserves(uint8 direction) private view returns (uint256 reserveIn, uint256 reserveOut) {
+        require(direction <= 1, "Invalid direction");
+        require(reserveA > 0 && reserveB > 0, "No liquidity");
+        return direction == 0 ? (reserveA, reserveB) : (reserveB, reserveA);
+    }
+
+    function _pull(address token, uint256 amount) private {
+        require(IERC20(token).transferFrom(msg.sender, address(this), amount), "Transfer failed");
+    }
+
+    function _push(address token, uint256 amount) private {
+        require(IERC20(token).transfer(msg.sender, amount), "Transfer failed");
+    }
+
+    function _sqrt(uint256 y) private pure returns (uint256 z) {
+        if (y > 3) {
+            z = y;
+            uint256 x = y / 2 + 1;
+            while (x < z) {
+                z = x;
+                x = (y / x + x) / 2;
+            }
+        } else if (y != 0) {
+            z = 1;
+        }
+    }
+
+    function _min(uint256 x, uint256 y) private pure returns (uint256) {
+        return x < y ? x : y;
+    }
+}
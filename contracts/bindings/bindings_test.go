@@ -0,0 +1,74 @@
+package bindings
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// TestBindings_UpToDate fails when an ABI file changed without go generate
+func TestBindings_UpToDate(t *testing.T) {
+	for file, meta := range map[string]string{
+		"../ConstantProductPair.abi": ConstantProductPairMetaData.ABI,
+		"../KevzToken.abi":           KevzTokenMetaData.ABI,
+	} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		fromFile, err := abi.JSON(strings.NewReader(string(data)))
+		if err != nil {
+			t.Fatalf("%s: invalid ABI: %v", file, err)
+		}
+		generated, err := abi.JSON(strings.NewReader(meta))
+		if err != nil {
+			t.Fatalf("%s: invalid generated ABI: %v", file, err)
+		}
+
+		for name, method := range fromFile.Methods {
+			if generated.Methods[name].Sig != method.Sig {
+				t.Errorf("%s: method %s is not in the bindings, run go generate", file, method.Sig)
+			}
+		}
+		for name, event := range fromFile.Events {
+			if generated.Events[name].ID != event.ID {
+				t.Errorf("%s: event %s is not in the bindings, run go generate", file, event.Sig)
+			}
+		}
+		if len(generated.Methods) != len(fromFile.Methods) || len(generated.Events) != len(fromFile.Events) {
+			t.Errorf("%s: bindings have extra methods or events, run go generate", file)
+		}
+	}
+}
+
+// TestConstantProductPair_ABI pins the signatures callers and the deploy
+// tooling rely on, so the ABI cannot drift from ConstantProductPair.sol
+func TestConstantProductPair_ABI(t *testing.T) {
+	parsed, err := ConstantProductPairMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, sig := range []string{
+		"swap(uint8,uint256,uint256)",
+		"swapExactOut(uint8,uint256,uint256)",
+		"getAmountOut(uint8,uint256)",
+		"getAmountIn(uint8,uint256)",
+		"addLiquidity(uint256,uint256)",
+		"removeLiquidity(uint256)",
+		"getReserves()",
+	} {
+		name := sig[:strings.Index(sig, "(")]
+		if got := parsed.Methods[name].Sig; got != sig {
+			t.Errorf("expected method %s, got %q", sig, got)
+		}
+	}
+	if got := parsed.Events["Swap"].Sig; got != "Swap(address,uint8,uint256,uint256,uint256,uint256)" {
+		t.Errorf("unexpected Swap event %q", got)
+	}
+	if inputs := parsed.Constructor.Inputs; len(inputs) != 2 {
+		t.Errorf("expected a (tokenA, tokenB) constructor, got %d inputs", len(inputs))
+	}
+}
@@ -0,0 +1,252 @@
+package bindings
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/internal/deploy"
+)
+
+// pairHarness drives a ConstantProductPair on a simulated chain, mining a
+// block after every transaction
+type pairHarness struct {
+	t      *testing.T
+	sim    *simulated.Backend
+	opts   *bind.TransactOpts
+	pair   *ConstantProductPair
+	tokenA common.Address
+	tokenB common.Address
+}
+
+// newPairHarness deploys two KevzTokens and a pair holding reserveA/reserveB
+// Skips the test when the contracts have not been compiled
+func newPairHarness(t *testing.T, reserveA, reserveB *big.Int) *pairHarness {
+	t.Helper()
+	token, err := deploy.LoadArtifact("..", "KevzToken")
+	if errors.Is(err, deploy.ErrNoBytecode) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pairArtifact, err := deploy.LoadArtifact("..", "ConstantProductPair")
+	if errors.Is(err, deploy.ErrNoBytecode) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	sim := simulated.NewBackend(types.GenesisAlloc{from: {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(21), nil)}})
+	t.Cleanup(func() { sim.Close() })
+	chainID, _ := sim.Client().ChainID(context.Background())
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	h := &pairHarness{t: t, sim: sim, opts: opts}
+	h.tokenA = h.deploy(token)
+	h.tokenB = h.deploy(token)
+	pairAddress := h.deploy(pairArtifact, h.tokenA, h.tokenB)
+	h.pair, err = NewConstantProductPair(pairAddress, sim.Client())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	maxUint := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	for _, address := range []common.Address{h.tokenA, h.tokenB} {
+		erc20, err := NewKevzToken(address, sim.Client())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		h.mine(erc20.Approve(opts, pairAddress, maxUint))
+	}
+	h.mine(h.pair.AddLiquidity(opts, reserveA, reserveB))
+	return h
+}
+
+func (h *pairHarness) deploy(artifact *deploy.Artifact, args ...interface{}) common.Address {
+	h.t.Helper()
+	address, tx, _, err := bind.DeployContract(h.opts, artifact.ABI, artifact.Bytecode, h.sim.Client(), args...)
+	h.mine(tx, err)
+	return address
+}
+
+// mine commits the block holding tx and fails the test unless it succeeded
+func (h *pairHarness) mine(tx *types.Transaction, err error) *types.Receipt {
+	h.t.Helper()
+	if err != nil {
+		h.t.Fatalf("failed to send tx: %v", err)
+	}
+	h.sim.Commit()
+	receipt, err := h.sim.Client().TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		h.t.Fatalf("failed to get receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		h.t.Fatalf("tx %s reverted", tx.Hash().Hex())
+	}
+	return receipt
+}
+
+// swapEvent returns the Swap event emitted by a mined swap
+func (h *pairHarness) swapEvent(receipt *types.Receipt) *ConstantProductPairSwap {
+	h.t.Helper()
+	for _, log := range receipt.Logs {
+		if event, err := h.pair.ParseSwap(*log); err == nil {
+			return event
+		}
+	}
+	h.t.Fatalf("no Swap event in tx %s", receipt.TxHash.Hex())
+	return nil
+}
+
+func (h *pairHarness) reserves() (reserveA, reserveB *big.Int) {
+	h.t.Helper()
+	reserveA, reserveB, err := h.pair.GetReserves(nil)
+	if err != nil {
+		h.t.Fatalf("failed to read reserves: %v", err)
+	}
+	return reserveA, reserveB
+}
+
+// randomAmount returns an amount between 1 wei and max/20, log-uniform so
+// dust swaps that stress rounding are as likely as large ones
+func randomAmount(rng *rand.Rand, max *big.Int) *big.Int {
+	limit := new(big.Int).Div(max, big.NewInt(20))
+	bits := rng.Intn(limit.BitLen()) + 1
+	n := new(big.Int).Rand(rng, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+	n.Mod(n, limit)
+	return n.Add(n, big.NewInt(1))
+}
+
+// TestDifferential_PoolMatchesContract runs the same random swaps on
+// domain.Pool and ConstantProductPair and requires quotes, swap amounts and
+// reserves to match to the wei
+func TestDifferential_PoolMatchesContract(t *testing.T) {
+	steps := 200
+	if testing.Short() {
+		steps = 40
+	}
+
+	for _, seed := range []int64{1, 2, 3} {
+		reserveA, _ := new(big.Int).SetString("1000000000000000000000", 10)
+		reserveB, _ := new(big.Int).SetString("2500000000000000000000", 10)
+		h := newPairHarness(t, reserveA, reserveB)
+		pool := domain.MustNewPool("A", "B", reserveA, reserveB)
+		rng := rand.New(rand.NewSource(seed))
+
+		for step := 0; step < steps; step++ {
+			direction := domain.Direction(rng.Intn(2))
+			exactIn := rng.Intn(2) == 0
+			reserveIn, reserveOut := pool.Reserves()
+			if direction == domain.BToA {
+				reserveIn, reserveOut = reserveOut, reserveIn
+			}
+
+			var quote, contractQuote *big.Int
+			var amount *big.Int
+			var err error
+			if exactIn {
+				amount = randomAmount(rng, reserveIn)
+				quote, err = pool.Quote(direction, amount)
+				if err != nil {
+					t.Fatalf("seed %d step %d: unexpected error: %v", seed, step, err)
+				}
+				contractQuote, err = h.pair.GetAmountOut(nil, uint8(direction), amount)
+			} else {
+				amount = randomAmount(rng, reserveOut)
+				quote, err = pool.QuoteExactOut(direction, amount)
+				if err != nil {
+					t.Fatalf("seed %d step %d: unexpected error: %v", seed, step, err)
+				}
+				contractQuote, err = h.pair.GetAmountIn(nil, uint8(direction), amount)
+			}
+			if err != nil {
+				t.Fatalf("seed %d step %d: contract quote failed: %v", seed, step, err)
+			}
+			if quote.Cmp(contractQuote) != 0 {
+				t.Fatalf("seed %d step %d: %s exactIn=%v %s: Go quotes %s, contract %s",
+					seed, step, direction, exactIn, amount, quote, contractQuote)
+			}
+
+			// the contract refuses swaps that give nothing, the Go pool fills them
+			if exactIn && quote.Sign() == 0 {
+				if _, err := h.pair.Swap(h.opts, uint8(direction), amount, common.Big0); err == nil {
+					t.Fatalf("seed %d step %d: expected the contract to reject a zero output swap", seed, step)
+				}
+				continue
+			}
+
+			var receipt *types.Receipt
+			var in, out *big.Int
+			if exactIn {
+				receipt = h.mine(h.pair.Swap(h.opts, uint8(direction), amount, quote))
+				in = amount
+				out, err = pool.Swap(0, direction, amount)
+			} else {
+				receipt = h.mine(h.pair.SwapExactOut(h.opts, uint8(direction), amount, quote))
+				out = amount
+				in, err = pool.SwapExactOut(0, direction, amount)
+			}
+			if err != nil {
+				t.Fatalf("seed %d step %d: unexpected error: %v", seed, step, err)
+			}
+
+			event := h.swapEvent(receipt)
+			if event.AmountIn.Cmp(in) != 0 || event.AmountOut.Cmp(out) != 0 {
+				t.Fatalf("seed %d step %d: Go swapped %s for %s, contract %s for %s",
+					seed, step, in, out, event.AmountIn, event.AmountOut)
+			}
+			goA, goB := pool.Reserves()
+			chainA, chainB := h.reserves()
+			if goA.Cmp(chainA) != 0 || goB.Cmp(chainB) != 0 {
+				t.Fatalf("seed %d step %d: Go reserves %s/%s, contract %s/%s", seed, step, goA, goB, chainA, chainB)
+			}
+			if event.ReserveA.Cmp(chainA) != 0 || event.ReserveB.Cmp(chainB) != 0 {
+				t.Fatalf("seed %d step %d: Swap event reserves %s/%s, contract %s/%s",
+					seed, step, event.ReserveA, event.ReserveB, chainA, chainB)
+			}
+		}
+	}
+}
+
+// TestDifferential_Rejections checks the contract and the Go pool refuse the
+// same exact-out trades
+func TestDifferential_Rejections(t *testing.T) {
+	reserveA, reserveB := big.NewInt(1_000_000), big.NewInt(3_000_000)
+	h := newPairHarness(t, reserveA, reserveB)
+	pool := domain.MustNewPool("A", "B", reserveA, reserveB)
+
+	for _, amount := range []*big.Int{reserveB, new(big.Int).Add(reserveB, big.NewInt(1))} {
+		if _, err := pool.QuoteExactOut(domain.AToB, amount); !errors.Is(err, domain.ErrInsufficientLiquidity) {
+			t.Errorf("expected ErrInsufficientLiquidity buying %s, got %v", amount, err)
+		}
+		if _, err := h.pair.GetAmountIn(nil, uint8(domain.AToB), amount); err == nil {
+			t.Errorf("expected the contract to reject buying %s", amount)
+		}
+	}
+
+	// a max input one wei below the quote must revert
+	amount := big.NewInt(1000)
+	quote, err := pool.QuoteExactOut(domain.AToB, amount)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := h.pair.SwapExactOut(h.opts, uint8(domain.AToB), amount, new(big.Int).Sub(quote, big.NewInt(1))); err == nil {
+		t.Error("expected a revert with maxAmountIn below the quote")
+	}
+	h.mine(h.pair.SwapExactOut(h.opts, uint8(domain.AToB), amount, quote))
+}
@@ -0,0 +1,8 @@
+// Package bindings holds the Go bindings of the contracts in contracts/,
+// generated by abigen from the ABI files. Regenerate after changing an ABI:
+//
+//	go generate ./contracts/bindings
+package bindings
+
+//go:generate go run github.com/ethereum/go-ethereum/cmd/abigen --abi ../ConstantProductPair.abi --pkg bindings --type ConstantProductPair --out pair.go
+//go:generate go run github.com/ethereum/go-ethereum/cmd/abigen --abi ../KevzToken.abi --pkg bindings --type KevzToken --out kevztoken.go
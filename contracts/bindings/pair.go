@@ -0,0 +1,988 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// ConstantProductPairMetaData contains all meta data concerning the ConstantProductPair contract.
+var ConstantProductPairMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"name\":\"_tokenA\",\"type\":\"address\"},{\"name\":\"_tokenB\",\"type\":\"address\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"provider\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"amountA\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"amountB\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"liquidity\",\"type\":\"uint256\"}],\"name\":\"Burn\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"provider\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"amountA\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"amountB\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"liquidity\",\"type\":\"uint256\"}],\"name\":\"Mint\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"trader\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"direction\",\"type\":\"uint8\"},{\"indexed\":false,\"name\":\"amountIn\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"amountOut\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"reserveA\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"reserveB\",\"type\":\"uint256\"}],\"name\":\"Swap\",\"type\":\"event\"},{\"inputs\":[{\"name\":\"amountA\",\"type\":\"uint256\"},{\"name\":\"amountB\",\"type\":\"uint256\"}],\"name\":\"addLiquidity\",\"outputs\":[{\"name\":\"liquidity\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"direction\",\"type\":\"uint8\"},{\"name\":\"amountOut\",\"type\":\"uint256\"}],\"name\":\"getAmountIn\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"direction\",\"type\":\"uint8\"},{\"name\":\"amountIn\",\"type\":\"uint256\"}],\"name\":\"getAmountOut\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getReserves\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"},{\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"liquidity\",\"type\":\"uint256\"}],\"name\":\"removeLiquidity\",\"outputs\":[{\"name\":\"amountA\",\"type\":\"uint256\"},{\"name\":\"amountB\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"reserveA\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"reserveB\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"direction\",\"type\":\"uint8\"},{\"name\":\"amountIn\",\"type\":\"uint256\"},{\"name\":\"minAmountOut\",\"type\":\"uint256\"}],\"name\":\"swap\",\"outputs\":[{\"name\":\"amountOut\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"direction\",\"type\":\"uint8\"},{\"name\":\"amountOut\",\"type\":\"uint256\"},{\"name\":\"maxAmountIn\",\"type\":\"uint256\"}],\"name\":\"swapExactOut\",\"outputs\":[{\"name\":\"amountIn\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"tokenA\",\"outputs\":[{\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"tokenB\",\"outputs\":[{\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"totalSupply\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// ConstantProductPairABI is the input ABI used to generate the binding from.
+// Deprecated: Use ConstantProductPairMetaData.ABI instead.
+var ConstantProductPairABI = ConstantProductPairMetaData.ABI
+
+// ConstantProductPair is an auto generated Go binding around an Ethereum contract.
+type ConstantProductPair struct {
+	ConstantProductPairCaller     // Read-only binding to the contract
+	ConstantProductPairTransactor // Write-only binding to the contract
+	ConstantProductPairFilterer   // Log filterer for contract events
+}
+
+// ConstantProductPairCaller is an auto generated read-only Go binding around an Ethereum contract.
+type ConstantProductPairCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ConstantProductPairTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type ConstantProductPairTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ConstantProductPairFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type ConstantProductPairFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ConstantProductPairSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type ConstantProductPairSession struct {
+	Contract     *ConstantProductPair // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts        // Call options to use throughout this session
+	TransactOpts bind.TransactOpts    // Transaction auth options to use throughout this session
+}
+
+// ConstantProductPairCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type ConstantProductPairCallerSession struct {
+	Contract *ConstantProductPairCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts              // Call options to use throughout this session
+}
+
+// ConstantProductPairTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type ConstantProductPairTransactorSession struct {
+	Contract     *ConstantProductPairTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts              // Transaction auth options to use throughout this session
+}
+
+// ConstantProductPairRaw is an auto generated low-level Go binding around an Ethereum contract.
+type ConstantProductPairRaw struct {
+	Contract *ConstantProductPair // Generic contract binding to access the raw methods on
+}
+
+// ConstantProductPairCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type ConstantProductPairCallerRaw struct {
+	Contract *ConstantProductPairCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// ConstantProductPairTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type ConstantProductPairTransactorRaw struct {
+	Contract *ConstantProductPairTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewConstantProductPair creates a new instance of ConstantProductPair, bound to a specific deployed contract.
+func NewConstantProductPair(address common.Address, backend bind.ContractBackend) (*ConstantProductPair, error) {
+	contract, err := bindConstantProductPair(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &ConstantProductPair{ConstantProductPairCaller: ConstantProductPairCaller{contract: contract}, ConstantProductPairTransactor: ConstantProductPairTransactor{contract: contract}, ConstantProductPairFilterer: ConstantProductPairFilterer{contract: contract}}, nil
+}
+
+// NewConstantProductPairCaller creates a new read-only instance of ConstantProductPair, bound to a specific deployed contract.
+func NewConstantProductPairCaller(address common.Address, caller bind.ContractCaller) (*ConstantProductPairCaller, error) {
+	contract, err := bindConstantProductPair(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ConstantProductPairCaller{contract: contract}, nil
+}
+
+// NewConstantProductPairTransactor creates a new write-only instance of ConstantProductPair, bound to a specific deployed contract.
+func NewConstantProductPairTransactor(address common.Address, transactor bind.ContractTransactor) (*ConstantProductPairTransactor, error) {
+	contract, err := bindConstantProductPair(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ConstantProductPairTransactor{contract: contract}, nil
+}
+
+// NewConstantProductPairFilterer creates a new log filterer instance of ConstantProductPair, bound to a specific deployed contract.
+func NewConstantProductPairFilterer(address common.Address, filterer bind.ContractFilterer) (*ConstantProductPairFilterer, error) {
+	contract, err := bindConstantProductPair(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ConstantProductPairFilterer{contract: contract}, nil
+}
+
+// bindConstantProductPair binds a generic wrapper to an already deployed contract.
+func bindConstantProductPair(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := ConstantProductPairMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ConstantProductPair *ConstantProductPairRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ConstantProductPair.Contract.ConstantProductPairCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ConstantProductPair *ConstantProductPairRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.ConstantProductPairTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ConstantProductPair *ConstantProductPairRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.ConstantProductPairTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ConstantProductPair *ConstantProductPairCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ConstantProductPair.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ConstantProductPair *ConstantProductPairTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ConstantProductPair *ConstantProductPairTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.contract.Transact(opts, method, params...)
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address ) view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCaller) BalanceOf(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _ConstantProductPair.contract.Call(opts, &out, "balanceOf", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address ) view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairSession) BalanceOf(arg0 common.Address) (*big.Int, error) {
+	return _ConstantProductPair.Contract.BalanceOf(&_ConstantProductPair.CallOpts, arg0)
+}
+
+// BalanceOf is a free data retrieval call binding the contract method 0x70a08231.
+//
+// Solidity: function balanceOf(address ) view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCallerSession) BalanceOf(arg0 common.Address) (*big.Int, error) {
+	return _ConstantProductPair.Contract.BalanceOf(&_ConstantProductPair.CallOpts, arg0)
+}
+
+// GetAmountIn is a free data retrieval call binding the contract method 0xc33748dc.
+//
+// Solidity: function getAmountIn(uint8 direction, uint256 amountOut) view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCaller) GetAmountIn(opts *bind.CallOpts, direction uint8, amountOut *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _ConstantProductPair.contract.Call(opts, &out, "getAmountIn", direction, amountOut)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetAmountIn is a free data retrieval call binding the contract method 0xc33748dc.
+//
+// Solidity: function getAmountIn(uint8 direction, uint256 amountOut) view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairSession) GetAmountIn(direction uint8, amountOut *big.Int) (*big.Int, error) {
+	return _ConstantProductPair.Contract.GetAmountIn(&_ConstantProductPair.CallOpts, direction, amountOut)
+}
+
+// GetAmountIn is a free data retrieval call binding the contract method 0xc33748dc.
+//
+// Solidity: function getAmountIn(uint8 direction, uint256 amountOut) view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCallerSession) GetAmountIn(direction uint8, amountOut *big.Int) (*big.Int, error) {
+	return _ConstantProductPair.Contract.GetAmountIn(&_ConstantProductPair.CallOpts, direction, amountOut)
+}
+
+// GetAmountOut is a free data retrieval call binding the contract method 0xaf2ba65c.
+//
+// Solidity: function getAmountOut(uint8 direction, uint256 amountIn) view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCaller) GetAmountOut(opts *bind.CallOpts, direction uint8, amountIn *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _ConstantProductPair.contract.Call(opts, &out, "getAmountOut", direction, amountIn)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetAmountOut is a free data retrieval call binding the contract method 0xaf2ba65c.
+//
+// Solidity: function getAmountOut(uint8 direction, uint256 amountIn) view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairSession) GetAmountOut(direction uint8, amountIn *big.Int) (*big.Int, error) {
+	return _ConstantProductPair.Contract.GetAmountOut(&_ConstantProductPair.CallOpts, direction, amountIn)
+}
+
+// GetAmountOut is a free data retrieval call binding the contract method 0xaf2ba65c.
+//
+// Solidity: function getAmountOut(uint8 direction, uint256 amountIn) view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCallerSession) GetAmountOut(direction uint8, amountIn *big.Int) (*big.Int, error) {
+	return _ConstantProductPair.Contract.GetAmountOut(&_ConstantProductPair.CallOpts, direction, amountIn)
+}
+
+// GetReserves is a free data retrieval call binding the contract method 0x0902f1ac.
+//
+// Solidity: function getReserves() view returns(uint256, uint256)
+func (_ConstantProductPair *ConstantProductPairCaller) GetReserves(opts *bind.CallOpts) (*big.Int, *big.Int, error) {
+	var out []interface{}
+	err := _ConstantProductPair.contract.Call(opts, &out, "getReserves")
+
+	if err != nil {
+		return *new(*big.Int), *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	out1 := *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+
+	return out0, out1, err
+
+}
+
+// GetReserves is a free data retrieval call binding the contract method 0x0902f1ac.
+//
+// Solidity: function getReserves() view returns(uint256, uint256)
+func (_ConstantProductPair *ConstantProductPairSession) GetReserves() (*big.Int, *big.Int, error) {
+	return _ConstantProductPair.Contract.GetReserves(&_ConstantProductPair.CallOpts)
+}
+
+// GetReserves is a free data retrieval call binding the contract method 0x0902f1ac.
+//
+// Solidity: function getReserves() view returns(uint256, uint256)
+func (_ConstantProductPair *ConstantProductPairCallerSession) GetReserves() (*big.Int, *big.Int, error) {
+	return _ConstantProductPair.Contract.GetReserves(&_ConstantProductPair.CallOpts)
+}
+
+// ReserveA is a free data retrieval call binding the contract method 0xdc5fa6c5.
+//
+// Solidity: function reserveA() view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCaller) ReserveA(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _ConstantProductPair.contract.Call(opts, &out, "reserveA")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// ReserveA is a free data retrieval call binding the contract method 0xdc5fa6c5.
+//
+// Solidity: function reserveA() view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairSession) ReserveA() (*big.Int, error) {
+	return _ConstantProductPair.Contract.ReserveA(&_ConstantProductPair.CallOpts)
+}
+
+// ReserveA is a free data retrieval call binding the contract method 0xdc5fa6c5.
+//
+// Solidity: function reserveA() view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCallerSession) ReserveA() (*big.Int, error) {
+	return _ConstantProductPair.Contract.ReserveA(&_ConstantProductPair.CallOpts)
+}
+
+// ReserveB is a free data retrieval call binding the contract method 0x19e36f3b.
+//
+// Solidity: function reserveB() view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCaller) ReserveB(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _ConstantProductPair.contract.Call(opts, &out, "reserveB")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// ReserveB is a free data retrieval call binding the contract method 0x19e36f3b.
+//
+// Solidity: function reserveB() view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairSession) ReserveB() (*big.Int, error) {
+	return _ConstantProductPair.Contract.ReserveB(&_ConstantProductPair.CallOpts)
+}
+
+// ReserveB is a free data retrieval call binding the contract method 0x19e36f3b.
+//
+// Solidity: function reserveB() view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCallerSession) ReserveB() (*big.Int, error) {
+	return _ConstantProductPair.Contract.ReserveB(&_ConstantProductPair.CallOpts)
+}
+
+// TokenA is a free data retrieval call binding the contract method 0x0fc63d10.
+//
+// Solidity: function tokenA() view returns(address)
+func (_ConstantProductPair *ConstantProductPairCaller) TokenA(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _ConstantProductPair.contract.Call(opts, &out, "tokenA")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// TokenA is a free data retrieval call binding the contract method 0x0fc63d10.
+//
+// Solidity: function tokenA() view returns(address)
+func (_ConstantProductPair *ConstantProductPairSession) TokenA() (common.Address, error) {
+	return _ConstantProductPair.Contract.TokenA(&_ConstantProductPair.CallOpts)
+}
+
+// TokenA is a free data retrieval call binding the contract method 0x0fc63d10.
+//
+// Solidity: function tokenA() view returns(address)
+func (_ConstantProductPair *ConstantProductPairCallerSession) TokenA() (common.Address, error) {
+	return _ConstantProductPair.Contract.TokenA(&_ConstantProductPair.CallOpts)
+}
+
+// TokenB is a free data retrieval call binding the contract method 0x5f64b55b.
+//
+// Solidity: function tokenB() view returns(address)
+func (_ConstantProductPair *ConstantProductPairCaller) TokenB(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _ConstantProductPair.contract.Call(opts, &out, "tokenB")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// TokenB is a free data retrieval call binding the contract method 0x5f64b55b.
+//
+// Solidity: function tokenB() view returns(address)
+func (_ConstantProductPair *ConstantProductPairSession) TokenB() (common.Address, error) {
+	return _ConstantProductPair.Contract.TokenB(&_ConstantProductPair.CallOpts)
+}
+
+// TokenB is a free data retrieval call binding the contract method 0x5f64b55b.
+//
+// Solidity: function tokenB() view returns(address)
+func (_ConstantProductPair *ConstantProductPairCallerSession) TokenB() (common.Address, error) {
+	return _ConstantProductPair.Contract.TokenB(&_ConstantProductPair.CallOpts)
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCaller) TotalSupply(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _ConstantProductPair.contract.Call(opts, &out, "totalSupply")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairSession) TotalSupply() (*big.Int, error) {
+	return _ConstantProductPair.Contract.TotalSupply(&_ConstantProductPair.CallOpts)
+}
+
+// TotalSupply is a free data retrieval call binding the contract method 0x18160ddd.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (_ConstantProductPair *ConstantProductPairCallerSession) TotalSupply() (*big.Int, error) {
+	return _ConstantProductPair.Contract.TotalSupply(&_ConstantProductPair.CallOpts)
+}
+
+// AddLiquidity is a paid mutator transaction binding the contract method 0x9cd441da.
+//
+// Solidity: function addLiquidity(uint256 amountA, uint256 amountB) returns(uint256 liquidity)
+func (_ConstantProductPair *ConstantProductPairTransactor) AddLiquidity(opts *bind.TransactOpts, amountA *big.Int, amountB *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.contract.Transact(opts, "addLiquidity", amountA, amountB)
+}
+
+// AddLiquidity is a paid mutator transaction binding the contract method 0x9cd441da.
+//
+// Solidity: function addLiquidity(uint256 amountA, uint256 amountB) returns(uint256 liquidity)
+func (_ConstantProductPair *ConstantProductPairSession) AddLiquidity(amountA *big.Int, amountB *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.AddLiquidity(&_ConstantProductPair.TransactOpts, amountA, amountB)
+}
+
+// AddLiquidity is a paid mutator transaction binding the contract method 0x9cd441da.
+//
+// Solidity: function addLiquidity(uint256 amountA, uint256 amountB) returns(uint256 liquidity)
+func (_ConstantProductPair *ConstantProductPairTransactorSession) AddLiquidity(amountA *big.Int, amountB *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.AddLiquidity(&_ConstantProductPair.TransactOpts, amountA, amountB)
+}
+
+// RemoveLiquidity is a paid mutator transaction binding the contract method 0x9c8f9f23.
+//
+// Solidity: function removeLiquidity(uint256 liquidity) returns(uint256 amountA, uint256 amountB)
+func (_ConstantProductPair *ConstantProductPairTransactor) RemoveLiquidity(opts *bind.TransactOpts, liquidity *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.contract.Transact(opts, "removeLiquidity", liquidity)
+}
+
+// RemoveLiquidity is a paid mutator transaction binding the contract method 0x9c8f9f23.
+//
+// Solidity: function removeLiquidity(uint256 liquidity) returns(uint256 amountA, uint256 amountB)
+func (_ConstantProductPair *ConstantProductPairSession) RemoveLiquidity(liquidity *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.RemoveLiquidity(&_ConstantProductPair.TransactOpts, liquidity)
+}
+
+// RemoveLiquidity is a paid mutator transaction binding the contract method 0x9c8f9f23.
+//
+// Solidity: function removeLiquidity(uint256 liquidity) returns(uint256 amountA, uint256 amountB)
+func (_ConstantProductPair *ConstantProductPairTransactorSession) RemoveLiquidity(liquidity *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.RemoveLiquidity(&_ConstantProductPair.TransactOpts, liquidity)
+}
+
+// Swap is a paid mutator transaction binding the contract method 0x0830fd86.
+//
+// Solidity: function swap(uint8 direction, uint256 amountIn, uint256 minAmountOut) returns(uint256 amountOut)
+func (_ConstantProductPair *ConstantProductPairTransactor) Swap(opts *bind.TransactOpts, direction uint8, amountIn *big.Int, minAmountOut *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.contract.Transact(opts, "swap", direction, amountIn, minAmountOut)
+}
+
+// Swap is a paid mutator transaction binding the contract method 0x0830fd86.
+//
+// Solidity: function swap(uint8 direction, uint256 amountIn, uint256 minAmountOut) returns(uint256 amountOut)
+func (_ConstantProductPair *ConstantProductPairSession) Swap(direction uint8, amountIn *big.Int, minAmountOut *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.Swap(&_ConstantProductPair.TransactOpts, direction, amountIn, minAmountOut)
+}
+
+// Swap is a paid mutator transaction binding the contract method 0x0830fd86.
+//
+// Solidity: function swap(uint8 direction, uint256 amountIn, uint256 minAmountOut) returns(uint256 amountOut)
+func (_ConstantProductPair *ConstantProductPairTransactorSession) Swap(direction uint8, amountIn *big.Int, minAmountOut *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.Swap(&_ConstantProductPair.TransactOpts, direction, amountIn, minAmountOut)
+}
+
+// SwapExactOut is a paid mutator transaction binding the contract method 0x482391f2.
+//
+// Solidity: function swapExactOut(uint8 direction, uint256 amountOut, uint256 maxAmountIn) returns(uint256 amountIn)
+func (_ConstantProductPair *ConstantProductPairTransactor) SwapExactOut(opts *bind.TransactOpts, direction uint8, amountOut *big.Int, maxAmountIn *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.contract.Transact(opts, "swapExactOut", direction, amountOut, maxAmountIn)
+}
+
+// SwapExactOut is a paid mutator transaction binding the contract method 0x482391f2.
+//
+// Solidity: function swapExactOut(uint8 direction, uint256 amountOut, uint256 maxAmountIn) returns(uint256 amountIn)
+func (_ConstantProductPair *ConstantProductPairSession) SwapExactOut(direction uint8, amountOut *big.Int, maxAmountIn *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.SwapExactOut(&_ConstantProductPair.TransactOpts, direction, amountOut, maxAmountIn)
+}
+
+// SwapExactOut is a paid mutator transaction binding the contract method 0x482391f2.
+//
+// Solidity: function swapExactOut(uint8 direction, uint256 amountOut, uint256 maxAmountIn) returns(uint256 amountIn)
+func (_ConstantProductPair *ConstantProductPairTransactorSession) SwapExactOut(direction uint8, amountOut *big.Int, maxAmountIn *big.Int) (*types.Transaction, error) {
+	return _ConstantProductPair.Contract.SwapExactOut(&_ConstantProductPair.TransactOpts, direction, amountOut, maxAmountIn)
+}
+
+// ConstantProductPairBurnIterator is returned from FilterBurn and is used to iterate over the raw logs and unpacked data for Burn events raised by the ConstantProductPair contract.
+type ConstantProductPairBurnIterator struct {
+	Event *ConstantProductPairBurn // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ConstantProductPairBurnIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ConstantProductPairBurn)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ConstantProductPairBurn)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ConstantProductPairBurnIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ConstantProductPairBurnIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ConstantProductPairBurn represents a Burn event raised by the ConstantProductPair contract.
+type ConstantProductPairBurn struct {
+	Provider  common.Address
+	AmountA   *big.Int
+	AmountB   *big.Int
+	Liquidity *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterBurn is a free log retrieval operation binding the contract event 0x743033787f4738ff4d6a7225ce2bd0977ee5f86b91a902a58f5e4d0b297b4644.
+//
+// Solidity: event Burn(address indexed provider, uint256 amountA, uint256 amountB, uint256 liquidity)
+func (_ConstantProductPair *ConstantProductPairFilterer) FilterBurn(opts *bind.FilterOpts, provider []common.Address) (*ConstantProductPairBurnIterator, error) {
+
+	var providerRule []interface{}
+	for _, providerItem := range provider {
+		providerRule = append(providerRule, providerItem)
+	}
+
+	logs, sub, err := _ConstantProductPair.contract.FilterLogs(opts, "Burn", providerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ConstantProductPairBurnIterator{contract: _ConstantProductPair.contract, event: "Burn", logs: logs, sub: sub}, nil
+}
+
+// WatchBurn is a free log subscription operation binding the contract event 0x743033787f4738ff4d6a7225ce2bd0977ee5f86b91a902a58f5e4d0b297b4644.
+//
+// Solidity: event Burn(address indexed provider, uint256 amountA, uint256 amountB, uint256 liquidity)
+func (_ConstantProductPair *ConstantProductPairFilterer) WatchBurn(opts *bind.WatchOpts, sink chan<- *ConstantProductPairBurn, provider []common.Address) (event.Subscription, error) {
+
+	var providerRule []interface{}
+	for _, providerItem := range provider {
+		providerRule = append(providerRule, providerItem)
+	}
+
+	logs, sub, err := _ConstantProductPair.contract.WatchLogs(opts, "Burn", providerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ConstantProductPairBurn)
+				if err := _ConstantProductPair.contract.UnpackLog(event, "Burn", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseBurn is a log parse operation binding the contract event 0x743033787f4738ff4d6a7225ce2bd0977ee5f86b91a902a58f5e4d0b297b4644.
+//
+// Solidity: event Burn(address indexed provider, uint256 amountA, uint256 amountB, uint256 liquidity)
+func (_ConstantProductPair *ConstantProductPairFilterer) ParseBurn(log types.Log) (*ConstantProductPairBurn, error) {
+	event := new(ConstantProductPairBurn)
+	if err := _ConstantProductPair.contract.UnpackLog(event, "Burn", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// ConstantProductPairMintIterator is returned from FilterMint and is used to iterate over the raw logs and unpacked data for Mint events raised by the ConstantProductPair contract.
+type ConstantProductPairMintIterator struct {
+	Event *ConstantProductPairMint // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ConstantProductPairMintIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ConstantProductPairMint)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ConstantProductPairMint)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ConstantProductPairMintIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ConstantProductPairMintIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ConstantProductPairMint represents a Mint event raised by the ConstantProductPair contract.
+type ConstantProductPairMint struct {
+	Provider  common.Address
+	AmountA   *big.Int
+	AmountB   *big.Int
+	Liquidity *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterMint is a free log retrieval operation binding the contract event 0xb4c03061fb5b7fed76389d5af8f2e0ddb09f8c70d1333abbb62582835e10accb.
+//
+// Solidity: event Mint(address indexed provider, uint256 amountA, uint256 amountB, uint256 liquidity)
+func (_ConstantProductPair *ConstantProductPairFilterer) FilterMint(opts *bind.FilterOpts, provider []common.Address) (*ConstantProductPairMintIterator, error) {
+
+	var providerRule []interface{}
+	for _, providerItem := range provider {
+		providerRule = append(providerRule, providerItem)
+	}
+
+	logs, sub, err := _ConstantProductPair.contract.FilterLogs(opts, "Mint", providerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ConstantProductPairMintIterator{contract: _ConstantProductPair.contract, event: "Mint", logs: logs, sub: sub}, nil
+}
+
+// WatchMint is a free log subscription operation binding the contract event 0xb4c03061fb5b7fed76389d5af8f2e0ddb09f8c70d1333abbb62582835e10accb.
+//
+// Solidity: event Mint(address indexed provider, uint256 amountA, uint256 amountB, uint256 liquidity)
+func (_ConstantProductPair *ConstantProductPairFilterer) WatchMint(opts *bind.WatchOpts, sink chan<- *ConstantProductPairMint, provider []common.Address) (event.Subscription, error) {
+
+	var providerRule []interface{}
+	for _, providerItem := range provider {
+		providerRule = append(providerRule, providerItem)
+	}
+
+	logs, sub, err := _ConstantProductPair.contract.WatchLogs(opts, "Mint", providerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ConstantProductPairMint)
+				if err := _ConstantProductPair.contract.UnpackLog(event, "Mint", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseMint is a log parse operation binding the contract event 0xb4c03061fb5b7fed76389d5af8f2e0ddb09f8c70d1333abbb62582835e10accb.
+//
+// Solidity: event Mint(address indexed provider, uint256 amountA, uint256 amountB, uint256 liquidity)
+func (_ConstantProductPair *ConstantProductPairFilterer) ParseMint(log types.Log) (*ConstantProductPairMint, error) {
+	event := new(ConstantProductPairMint)
+	if err := _ConstantProductPair.contract.UnpackLog(event, "Mint", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// ConstantProductPairSwapIterator is returned from FilterSwap and is used to iterate over the raw logs and unpacked data for Swap events raised by the ConstantProductPair contract.
+type ConstantProductPairSwapIterator struct {
+	Event *ConstantProductPairSwap // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ConstantProductPairSwapIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ConstantProductPairSwap)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ConstantProductPairSwap)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ConstantProductPairSwapIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ConstantProductPairSwapIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ConstantProductPairSwap represents a Swap event raised by the ConstantProductPair contract.
+type ConstantProductPairSwap struct {
+	Trader    common.Address
+	Direction uint8
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	ReserveA  *big.Int
+	ReserveB  *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterSwap is a free log retrieval operation binding the contract event 0xec9a01251436957128e2836cfc2674cdcef8618e093adaa9f9a3af79b9c4f4be.
+//
+// Solidity: event Swap(address indexed trader, uint8 direction, uint256 amountIn, uint256 amountOut, uint256 reserveA, uint256 reserveB)
+func (_ConstantProductPair *ConstantProductPairFilterer) FilterSwap(opts *bind.FilterOpts, trader []common.Address) (*ConstantProductPairSwapIterator, error) {
+
+	var traderRule []interface{}
+	for _, traderItem := range trader {
+		traderRule = append(traderRule, traderItem)
+	}
+
+	logs, sub, err := _ConstantProductPair.contract.FilterLogs(opts, "Swap", traderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ConstantProductPairSwapIterator{contract: _ConstantProductPair.contract, event: "Swap", logs: logs, sub: sub}, nil
+}
+
+// WatchSwap is a free log subscription operation binding the contract event 0xec9a01251436957128e2836cfc2674cdcef8618e093adaa9f9a3af79b9c4f4be.
+//
+// Solidity: event Swap(address indexed trader, uint8 direction, uint256 amountIn, uint256 amountOut, uint256 reserveA, uint256 reserveB)
+func (_ConstantProductPair *ConstantProductPairFilterer) WatchSwap(opts *bind.WatchOpts, sink chan<- *ConstantProductPairSwap, trader []common.Address) (event.Subscription, error) {
+
+	var traderRule []interface{}
+	for _, traderItem := range trader {
+		traderRule = append(traderRule, traderItem)
+	}
+
+	logs, sub, err := _ConstantProductPair.contract.WatchLogs(opts, "Swap", traderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ConstantProductPairSwap)
+				if err := _ConstantProductPair.contract.UnpackLog(event, "Swap", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseSwap is a log parse operation binding the contract event 0xec9a01251436957128e2836cfc2674cdcef8618e093adaa9f9a3af79b9c4f4be.
+//
+// Solidity: event Swap(address indexed trader, uint8 direction, uint256 amountIn, uint256 amountOut, uint256 reserveA, uint256 reserveB)
+func (_ConstantProductPair *ConstantProductPairFilterer) ParseSwap(log types.Log) (*ConstantProductPairSwap, error) {
+	event := new(ConstantProductPairSwap)
+	if err := _ConstantProductPair.contract.UnpackLog(event, "Swap", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
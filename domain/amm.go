@@ -1,99 +1,382 @@
 package domain
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
+	"time"
+)
+
+// AMM is the behaviour shared by every pool design (constant product,
+// concentrated liquidity, stableswap), so they can be simulated side by side
+type AMM interface {
+	// Tokens returns the symbols of TokenA and TokenB
+	Tokens() (tokenA, tokenB string)
+
+	// Quote returns what selling amountIn would give, without swapping
+	Quote(direction Direction, amountIn *big.Int) (*big.Int, error)
+
+	// QuoteExactOut returns what buying amountOut would cost, without swapping
+	QuoteExactOut(direction Direction, amountOut *big.Int) (*big.Int, error)
+
+	// Swap sells amountIn in the given direction on behalf of a trader
+	// (reported in swap events) and returns the amount received
+	Swap(trader int, direction Direction, amountIn *big.Int) (*big.Int, error)
+
+	// SwapExactOut buys exactly amountOut and returns the amount paid
+	SwapExactOut(trader int, direction Direction, amountOut *big.Int) (*big.Int, error)
+
+	// PriceAInB and PriceBInA return the spot prices
+	PriceAInB() float64
+	PriceBInA() float64
+
+	// Snapshot returns a consistent copy of the pool state
+	Snapshot() PoolSnapshot
+
+	// Subscribe streams the swap events of the pool
+	Subscribe(buffer int) *Subscription
+}
+
+// ContextSwapper is implemented by pools whose swaps wait on I/O, such as an
+// on-chain pair: SwapContext is Swap, bounded and cancelled by ctx
+type ContextSwapper interface {
+	SwapContext(ctx context.Context, trader int, direction Direction, amountIn *big.Int) (*big.Int, error)
+}
+
+var (
+	// ErrInsufficientLiquidity is returned when a pool cannot fill a swap
+	// or a position does not hold the liquidity being removed
+	ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+
+	// ErrInvalidReserves is returned for pool reserves that are missing, below
+	// MinimumLiquidity or above MaxReserve
+	ErrInvalidReserves = errors.New("invalid reserves")
+)
+
+// MinimumLiquidity is the floor of each Pool reserve in raw units: pools
+// start with at least this much and a swap that would leave its output
+// reserve below it is refused, so prices stay finite and the pool can never
+// be drained. Pool has no LP shares, so unlike the MINIMUM_LIQUIDITY of
+// Uniswap v2 nothing is burned at creation; the floor applies to every swap
+const MinimumLiquidity = 1000
+
+// MaxReserve bounds each Pool reserve to 2^112 - 1, the uint112 reserves of
+// Uniswap v2 and the range of the UQ112x112 oracle prices
+var MaxReserve = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 112), big.NewInt(1))
+
+var (
+	_ AMM = (*Pool)(nil)
+	_ AMM = (*ConcentratedPool)(nil)
+	_ AMM = (*StableSwapPool)(nil)
 )
 
 // Pool represents an AMM liquidity pool with two tokens
 // Uses constant product formula: x * y = k
 // Thread-safe for concurrent access (RWMutex for read/write optimization)
+// Read reserves through Snapshot or Reserves: the exported fields are not
+// safe to read while other goroutines swap
 type Pool struct {
 	mu       sync.RWMutex
 	TokenA   string
 	TokenB   string
 	ReserveA *big.Int
 	ReserveB *big.Int
+
+	// token decimals, 0 (raw units) unless SetDecimals is called
+	decimalsA uint8
+	decimalsB uint8
+
+	eventHub
+
+	// TWAP oracle, see oracle.go
+	now              func() time.Time
+	priceACumulative *big.Int
+	priceBCumulative *big.Int
+	lastUpdate       time.Time
+	observations     []Observation // ring buffer
+	obsNext          int
+	obsCardinality   int
+}
+
+// PoolSnapshot is a consistent copy of the pool state
+type PoolSnapshot struct {
+	TokenA    string   `json:"token_a"`
+	TokenB    string   `json:"token_b"`
+	ReserveA  *big.Int `json:"reserve_a"`
+	ReserveB  *big.Int `json:"reserve_b"`
+	K         *big.Int `json:"k"`
+	PriceAInB float64  `json:"price_a_in_b"`
+	PriceBInA float64  `json:"price_b_in_a"`
+	DecimalsA uint8    `json:"decimals_a"`
+	DecimalsB uint8    `json:"decimals_b"`
+}
+
+// TokenInfo returns the symbol and decimals of TokenA and TokenB
+func (s PoolSnapshot) TokenInfo() (tokenA, tokenB Token) {
+	return Token{Symbol: s.TokenA, Decimals: s.DecimalsA}, Token{Symbol: s.TokenB, Decimals: s.DecimalsB}
+}
+
+// ReserveAmounts returns the reserves with their token metadata
+func (s PoolSnapshot) ReserveAmounts() (reserveA, reserveB TokenAmount) {
+	tokenA, tokenB := s.TokenInfo()
+	return tokenA.Amount(s.ReserveA), tokenB.Amount(s.ReserveB)
+}
+
+// UnitPriceAInB returns the price of one whole TokenA in whole TokenB
+// PriceAInB is a ratio of raw units, which differs when the decimals differ
+// (e.g. ETH with 18 and USDC with 6)
+func (s PoolSnapshot) UnitPriceAInB() float64 {
+	reserveA, reserveB := s.ReserveAmounts()
+	return reserveB.Float64() / reserveA.Float64()
 }
 
 // NewPool creates a new liquidity pool
-func NewPool(tokenA, tokenB string, reserveA, reserveB *big.Int) *Pool {
-	return &Pool{
+// Both reserves must be between MinimumLiquidity and MaxReserve
+func NewPool(tokenA, tokenB string, reserveA, reserveB *big.Int) (*Pool, error) {
+	if err := validateReserve(tokenA, reserveA); err != nil {
+		return nil, err
+	}
+	if err := validateReserve(tokenB, reserveB); err != nil {
+		return nil, err
+	}
+
+	p := &Pool{
 		TokenA:   tokenA,
 		TokenB:   tokenB,
 		ReserveA: new(big.Int).Set(reserveA),
 		ReserveB: new(big.Int).Set(reserveB),
+		now:      time.Now,
+	}
+	p.initOracle(p.now())
+	return p, nil
+}
+
+// MustNewPool is NewPool for reserves known to be valid, e.g. in tests
+// Panics on invalid reserves
+func MustNewPool(tokenA, tokenB string, reserveA, reserveB *big.Int) *Pool {
+	p, err := NewPool(tokenA, tokenB, reserveA, reserveB)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// validateReserve checks an initial reserve is within the pool bounds
+func validateReserve(token string, reserve *big.Int) error {
+	if reserve == nil {
+		return fmt.Errorf("%w: no %s reserve", ErrInvalidReserves, token)
+	}
+	if reserve.Cmp(big.NewInt(MinimumLiquidity)) < 0 {
+		return fmt.Errorf("%w: %s reserve %s is below the minimum liquidity %d",
+			ErrInvalidReserves, token, reserve, MinimumLiquidity)
+	}
+	if reserve.Cmp(MaxReserve) > 0 {
+		return fmt.Errorf("%w: %s reserve %s exceeds 2^112-1", ErrInvalidReserves, token, reserve)
+	}
+	return nil
+}
+
+// Swap executes a swap on behalf of a trader (e.g. a bot ID) and returns the
+// amount received; the trader is reported in the SwapEvent
+func (p *Pool) Swap(trader int, direction Direction, amountIn *big.Int) (*big.Int, error) {
+	if direction == AToB {
+		return p.swapAForB(trader, amountIn)
 	}
+	return p.swapBForA(trader, amountIn)
 }
 
 // SwapAForB swaps amountIn of TokenA for TokenB
 // Returns the amount of TokenB received
-// Formula: dy = (y * dx) / (x + dx)
 func (p *Pool) SwapAForB(amountIn *big.Int) (*big.Int, error) {
+	return p.swapAForB(0, amountIn)
+}
+
+// SwapBForA swaps amountIn of TokenB for TokenA
+// Returns the amount of TokenA received
+func (p *Pool) SwapBForA(amountIn *big.Int) (*big.Int, error) {
+	return p.swapBForA(0, amountIn)
+}
+
+// swapAForB sells TokenA
+// Formula: dy = (y * dx) / (x + dx)
+func (p *Pool) swapAForB(trader int, amountIn *big.Int) (*big.Int, error) {
+	_, out, err := p.swap(trader, AToB, amountIn, true)
+	return out, err
+}
+
+// swapBForA sells TokenB
+// Formula: dx = (x * dy) / (y + dy)
+func (p *Pool) swapBForA(trader int, amountIn *big.Int) (*big.Int, error) {
+	_, out, err := p.swap(trader, BToA, amountIn, true)
+	return out, err
+}
+
+// SwapExactOut buys exactly amountOut in the given direction on behalf of a
+// trader and returns the amount paid
+func (p *Pool) SwapExactOut(trader int, direction Direction, amountOut *big.Int) (*big.Int, error) {
+	in, _, err := p.swap(trader, direction, amountOut, false)
+	return in, err
+}
+
+// Quote returns what selling amountIn would give, without swapping
+func (p *Pool) Quote(direction Direction, amountIn *big.Int) (*big.Int, error) {
 	if err := p.validateAmount(amountIn); err != nil {
 		return nil, err
 	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, out, err := p.amounts(direction, amountIn, true)
+	return out, err
+}
+
+// QuoteExactOut returns what buying amountOut would cost, without swapping
+func (p *Pool) QuoteExactOut(direction Direction, amountOut *big.Int) (*big.Int, error) {
+	if err := p.validateAmount(amountOut); err != nil {
+		return nil, err
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	in, _, err := p.amounts(direction, amountOut, false)
+	return in, err
+}
+
+// swap executes an exact-in (amount is the input) or exact-out swap
+func (p *Pool) swap(trader int, direction Direction, amount *big.Int, exactIn bool) (in, out *big.Int, err error) {
+	if err := p.validateAmount(amount); err != nil {
+		return nil, nil, err
+	}
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// dy = (y * dx) / (x + dx)
-	numerator := new(big.Int).Mul(p.ReserveB, amountIn)
-	denominator := new(big.Int).Add(p.ReserveA, amountIn)
-	amountOut := new(big.Int).Div(numerator, denominator)
+	in, out, err = p.amounts(direction, amount, exactIn)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// update reserves
-	p.ReserveA.Add(p.ReserveA, amountIn)
-	p.ReserveB.Sub(p.ReserveB, amountOut)
+	// accumulate the prices that held until now, then update reserves
+	now := p.now()
+	p.updateOracle(now)
+	reserveIn, reserveOut := p.ReserveA, p.ReserveB
+	if direction == BToA {
+		reserveIn, reserveOut = p.ReserveB, p.ReserveA
+	}
+	reserveIn.Add(reserveIn, in)
+	reserveOut.Sub(reserveOut, out)
 
-	return amountOut, nil
+	p.publishSwap(trader, direction, in, out, now)
+	return in, out, nil
 }
 
-// SwapBForA swaps amountIn of TokenB for TokenA
-// Returns the amount of TokenA received
-// Formula: dx = (x * dy) / (y + dy)
-func (p *Pool) SwapBForA(amountIn *big.Int) (*big.Int, error) {
-	if err := p.validateAmount(amountIn); err != nil {
-		return nil, err
+// amounts returns both sides of a swap, called with p.mu held
+// Exact in:  out = (reserveOut * in) / (reserveIn + in), rounded down
+// Exact out: in = (reserveIn * out) / (reserveOut - out), rounded up
+// so k never decreases. Swaps leaving reserveOut below MinimumLiquidity or
+// reserveIn above MaxReserve are refused
+func (p *Pool) amounts(direction Direction, amount *big.Int, exactIn bool) (in, out *big.Int, err error) {
+	reserveIn, reserveOut := p.ReserveA, p.ReserveB
+	if direction == BToA {
+		reserveIn, reserveOut = p.ReserveB, p.ReserveA
 	}
 
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	if exactIn {
+		numerator := new(big.Int).Mul(reserveOut, amount)
+		denominator := new(big.Int).Add(reserveIn, amount)
+		in, out = new(big.Int).Set(amount), numerator.Div(numerator, denominator)
+	} else {
+		if amount.Cmp(reserveOut) >= 0 {
+			return nil, nil, fmt.Errorf("%w: cannot buy %s of %s reserve", ErrInsufficientLiquidity, amount, reserveOut)
+		}
+		numerator := new(big.Int).Mul(reserveIn, amount)
+		denominator := new(big.Int).Sub(reserveOut, amount)
+		in, out = divUp(numerator, denominator), new(big.Int).Set(amount)
+	}
 
-	// dx = (x * dy) / (y + dy)
-	numerator := new(big.Int).Mul(p.ReserveA, amountIn)
-	denominator := new(big.Int).Add(p.ReserveB, amountIn)
-	amountOut := new(big.Int).Div(numerator, denominator)
+	if left := new(big.Int).Sub(reserveOut, out); left.Cmp(big.NewInt(MinimumLiquidity)) < 0 {
+		return nil, nil, fmt.Errorf("%w: swap would leave %s of %s reserve, below the minimum liquidity %d",
+			ErrInsufficientLiquidity, left, reserveOut, MinimumLiquidity)
+	}
+	if new(big.Int).Add(reserveIn, in).Cmp(MaxReserve) > 0 {
+		return nil, nil, fmt.Errorf("%w: swap of %s would push the %s reserve past 2^112-1",
+			ErrInvalidReserves, in, reserveIn)
+	}
+	return in, out, nil
+}
+
+// Tokens returns the symbols of TokenA and TokenB
+func (p *Pool) Tokens() (tokenA, tokenB string) {
+	return p.TokenA, p.TokenB
+}
 
-	// update reserves
-	p.ReserveB.Add(p.ReserveB, amountIn)
-	p.ReserveA.Sub(p.ReserveA, amountOut)
+// Snapshot returns reserves, k and prices read under a single lock
+func (p *Pool) Snapshot() PoolSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
-	return amountOut, nil
+	return PoolSnapshot{
+		TokenA:    p.TokenA,
+		TokenB:    p.TokenB,
+		ReserveA:  new(big.Int).Set(p.ReserveA),
+		ReserveB:  new(big.Int).Set(p.ReserveB),
+		K:         new(big.Int).Mul(p.ReserveA, p.ReserveB),
+		PriceAInB: ratio(p.ReserveB, p.ReserveA),
+		PriceBInA: ratio(p.ReserveA, p.ReserveB),
+		DecimalsA: p.decimalsA,
+		DecimalsB: p.decimalsB,
+	}
+}
+
+// SetDecimals sets the decimals of TokenA and TokenB, reported in snapshots
+// to display amounts. Swaps always work on raw units
+// Must be called before the pool is shared
+func (p *Pool) SetDecimals(decimalsA, decimalsB uint8) {
+	p.decimalsA, p.decimalsB = decimalsA, decimalsB
+}
+
+// Reserves returns copies of both reserves, read under the pool lock
+func (p *Pool) Reserves() (reserveA, reserveB *big.Int) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return new(big.Int).Set(p.ReserveA), new(big.Int).Set(p.ReserveB)
 }
 
 // PriceAInB returns the price of TokenA in terms of TokenB
 func (p *Pool) PriceAInB() float64 {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-
-	a := new(big.Float).SetInt(p.ReserveA)
-	b := new(big.Float).SetInt(p.ReserveB)
-	price := new(big.Float).Quo(b, a)
-	result, _ := price.Float64()
-	return result
+	return ratio(p.ReserveB, p.ReserveA)
 }
 
 // PriceBInA returns the price of TokenB in terms of TokenA
 func (p *Pool) PriceBInA() float64 {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
+	return ratio(p.ReserveA, p.ReserveB)
+}
+
+// publishSwap notifies subscribers, called with p.mu held
+func (p *Pool) publishSwap(trader int, direction Direction, amountIn, amountOut *big.Int, now time.Time) {
+	p.publish(func() SwapEvent {
+		return SwapEvent{
+			Trader:    trader,
+			Direction: direction,
+			AmountIn:  new(big.Int).Set(amountIn),
+			AmountOut: new(big.Int).Set(amountOut),
+			ReserveA:  new(big.Int).Set(p.ReserveA),
+			ReserveB:  new(big.Int).Set(p.ReserveB),
+			PriceAInB: ratio(p.ReserveB, p.ReserveA),
+			Time:      now,
+		}
+	})
+}
 
-	a := new(big.Float).SetInt(p.ReserveA)
-	b := new(big.Float).SetInt(p.ReserveB)
-	price := new(big.Float).Quo(a, b)
-	result, _ := price.Float64()
+// ratio returns x / y as a float
+func ratio(x, y *big.Int) float64 {
+	a := new(big.Float).SetInt(x)
+	b := new(big.Float).SetInt(y)
+	result, _ := new(big.Float).Quo(a, b).Float64()
 	return result
 }
 
@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"encoding/binary"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// poolOp is one swap decoded from fuzz input
+type poolOp struct {
+	direction Direction
+	exactIn   bool
+	amount    *big.Int
+}
+
+// decodeOps turns fuzz bytes into swaps, 9 bytes each: a flags byte and a
+// uint64 amount. The high bits of the flags shift the amount left by up to 63
+// bits so swaps far larger than the reserves are covered
+func decodeOps(data []byte) []poolOp {
+	var ops []poolOp
+	for ; len(data) >= 9; data = data[9:] {
+		flags := data[0]
+		amount := new(big.Int).SetUint64(binary.BigEndian.Uint64(data[1:9]))
+		ops = append(ops, poolOp{
+			direction: Direction(flags & 1),
+			exactIn:   flags&2 == 0,
+			amount:    amount.Lsh(amount, uint(flags>>2)),
+		})
+	}
+	return ops
+}
+
+// apply runs op on the pool and returns both sides of the swap
+// ok is false when the pool rejected it
+func (op poolOp) apply(pool *Pool, trader int) (in, out *big.Int, ok bool) {
+	var err error
+	if op.exactIn {
+		in = op.amount
+		out, err = pool.Swap(trader, op.direction, op.amount)
+	} else {
+		out = op.amount
+		in, err = pool.SwapExactOut(trader, op.direction, op.amount)
+	}
+	return in, out, err == nil
+}
+
+// checkStep asserts the invariants between two pool states around one swap
+func checkStep(t *testing.T, op poolOp, beforeA, beforeB, in, out, afterA, afterB *big.Int) {
+	t.Helper()
+	reserveIn, reserveOut := beforeA, beforeB
+	if op.direction == BToA {
+		reserveIn, reserveOut = beforeB, beforeA
+	}
+
+	if out.Cmp(reserveOut) >= 0 {
+		t.Fatalf("%s swap of %s paid %s out of a %s reserve", op.direction, op.amount, out, reserveOut)
+	}
+	if in.Sign() <= 0 || out.Sign() < 0 {
+		t.Fatalf("%s swap of %s took %s and paid %s", op.direction, op.amount, in, out)
+	}
+	minimum := big.NewInt(MinimumLiquidity)
+	if afterA.Cmp(minimum) < 0 || afterB.Cmp(minimum) < 0 || afterA.Cmp(MaxReserve) > 0 || afterB.Cmp(MaxReserve) > 0 {
+		t.Fatalf("%s swap of %s left reserves %s/%s", op.direction, op.amount, afterA, afterB)
+	}
+
+	wantIn := new(big.Int).Add(reserveIn, in)
+	wantOut := new(big.Int).Sub(reserveOut, out)
+	gotIn, gotOut := afterA, afterB
+	if op.direction == BToA {
+		gotIn, gotOut = afterB, afterA
+	}
+	if gotIn.Cmp(wantIn) != 0 || gotOut.Cmp(wantOut) != 0 {
+		t.Fatalf("%s swap of %s: reserves %s/%s do not match the amounts %s in, %s out",
+			op.direction, op.amount, afterA, afterB, in, out)
+	}
+
+	kBefore := new(big.Int).Mul(beforeA, beforeB)
+	kAfter := new(big.Int).Mul(afterA, afterB)
+	if kAfter.Cmp(kBefore) < 0 {
+		t.Fatalf("%s swap of %s decreased k from %s to %s", op.direction, op.amount, kBefore, kAfter)
+	}
+}
+
+func addPoolSeeds(f *testing.F) {
+	f.Add(uint64(1000), uint64(2000), []byte{0, 0, 0, 0, 0, 0, 0, 0, 100, 3, 0, 0, 0, 0, 0, 0, 0, 50})
+	f.Add(uint64(1000), uint64(1000), []byte{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0, 1})
+	// huge swaps against a deep pool
+	f.Add(uint64(1e15), uint64(3e18), []byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 254, 0, 0, 0, 0, 0, 0, 0, 1})
+	// exact-out swaps buying most of the reserve
+	f.Add(uint64(500), uint64(500), []byte{2, 0, 0, 0, 0, 0, 0, 1, 243, 3, 0, 0, 0, 0, 0, 0, 1, 243})
+}
+
+// FuzzPool_Sequence runs swap sequences and checks k never decreases,
+// reserves stay within MinimumLiquidity and MaxReserve and no swap pays out
+// its whole reserve
+func FuzzPool_Sequence(f *testing.F) {
+	addPoolSeeds(f)
+	f.Fuzz(func(t *testing.T, reserveA, reserveB uint64, data []byte) {
+		pool, err := NewPool("A", "B", new(big.Int).SetUint64(reserveA), new(big.Int).SetUint64(reserveB))
+		if err != nil {
+			t.Skip()
+		}
+
+		for _, op := range decodeOps(data) {
+			beforeA, beforeB := pool.Reserves()
+			in, out, ok := op.apply(pool, 1)
+			afterA, afterB := pool.Reserves()
+			if !ok {
+				if afterA.Cmp(beforeA) != 0 || afterB.Cmp(beforeB) != 0 {
+					t.Fatalf("rejected %s swap of %s changed reserves", op.direction, op.amount)
+				}
+				continue
+			}
+			checkStep(t, op, beforeA, beforeB, in, out, afterA, afterB)
+		}
+	})
+}
+
+// FuzzPool_Concurrent splits a sequence across goroutines and checks the
+// invariants on the swap events, which are published in execution order
+func FuzzPool_Concurrent(f *testing.F) {
+	addPoolSeeds(f)
+	f.Fuzz(func(t *testing.T, reserveA, reserveB uint64, data []byte) {
+		pool, err := NewPool("A", "B", new(big.Int).SetUint64(reserveA), new(big.Int).SetUint64(reserveB))
+		if err != nil {
+			t.Skip()
+		}
+		ops := decodeOps(data)
+		sub := pool.Subscribe(len(ops))
+
+		const workers = 4
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < len(ops); i += workers {
+					ops[i].apply(pool, w+1)
+				}
+			}(w)
+		}
+		wg.Wait()
+		sub.Close()
+		if sub.Dropped() != 0 {
+			t.Fatalf("dropped %d events", sub.Dropped())
+		}
+
+		prevA, prevB := new(big.Int).SetUint64(reserveA), new(big.Int).SetUint64(reserveB)
+		for event := range sub.Events() {
+			op := poolOp{direction: event.Direction}
+			checkStep(t, op, prevA, prevB, event.AmountIn, event.AmountOut, event.ReserveA, event.ReserveB)
+			prevA, prevB = event.ReserveA, event.ReserveB
+		}
+		finalA, finalB := pool.Reserves()
+		if finalA.Cmp(prevA) != 0 || finalB.Cmp(prevB) != 0 {
+			t.Fatalf("final reserves %s/%s, last event %s/%s", finalA, finalB, prevA, prevB)
+		}
+	})
+}
+
+// FuzzPool_RoundTrip checks that swapping there and back never profits, for
+// exact-in and exact-out legs, and that quotes match the swaps
+func FuzzPool_RoundTrip(f *testing.F) {
+	f.Add(uint64(1000), uint64(2000), uint64(100), false)
+	f.Add(uint64(1000), uint64(1e18), uint64(1), true)
+	f.Add(uint64(1e18), uint64(7000), uint64(1e17), false)
+	f.Fuzz(func(t *testing.T, reserveA, reserveB, amount uint64, buyB bool) {
+		pool, err := NewPool("A", "B", new(big.Int).SetUint64(reserveA), new(big.Int).SetUint64(reserveB))
+		if err != nil || amount == 0 {
+			t.Skip()
+		}
+		x := new(big.Int).SetUint64(amount)
+
+		// first leg: buy x of B, or sell x of A
+		spent, received := x, (*big.Int)(nil)
+		if buyB {
+			quote, err := pool.QuoteExactOut(AToB, x)
+			if err != nil {
+				t.Skip()
+			}
+			if spent, err = pool.SwapExactOut(1, AToB, x); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if spent.Cmp(quote) != 0 {
+				t.Fatalf("quoted %s, paid %s", quote, spent)
+			}
+			received = x
+		} else {
+			quote, err := pool.Quote(AToB, x)
+			if err != nil {
+				t.Skip()
+			}
+			if received, err = pool.Swap(1, AToB, x); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if received.Cmp(quote) != 0 {
+				t.Fatalf("quoted %s, received %s", quote, received)
+			}
+		}
+		if received.Sign() == 0 {
+			return
+		}
+
+		// second leg: sell all the B received
+		back, err := pool.Swap(1, BToA, received)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if back.Cmp(spent) > 0 {
+			t.Fatalf("round trip of %s A returned %s A", spent, back)
+		}
+	})
+}
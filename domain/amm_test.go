@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"math/big"
 	"sync"
 	"testing"
@@ -10,7 +11,7 @@ func TestNewPool(t *testing.T) {
 	reserveA := big.NewInt(1000)
 	reserveB := big.NewInt(2000)
 
-	pool := NewPool("ETH", "USDC", reserveA, reserveB)
+	pool := MustNewPool("ETH", "USDC", reserveA, reserveB)
 
 	if pool.TokenA != "ETH" {
 		t.Errorf("expected TokenA=ETH, got %s", pool.TokenA)
@@ -30,7 +31,7 @@ func TestPool_SwapAForB(t *testing.T) {
 	// pool with 1000 ETH and 2000 USDC
 	reserveA := big.NewInt(1000)
 	reserveB := big.NewInt(2000)
-	pool := NewPool("ETH", "USDC", reserveA, reserveB)
+	pool := MustNewPool("ETH", "USDC", reserveA, reserveB)
 
 	// swap 100 ETH for USDC
 	amountIn := big.NewInt(100)
@@ -60,29 +61,90 @@ func TestPool_SwapAForB(t *testing.T) {
 	}
 }
 
+func TestNewPool_InvalidReserves(t *testing.T) {
+	tooLarge := new(big.Int).Add(MaxReserve, big.NewInt(1))
+	for name, reserves := range map[string][2]*big.Int{
+		"nil":           {nil, big.NewInt(2000)},
+		"zero":          {big.NewInt(1000), big.NewInt(0)},
+		"negative":      {big.NewInt(-1000), big.NewInt(2000)},
+		"below minimum": {big.NewInt(MinimumLiquidity - 1), big.NewInt(2000)},
+		"above maximum": {big.NewInt(1000), tooLarge},
+	} {
+		if _, err := NewPool("ETH", "USDC", reserves[0], reserves[1]); !errors.Is(err, ErrInvalidReserves) {
+			t.Errorf("%s: expected ErrInvalidReserves, got %v", name, err)
+		}
+	}
+
+	if _, err := NewPool("ETH", "USDC", big.NewInt(MinimumLiquidity), MaxReserve); err != nil {
+		t.Errorf("unexpected error at the bounds: %v", err)
+	}
+}
+
+func TestPool_MinimumLiquidity(t *testing.T) {
+	pool := MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+
+	// selling 2000 ETH would take 1333 of the 2000 USDC
+	if _, err := pool.SwapAForB(big.NewInt(2000)); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("expected ErrInsufficientLiquidity, got %v", err)
+	}
+	huge := new(big.Int).Lsh(big.NewInt(1), 100)
+	if _, err := pool.SwapAForB(huge); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("expected ErrInsufficientLiquidity for a huge swap, got %v", err)
+	}
+	if _, err := pool.SwapExactOut(1, AToB, big.NewInt(1001)); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("expected ErrInsufficientLiquidity buying into the locked liquidity, got %v", err)
+	}
+	if _, err := pool.Quote(AToB, big.NewInt(2000)); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("expected quotes to refuse the swap too, got %v", err)
+	}
+	reserveA, reserveB := pool.Reserves()
+	if reserveA.Int64() != 1000 || reserveB.Int64() != 2000 {
+		t.Fatalf("refused swaps changed the reserves to %s/%s", reserveA, reserveB)
+	}
+
+	// buying down to exactly the minimum is allowed
+	if _, err := pool.SwapExactOut(1, AToB, big.NewInt(1000)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, reserveB := pool.Reserves(); reserveB.Int64() != MinimumLiquidity {
+		t.Errorf("expected ReserveB=%d, got %s", MinimumLiquidity, reserveB)
+	}
+}
+
+func TestPool_MaxReserve(t *testing.T) {
+	pool := MustNewPool("ETH", "USDC", new(big.Int).Sub(MaxReserve, big.NewInt(10)), MaxReserve)
+
+	if _, err := pool.SwapAForB(big.NewInt(11)); !errors.Is(err, ErrInvalidReserves) {
+		t.Errorf("expected ErrInvalidReserves pushing a reserve past the maximum, got %v", err)
+	}
+	if _, err := pool.SwapAForB(big.NewInt(10)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
 func TestPool_SwapBForA(t *testing.T) {
-	// pool with 1000 ETH and 2000 USDC
-	reserveA := big.NewInt(1000)
-	reserveB := big.NewInt(2000)
-	pool := NewPool("ETH", "USDC", reserveA, reserveB)
+	// pool with 10000 ETH and 20000 USDC
+	reserveA := big.NewInt(10000)
+	reserveB := big.NewInt(20000)
+	pool := MustNewPool("ETH", "USDC", reserveA, reserveB)
 
-	// swap 200 USDC for ETH
-	amountIn := big.NewInt(200)
+	// swap 2000 USDC for ETH
+	amountIn := big.NewInt(2000)
 	amountOut, err := pool.SwapBForA(amountIn)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
 	// formula: dx = (x * dy) / (y + dy)
-	// dx = (1000 * 200) / (2000 + 200) = 200000 / 2200 = 90
-	expected := big.NewInt(90)
+	// dx = (10000 * 2000) / (20000 + 2000) = 20000000 / 22000 = 909
+	expected := big.NewInt(909)
 	if amountOut.Cmp(expected) != 0 {
 		t.Errorf("expected amountOut=%s, got %s", expected.String(), amountOut.String())
 	}
 }
 
 func TestPool_SwapZeroAmount(t *testing.T) {
-	pool := NewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	pool := MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
 
 	_, err := pool.SwapAForB(big.NewInt(0))
 	if err == nil {
@@ -91,7 +153,7 @@ func TestPool_SwapZeroAmount(t *testing.T) {
 }
 
 func TestPool_SwapNegativeAmount(t *testing.T) {
-	pool := NewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	pool := MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
 
 	_, err := pool.SwapAForB(big.NewInt(-100))
 	if err == nil {
@@ -102,7 +164,7 @@ func TestPool_SwapNegativeAmount(t *testing.T) {
 func TestPool_GetPrice(t *testing.T) {
 	// pool with 1000 ETH and 2000 USDC
 	// price of A in terms of B = reserveB / reserveA = 2000/1000 = 2
-	pool := NewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	pool := MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
 
 	priceAInB := pool.PriceAInB()
 	// 2000 / 1000 = 2
@@ -117,9 +179,24 @@ func TestPool_GetPrice(t *testing.T) {
 	}
 }
 
+func TestPool_Reserves(t *testing.T) {
+	pool := MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+
+	a, b := pool.Reserves()
+	if a.Int64() != 1000 || b.Int64() != 2000 {
+		t.Errorf("expected reserves 1000/2000, got %s/%s", a.String(), b.String())
+	}
+
+	// returned values are copies
+	a.SetInt64(0)
+	if pool.ReserveA.Int64() != 1000 {
+		t.Error("modifying returned reserve should not affect the pool")
+	}
+}
+
 func TestPool_ConcurrentSwaps(t *testing.T) {
 	// large reserves to handle many swaps
-	pool := NewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	pool := MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
 
 	numGoroutines := 100
 	swapsPerGoroutine := 10
@@ -157,3 +234,172 @@ func TestPool_ConcurrentSwaps(t *testing.T) {
 		pool.ReserveA.String(),
 		pool.ReserveB.String())
 }
+
+func TestPool_Snapshot(t *testing.T) {
+	pool := MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	snap := pool.Snapshot()
+
+	if snap.ReserveA.Int64() != 1000 || snap.ReserveB.Int64() != 2000 || snap.K.Int64() != 2000000 {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+	if snap.PriceAInB != 2 || snap.PriceBInA != 0.5 {
+		t.Errorf("unexpected prices: %f, %f", snap.PriceAInB, snap.PriceBInA)
+	}
+
+	// the snapshot is a copy
+	snap.ReserveA.SetInt64(1)
+	if pool.Snapshot().ReserveA.Int64() != 1000 {
+		t.Error("modifying the snapshot changed the pool")
+	}
+}
+
+func TestPool_Subscribe(t *testing.T) {
+	pool := MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	sub := pool.Subscribe(10)
+
+	if _, err := pool.Swap(7, AToB, big.NewInt(100)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := pool.SwapBForA(big.NewInt(50)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sub.Close()
+
+	var events []SwapEvent
+	for e := range sub.Events() {
+		events = append(events, e)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	first := events[0]
+	if first.Trader != 7 || first.Direction != AToB || first.AmountIn.Int64() != 100 || first.AmountOut.Int64() != 181 {
+		t.Errorf("unexpected first event: %+v", first)
+	}
+	if first.ReserveA.Int64() != 1100 || first.ReserveB.Int64() != 1819 || first.Time.IsZero() {
+		t.Errorf("first event should carry the reserves after the swap: %+v", first)
+	}
+	if events[1].Trader != 0 || events[1].Direction != BToA {
+		t.Errorf("unexpected second event: %+v", events[1])
+	}
+
+	// no events after Close, and Close is idempotent
+	pool.SwapAForB(big.NewInt(10))
+	sub.Close()
+}
+
+func TestPool_Subscribe_DropsWhenFull(t *testing.T) {
+	pool := MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	sub := pool.Subscribe(2)
+	defer sub.Close()
+
+	for i := 0; i < 5; i++ {
+		pool.SwapAForB(big.NewInt(10))
+	}
+
+	if len(sub.Events()) != 2 || sub.Dropped() != 3 {
+		t.Errorf("expected 2 buffered and 3 dropped, got %d and %d", len(sub.Events()), sub.Dropped())
+	}
+}
+
+// testAMMs returns one pool of each design with comparable depth at price ~1
+func testAMMs(t *testing.T) map[string]AMM {
+	t.Helper()
+	concentrated, err := NewConcentratedPool("USDC", "USDT", 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := concentrated.Mint(PositionKey{Owner: 1, Lower: -1000, Upper: 1000}, big.NewInt(100000000000)); err != nil {
+		t.Fatalf("unexpected mint error: %v", err)
+	}
+	stable, err := NewStableSwapPool([]string{"USDC", "USDT"}, []*big.Int{big.NewInt(1000000000), big.NewInt(1000000000)}, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return map[string]AMM{
+		"constant product": MustNewPool("USDC", "USDT", big.NewInt(1000000000), big.NewInt(1000000000)),
+		"concentrated":     concentrated,
+		"stableswap":       stable,
+	}
+}
+
+func TestAMM_QuoteMatchesSwap(t *testing.T) {
+	for name, pool := range testAMMs(t) {
+		for _, dir := range []Direction{AToB, BToA} {
+			amount := big.NewInt(1000000)
+			quote, err := pool.Quote(dir, amount)
+			if err != nil {
+				t.Fatalf("%s: unexpected quote error: %v", name, err)
+			}
+			before := pool.Snapshot()
+			out, err := pool.Swap(1, dir, amount)
+			if err != nil {
+				t.Fatalf("%s: unexpected swap error: %v", name, err)
+			}
+			if out.Cmp(quote) != 0 {
+				t.Errorf("%s %s: quoted %s, swap gave %s", name, dir, quote, out)
+			}
+			if pool.Snapshot().K.Cmp(before.K) < 0 {
+				t.Errorf("%s %s: swap decreased the invariant", name, dir)
+			}
+		}
+	}
+}
+
+func TestAMM_SwapExactOut(t *testing.T) {
+	for name, pool := range testAMMs(t) {
+		for _, dir := range []Direction{AToB, BToA} {
+			want := big.NewInt(1000000)
+			quote, err := pool.QuoteExactOut(dir, want)
+			if err != nil {
+				t.Fatalf("%s: unexpected quote error: %v", name, err)
+			}
+
+			// selling the quoted input gives at least the output asked for
+			if out, _ := pool.Quote(dir, quote); out.Cmp(want) < 0 {
+				t.Errorf("%s %s: %s in only gives %s, want %s", name, dir, quote, out, want)
+			}
+
+			before := pool.Snapshot()
+			in, err := pool.SwapExactOut(1, dir, want)
+			if err != nil {
+				t.Fatalf("%s: unexpected swap error: %v", name, err)
+			}
+			after := pool.Snapshot()
+			if in.Cmp(quote) != 0 {
+				t.Errorf("%s %s: quoted %s in, swap took %s", name, dir, quote, in)
+			}
+
+			gotOut := new(big.Int).Sub(before.ReserveB, after.ReserveB)
+			paid := new(big.Int).Sub(after.ReserveA, before.ReserveA)
+			if dir == BToA {
+				gotOut.Sub(before.ReserveA, after.ReserveA)
+				paid.Sub(after.ReserveB, before.ReserveB)
+			}
+			if gotOut.Cmp(want) != 0 || paid.Cmp(in) != 0 {
+				t.Errorf("%s %s: reserves moved by %s in / %s out, want %s / %s", name, dir, paid, gotOut, in, want)
+			}
+			if after.K.Cmp(before.K) < 0 {
+				t.Errorf("%s %s: swap decreased the invariant", name, dir)
+			}
+		}
+	}
+}
+
+func TestAMM_SwapExactOut_Errors(t *testing.T) {
+	for name, pool := range testAMMs(t) {
+		if _, err := pool.SwapExactOut(1, AToB, big.NewInt(0)); err == nil {
+			t.Errorf("%s: expected error for a zero output", name)
+		}
+
+		before := pool.Snapshot()
+		reserve := before.ReserveB
+		if _, err := pool.SwapExactOut(1, AToB, reserve); !errors.Is(err, ErrInsufficientLiquidity) {
+			t.Errorf("%s: expected ErrInsufficientLiquidity buying the whole reserve, got %v", name, err)
+		}
+		if after := pool.Snapshot(); after.ReserveA.Cmp(before.ReserveA) != 0 || after.ReserveB.Cmp(before.ReserveB) != 0 {
+			t.Errorf("%s: a failed swap must not change the pool", name)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	// ErrInvalidAmount is returned for amounts that do not parse, or have
+	// more fractional digits than the token decimals
+	ErrInvalidAmount = errors.New("invalid amount")
+
+	// ErrTokenMismatch is returned when amounts of different tokens are
+	// combined or compared
+	ErrTokenMismatch = errors.New("token mismatch")
+)
+
+// MaxDecimals is the most decimals a token can have: 10^77 is the largest
+// power of ten that fits in a uint256
+const MaxDecimals = 77
+
+// Token is the metadata needed to read and display amounts of a token
+// Decimals is how many of the smallest units make one token, as a power of
+// ten (18 for ETH and most ERC20s, 6 for USDC). The zero value, no symbol
+// and no decimals, treats amounts as raw units
+// Name and Address are optional: tokens of the simulated pool have no contract
+type Token struct {
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+	Name     string `json:"name,omitempty"`
+	Address  string `json:"address,omitempty"`
+}
+
+// Unit returns the raw amount of one whole token, 10^Decimals
+func (t Token) Unit() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.Decimals)), nil)
+}
+
+// Amount wraps a raw amount of the token
+func (t Token) Amount(raw *big.Int) TokenAmount {
+	return NewTokenAmount(t, raw)
+}
+
+// TokenAmount is an amount of a token in its smallest unit, with the
+// metadata to parse and display it. Values are immutable: arithmetic
+// returns new amounts
+type TokenAmount struct {
+	Token Token
+	raw   *big.Int
+}
+
+// NewTokenAmount returns raw smallest units of token, nil meaning zero
+func NewTokenAmount(token Token, raw *big.Int) TokenAmount {
+	a := TokenAmount{Token: token, raw: new(big.Int)}
+	if raw != nil {
+		a.raw.Set(raw)
+	}
+	return a
+}
+
+// ParseTokenAmount reads a human amount such as "1.5" or "1.5 KEVZ"
+// A symbol, if present, must match the token (case-insensitive). The amount
+// may not have more fractional digits than the token decimals
+func ParseTokenAmount(s string, token Token) (TokenAmount, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return TokenAmount{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if len(fields) == 2 && !strings.EqualFold(fields[1], token.Symbol) {
+		return TokenAmount{}, fmt.Errorf("%w: %q is not an amount of %s", ErrTokenMismatch, s, token.Symbol)
+	}
+
+	number := fields[0]
+	negative := strings.HasPrefix(number, "-")
+	number = strings.TrimPrefix(number, "-")
+	whole, frac, hasPoint := strings.Cut(number, ".")
+	if whole == "" || (hasPoint && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return TokenAmount{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if len(frac) > int(token.Decimals) {
+		return TokenAmount{}, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidAmount, s, token.Decimals)
+	}
+
+	digits := whole + frac + strings.Repeat("0", int(token.Decimals)-len(frac))
+	raw, _ := new(big.Int).SetString(digits, 10)
+	if negative {
+		raw.Neg(raw)
+	}
+	return TokenAmount{Token: token, raw: raw}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Raw returns a copy of the amount in the smallest unit
+func (a TokenAmount) Raw() *big.Int {
+	if a.raw == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.raw)
+}
+
+// Sign returns -1, 0 or +1
+func (a TokenAmount) Sign() int {
+	if a.raw == nil {
+		return 0
+	}
+	return a.raw.Sign()
+}
+
+// IsZero reports whether the amount is zero
+func (a TokenAmount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// Add returns a + b; both must be amounts of the same token
+func (a TokenAmount) Add(b TokenAmount) (TokenAmount, error) {
+	if err := a.sameToken(b); err != nil {
+		return TokenAmount{}, err
+	}
+	return TokenAmount{Token: a.Token, raw: new(big.Int).Add(a.Raw(), b.Raw())}, nil
+}
+
+// Sub returns a - b; both must be amounts of the same token
+func (a TokenAmount) Sub(b TokenAmount) (TokenAmount, error) {
+	if err := a.sameToken(b); err != nil {
+		return TokenAmount{}, err
+	}
+	return TokenAmount{Token: a.Token, raw: new(big.Int).Sub(a.Raw(), b.Raw())}, nil
+}
+
+// Cmp compares a and b like big.Int.Cmp; both must be amounts of the same token
+func (a TokenAmount) Cmp(b TokenAmount) (int, error) {
+	if err := a.sameToken(b); err != nil {
+		return 0, err
+	}
+	return a.Raw().Cmp(b.Raw()), nil
+}
+
+func (a TokenAmount) sameToken(b TokenAmount) error {
+	if a.Token != b.Token {
+		return fmt.Errorf("%w: %s (%d decimals) and %s (%d decimals)",
+			ErrTokenMismatch, a.Token.Symbol, a.Token.Decimals, b.Token.Symbol, b.Token.Decimals)
+	}
+	return nil
+}
+
+// Float64 returns the amount in whole tokens, for metrics and ratios
+func (a TokenAmount) Float64() float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(a.Raw()), new(big.Float).SetInt(a.Token.Unit())).Float64()
+	return f
+}
+
+// Text formats the amount in whole tokens without the symbol
+// places < 0 prints every significant digit, otherwise exactly places
+// fractional digits, truncated towards zero
+func (a TokenAmount) Text(places int) string {
+	raw := a.Raw()
+	sign := ""
+	if raw.Sign() < 0 {
+		sign = "-"
+		raw.Neg(raw)
+	}
+
+	whole, frac := new(big.Int).QuoRem(raw, a.Token.Unit(), new(big.Int))
+	fracDigits := ""
+	if a.Token.Decimals > 0 {
+		fracDigits = frac.String()
+		fracDigits = strings.Repeat("0", int(a.Token.Decimals)-len(fracDigits)) + fracDigits
+	}
+	if places < 0 {
+		fracDigits = strings.TrimRight(fracDigits, "0")
+	} else if places <= len(fracDigits) {
+		fracDigits = fracDigits[:places]
+	} else {
+		fracDigits += strings.Repeat("0", places-len(fracDigits))
+	}
+
+	if strings.Trim(whole.String()+fracDigits, "0") == "" {
+		sign = ""
+	}
+	if fracDigits == "" {
+		return sign + whole.String()
+	}
+	return sign + whole.String() + "." + fracDigits
+}
+
+// String formats the amount with every significant digit and the symbol,
+// e.g. "1.5 KEVZ"
+func (a TokenAmount) String() string {
+	if a.Token.Symbol == "" {
+		return a.Text(-1)
+	}
+	return a.Text(-1) + " " + a.Token.Symbol
+}
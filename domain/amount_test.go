@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+var (
+	testKEVZ = Token{Symbol: "KEVZ", Decimals: 18}
+	testUSDC = Token{Symbol: "USDC", Decimals: 6}
+)
+
+func TestParseTokenAmount(t *testing.T) {
+	for input, want := range map[string]string{
+		"1.5 KEVZ":             "1500000000000000000",
+		"1.5 kevz":             "1500000000000000000",
+		"1":                    "1000000000000000000",
+		"0.000000000000000001": "1",
+		"-2.25":                "-2250000000000000000",
+		"  7  ":                "7000000000000000000",
+		"007.10":               "7100000000000000000",
+	} {
+		amount, err := ParseTokenAmount(input, testKEVZ)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if amount.Raw().String() != want || amount.Token != testKEVZ {
+			t.Errorf("%q: expected %s raw KEVZ, got %s %s", input, want, amount.Raw(), amount.Token.Symbol)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "1.", ".5", "1.2.3", "1e18", "+1", "1 KEVZ extra", "0.0000001"} {
+		if _, err := ParseTokenAmount(input, testUSDC); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("%q: expected ErrInvalidAmount, got %v", input, err)
+		}
+	}
+	if _, err := ParseTokenAmount("1 KEVZ", testUSDC); !errors.Is(err, ErrTokenMismatch) {
+		t.Errorf("expected ErrTokenMismatch for another symbol, got %v", err)
+	}
+}
+
+func TestTokenAmount_Format(t *testing.T) {
+	for _, tt := range []struct {
+		amount TokenAmount
+		places int
+		text   string
+		str    string
+	}{
+		{testKEVZ.Amount(big.NewInt(1500000000000000000)), 2, "1.50", "1.5 KEVZ"},
+		{testKEVZ.Amount(big.NewInt(1)), 2, "0.00", "0.000000000000000001 KEVZ"},
+		{testUSDC.Amount(big.NewInt(-1234567)), 4, "-1.2345", "-1.234567 USDC"},
+		{testUSDC.Amount(big.NewInt(-1)), 2, "0.00", "-0.000001 USDC"},
+		{testUSDC.Amount(big.NewInt(42000000)), 0, "42", "42 USDC"},
+		{testUSDC.Amount(big.NewInt(5)), 8, "0.00000500", "0.000005 USDC"},
+		{Token{}.Amount(big.NewInt(181)), 2, "181.00", "181"},
+		{NewTokenAmount(testUSDC, nil), -1, "0", "0 USDC"},
+	} {
+		if got := tt.amount.Text(tt.places); got != tt.text {
+			t.Errorf("%s: Text(%d) = %q, want %q", tt.amount.Raw(), tt.places, got, tt.text)
+		}
+		if got := tt.amount.String(); got != tt.str {
+			t.Errorf("%s: String() = %q, want %q", tt.amount.Raw(), got, tt.str)
+		}
+	}
+
+	// parsing the formatted value gives the same amount back
+	amount := testUSDC.Amount(big.NewInt(-1234567))
+	parsed, err := ParseTokenAmount(amount.String(), testUSDC)
+	if err != nil || parsed.Raw().Cmp(amount.Raw()) != 0 {
+		t.Errorf("round trip of %s gave %s (%v)", amount, parsed, err)
+	}
+}
+
+func TestTokenAmount_Arithmetic(t *testing.T) {
+	a, _ := ParseTokenAmount("1.5", testUSDC)
+	b, _ := ParseTokenAmount("0.25", testUSDC)
+
+	sum, err := a.Add(b)
+	if err != nil || sum.String() != "1.75 USDC" {
+		t.Errorf("expected 1.75 USDC, got %s (%v)", sum, err)
+	}
+	diff, err := b.Sub(a)
+	if err != nil || diff.String() != "-1.25 USDC" || diff.Sign() >= 0 {
+		t.Errorf("expected -1.25 USDC, got %s (%v)", diff, err)
+	}
+	if cmp, err := a.Cmp(b); err != nil || cmp != 1 {
+		t.Errorf("expected 1.5 > 0.25, got %d (%v)", cmp, err)
+	}
+	if a.String() != "1.5 USDC" || b.String() != "0.25 USDC" {
+		t.Errorf("operands changed: %s, %s", a, b)
+	}
+
+	// same symbol, other decimals: still a different token
+	kevz, _ := ParseTokenAmount("1", testKEVZ)
+	other := Token{Symbol: "USDC", Decimals: 18}.Amount(big.NewInt(1))
+	for _, x := range []TokenAmount{kevz, other} {
+		if _, err := a.Add(x); !errors.Is(err, ErrTokenMismatch) {
+			t.Errorf("expected ErrTokenMismatch adding %s, got %v", x, err)
+		}
+		if _, err := a.Sub(x); !errors.Is(err, ErrTokenMismatch) {
+			t.Errorf("expected ErrTokenMismatch subtracting %s, got %v", x, err)
+		}
+		if _, err := a.Cmp(x); !errors.Is(err, ErrTokenMismatch) {
+			t.Errorf("expected ErrTokenMismatch comparing %s, got %v", x, err)
+		}
+	}
+
+	var zero TokenAmount
+	if !zero.IsZero() || zero.Raw().Sign() != 0 || zero.String() != "0" {
+		t.Errorf("unexpected zero value: %s", zero)
+	}
+	if f := a.Float64(); f != 1.5 {
+		t.Errorf("expected 1.5, got %v", f)
+	}
+}
+
+func TestPoolSnapshot_MixedDecimals(t *testing.T) {
+	// 10 ETH (18 decimals) against 20000 USDC (6 decimals)
+	reserveA, _ := new(big.Int).SetString("10000000000000000000", 10)
+	pool := MustNewPool("ETH", "USDC", reserveA, big.NewInt(20000000000))
+	pool.SetDecimals(18, 6)
+
+	snap := pool.Snapshot()
+	if price := snap.UnitPriceAInB(); price != 2000 {
+		t.Errorf("expected 2000 USDC per ETH, got %v", price)
+	}
+	if snap.PriceAInB == 2000 {
+		t.Error("PriceAInB is a ratio of raw units and should not match the unit price")
+	}
+	a, b := snap.ReserveAmounts()
+	if a.String() != "10 ETH" || b.String() != "20000 USDC" {
+		t.Errorf("unexpected reserves: %s, %s", a, b)
+	}
+}
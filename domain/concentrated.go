@@ -0,0 +1,496 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrInvalidTickRange is returned for position ranges that are empty,
+// out of bounds or not aligned on the tick spacing
+var ErrInvalidTickRange = errors.New("invalid tick range")
+
+// ConcentratedPool is a Uniswap v3 style pool: liquidity providers choose
+// the price range their liquidity is active in, and swaps cross ticks as
+// the price leaves a range. Prices are TokenB per TokenA, stored as sqrt
+// prices in Q64.96 fixed point. There is no swap fee, like Pool
+// Thread-safe for concurrent access
+type ConcentratedPool struct {
+	mu          sync.RWMutex
+	tokenA      string
+	tokenB      string
+	tickSpacing int
+
+	sqrtPrice *big.Int // Q64.96
+	tick      int      // tick of sqrtPrice; one below a tick just crossed downwards, as in Uniswap v3
+	liquidity *big.Int // liquidity active at the current price
+
+	ticks       map[int]*tickInfo
+	initialized []int // sorted ticks referenced by at least one position
+	positions   map[PositionKey]*big.Int
+
+	// tokens held by the pool
+	balanceA *big.Int
+	balanceB *big.Int
+
+	now func() time.Time
+	eventHub
+}
+
+// PositionKey identifies the liquidity of an owner over a tick range
+// A position is active while Lower <= current tick < Upper
+type PositionKey struct {
+	Owner int
+	Lower int
+	Upper int
+}
+
+// tickInfo is the liquidity referenced by a tick
+type tickInfo struct {
+	gross *big.Int // total liquidity of the positions using this tick
+	net   *big.Int // liquidity added when the price crosses it upwards
+}
+
+// NewConcentratedPool creates an empty pool at the given price of A in B
+// Positions must use ticks that are multiples of tickSpacing
+func NewConcentratedPool(tokenA, tokenB string, price float64, tickSpacing int) (*ConcentratedPool, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("initial price must be positive")
+	}
+	if tickSpacing <= 0 {
+		return nil, fmt.Errorf("tick spacing must be positive")
+	}
+
+	sqrtPrice := SqrtPriceFromPrice(price)
+	if sqrtPrice.Cmp(SqrtPriceAtTick(MinTick)) < 0 || sqrtPrice.Cmp(SqrtPriceAtTick(MaxTick)) >= 0 {
+		return nil, fmt.Errorf("initial price %g out of the tick range", price)
+	}
+
+	return &ConcentratedPool{
+		tokenA:      tokenA,
+		tokenB:      tokenB,
+		tickSpacing: tickSpacing,
+		sqrtPrice:   sqrtPrice,
+		tick:        TickAtSqrtPrice(sqrtPrice),
+		liquidity:   new(big.Int),
+		ticks:       make(map[int]*tickInfo),
+		positions:   make(map[PositionKey]*big.Int),
+		balanceA:    new(big.Int),
+		balanceB:    new(big.Int),
+		now:         time.Now,
+	}, nil
+}
+
+// SetClock replaces the time source of swap events
+func (p *ConcentratedPool) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now = now
+}
+
+// TickSpacing returns the spacing position ticks must be aligned on
+func (p *ConcentratedPool) TickSpacing() int {
+	return p.tickSpacing
+}
+
+// Tick returns the current tick
+func (p *ConcentratedPool) Tick() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.tick
+}
+
+// SqrtPriceX96 returns a copy of the current sqrt price in Q64.96
+func (p *ConcentratedPool) SqrtPriceX96() *big.Int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return new(big.Int).Set(p.sqrtPrice)
+}
+
+// Liquidity returns the liquidity active at the current price
+func (p *ConcentratedPool) Liquidity() *big.Int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return new(big.Int).Set(p.liquidity)
+}
+
+// Position returns the liquidity of a position (zero if it does not exist)
+func (p *ConcentratedPool) Position(key PositionKey) *big.Int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if l, ok := p.positions[key]; ok {
+		return new(big.Int).Set(l)
+	}
+	return new(big.Int)
+}
+
+// Mint adds liquidity to a position and returns the token amounts the owner
+// deposits, rounded up in favour of the pool
+func (p *ConcentratedPool) Mint(key PositionKey, liquidity *big.Int) (amountA, amountB *big.Int, err error) {
+	if err := p.validateRange(key); err != nil {
+		return nil, nil, err
+	}
+	if liquidity.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("liquidity must be positive")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	amountA, amountB = p.amountsForLiquidity(key, liquidity, true)
+	p.updatePosition(key, liquidity)
+	p.balanceA.Add(p.balanceA, amountA)
+	p.balanceB.Add(p.balanceB, amountB)
+	return amountA, amountB, nil
+}
+
+// Burn removes liquidity from a position and returns the token amounts the
+// owner withdraws, rounded down in favour of the pool
+func (p *ConcentratedPool) Burn(key PositionKey, liquidity *big.Int) (amountA, amountB *big.Int, err error) {
+	if liquidity.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("liquidity must be positive")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current, ok := p.positions[key]
+	if !ok || current.Cmp(liquidity) < 0 {
+		return nil, nil, fmt.Errorf("position %+v: %w", key, ErrInsufficientLiquidity)
+	}
+
+	amountA, amountB = p.amountsForLiquidity(key, liquidity, false)
+	p.updatePosition(key, new(big.Int).Neg(liquidity))
+	p.balanceA.Sub(p.balanceA, amountA)
+	p.balanceB.Sub(p.balanceB, amountB)
+	return amountA, amountB, nil
+}
+
+// Tokens returns the symbols of TokenA and TokenB
+func (p *ConcentratedPool) Tokens() (tokenA, tokenB string) {
+	return p.tokenA, p.tokenB
+}
+
+// Swap sells amountIn in the given direction on behalf of a trader and
+// returns the amount received. The swap crosses as many ticks as needed and
+// fails without changing the pool if the liquidity runs out
+func (p *ConcentratedPool) Swap(trader int, direction Direction, amountIn *big.Int) (*big.Int, error) {
+	result, err := p.swap(trader, direction, amountIn, true)
+	if err != nil {
+		return nil, err
+	}
+	return result.out, nil
+}
+
+// SwapExactOut buys exactly amountOut in the given direction on behalf of a
+// trader and returns the amount paid
+func (p *ConcentratedPool) SwapExactOut(trader int, direction Direction, amountOut *big.Int) (*big.Int, error) {
+	result, err := p.swap(trader, direction, amountOut, false)
+	if err != nil {
+		return nil, err
+	}
+	return result.in, nil
+}
+
+// Quote returns what selling amountIn would give, without swapping
+func (p *ConcentratedPool) Quote(direction Direction, amountIn *big.Int) (*big.Int, error) {
+	result, err := p.quote(direction, amountIn, true)
+	if err != nil {
+		return nil, err
+	}
+	return result.out, nil
+}
+
+// QuoteExactOut returns what buying amountOut would cost, without swapping
+func (p *ConcentratedPool) QuoteExactOut(direction Direction, amountOut *big.Int) (*big.Int, error) {
+	result, err := p.quote(direction, amountOut, false)
+	if err != nil {
+		return nil, err
+	}
+	return result.in, nil
+}
+
+func (p *ConcentratedPool) quote(direction Direction, amount *big.Int, exactIn bool) (*swapResult, error) {
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.compute(direction, amount, exactIn)
+}
+
+// swap computes and applies an exact-in (amount is the input) or exact-out swap
+func (p *ConcentratedPool) swap(trader int, direction Direction, amount *big.Int, exactIn bool) (*swapResult, error) {
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result, err := p.compute(direction, amount, exactIn)
+	if err != nil {
+		return nil, err
+	}
+
+	p.sqrtPrice, p.tick, p.liquidity = result.sqrtPrice, result.tick, result.liquidity
+	if direction == AToB {
+		p.balanceA.Add(p.balanceA, result.in)
+		p.balanceB.Sub(p.balanceB, result.out)
+	} else {
+		p.balanceB.Add(p.balanceB, result.in)
+		p.balanceA.Sub(p.balanceA, result.out)
+	}
+
+	now := p.now()
+	p.publish(func() SwapEvent {
+		return SwapEvent{
+			Trader:    trader,
+			Direction: direction,
+			AmountIn:  new(big.Int).Set(result.in),
+			AmountOut: new(big.Int).Set(result.out),
+			ReserveA:  new(big.Int).Set(p.balanceA),
+			ReserveB:  new(big.Int).Set(p.balanceB),
+			PriceAInB: PriceFromSqrtPrice(p.sqrtPrice),
+			Time:      now,
+		}
+	})
+	return result, nil
+}
+
+// swapResult is the outcome of a swap computed on the current state
+type swapResult struct {
+	in, out   *big.Int
+	sqrtPrice *big.Int
+	tick      int
+	liquidity *big.Int
+}
+
+// compute walks the ticks of a swap without changing the pool
+// Called with p.mu held
+func (p *ConcentratedPool) compute(direction Direction, amount *big.Int, exactIn bool) (*swapResult, error) {
+	down := direction == AToB // selling A lowers the price of A
+	r := &swapResult{
+		in:        new(big.Int),
+		out:       new(big.Int),
+		sqrtPrice: new(big.Int).Set(p.sqrtPrice),
+		tick:      p.tick,
+		liquidity: new(big.Int).Set(p.liquidity),
+	}
+	remaining := new(big.Int).Set(amount) // input left, or output left for exact out
+
+	for remaining.Sign() > 0 {
+		next, ok := p.nextInitializedTick(r.tick, down)
+		if !ok {
+			if r.liquidity.Sign() == 0 {
+				return nil, ErrInsufficientLiquidity
+			}
+			// no more ticks: the active liquidity runs to the price bound
+			next = MaxTick
+			if down {
+				next = MinTick
+			}
+		}
+		target := SqrtPriceAtTick(next)
+
+		var in, out, sqrtNext *big.Int
+		if exactIn {
+			in, out, sqrtNext = swapStep(r.sqrtPrice, target, r.liquidity, remaining, down)
+			remaining.Sub(remaining, in)
+		} else {
+			in, out, sqrtNext = swapStepExactOut(r.sqrtPrice, target, r.liquidity, remaining, down)
+			remaining.Sub(remaining, out)
+		}
+		r.in.Add(r.in, in)
+		r.out.Add(r.out, out)
+
+		if sqrtNext.Cmp(target) != 0 {
+			// filled inside the range; keep the tick if the price did not move
+			if sqrtNext.Cmp(r.sqrtPrice) != 0 {
+				r.tick = TickAtSqrtPrice(sqrtNext)
+			}
+			r.sqrtPrice = sqrtNext
+			break
+		}
+		r.sqrtPrice = sqrtNext
+		if !ok {
+			return nil, ErrInsufficientLiquidity
+		}
+
+		// cross the tick: positions starting or ending here switch on/off
+		net := p.ticks[next].net
+		if down {
+			r.liquidity.Sub(r.liquidity, net)
+			r.tick = next - 1
+		} else {
+			r.liquidity.Add(r.liquidity, net)
+			r.tick = next
+		}
+	}
+	return r, nil
+}
+
+// swapStep moves the price from sqrtPrice towards target with constant
+// liquidity, using at most remaining, and returns what went in and out
+func swapStep(sqrtPrice, target, liquidity, remaining *big.Int, down bool) (in, out, sqrtNext *big.Int) {
+	if down {
+		maxIn := amountADelta(target, sqrtPrice, liquidity, true)
+		if remaining.Cmp(maxIn) >= 0 {
+			in, sqrtNext = maxIn, target
+		} else {
+			in, sqrtNext = new(big.Int).Set(remaining), nextSqrtPriceFromAmountA(sqrtPrice, liquidity, remaining)
+		}
+		return in, amountBDelta(sqrtNext, sqrtPrice, liquidity, false), sqrtNext
+	}
+
+	maxIn := amountBDelta(sqrtPrice, target, liquidity, true)
+	if remaining.Cmp(maxIn) >= 0 {
+		in, sqrtNext = maxIn, target
+	} else {
+		in, sqrtNext = new(big.Int).Set(remaining), nextSqrtPriceFromAmountB(sqrtPrice, liquidity, remaining)
+	}
+	return in, amountADelta(sqrtPrice, sqrtNext, liquidity, false), sqrtNext
+}
+
+// swapStepExactOut moves the price towards target until remaining has been
+// paid out, and returns what went in and out
+func swapStepExactOut(sqrtPrice, target, liquidity, remaining *big.Int, down bool) (in, out, sqrtNext *big.Int) {
+	if down {
+		maxOut := amountBDelta(target, sqrtPrice, liquidity, false)
+		if remaining.Cmp(maxOut) >= 0 {
+			out, sqrtNext = maxOut, target
+		} else {
+			out, sqrtNext = new(big.Int).Set(remaining), nextSqrtPriceFromOutputB(sqrtPrice, liquidity, remaining)
+		}
+		return amountADelta(sqrtNext, sqrtPrice, liquidity, true), out, sqrtNext
+	}
+
+	maxOut := amountADelta(sqrtPrice, target, liquidity, false)
+	if remaining.Cmp(maxOut) >= 0 {
+		out, sqrtNext = maxOut, target
+	} else {
+		out, sqrtNext = new(big.Int).Set(remaining), nextSqrtPriceFromOutputA(sqrtPrice, liquidity, remaining)
+	}
+	return amountBDelta(sqrtPrice, sqrtNext, liquidity, true), out, sqrtNext
+}
+
+// nextInitializedTick returns the next tick with liquidity in the swap
+// direction: the greatest one <= tick going down, the least one > tick going up
+func (p *ConcentratedPool) nextInitializedTick(tick int, down bool) (int, bool) {
+	if down {
+		i := sort.SearchInts(p.initialized, tick+1) - 1
+		if i < 0 {
+			return 0, false
+		}
+		return p.initialized[i], true
+	}
+	i := sort.SearchInts(p.initialized, tick+1)
+	if i == len(p.initialized) {
+		return 0, false
+	}
+	return p.initialized[i], true
+}
+
+// amountsForLiquidity returns the tokens backing liquidity over a range at
+// the current price: only A above the price, only B below, both inside
+func (p *ConcentratedPool) amountsForLiquidity(key PositionKey, liquidity *big.Int, roundUp bool) (amountA, amountB *big.Int) {
+	sqrtLower := SqrtPriceAtTick(key.Lower)
+	sqrtUpper := SqrtPriceAtTick(key.Upper)
+
+	switch {
+	case p.tick < key.Lower:
+		return amountADelta(sqrtLower, sqrtUpper, liquidity, roundUp), new(big.Int)
+	case p.tick >= key.Upper:
+		return new(big.Int), amountBDelta(sqrtLower, sqrtUpper, liquidity, roundUp)
+	default:
+		return amountADelta(p.sqrtPrice, sqrtUpper, liquidity, roundUp),
+			amountBDelta(sqrtLower, p.sqrtPrice, liquidity, roundUp)
+	}
+}
+
+// updatePosition applies a liquidity delta to a position and its ticks
+// Called with p.mu held
+func (p *ConcentratedPool) updatePosition(key PositionKey, delta *big.Int) {
+	position, ok := p.positions[key]
+	if !ok {
+		position = new(big.Int)
+		p.positions[key] = position
+	}
+	position.Add(position, delta)
+	if position.Sign() == 0 {
+		delete(p.positions, key)
+	}
+
+	p.updateTick(key.Lower, delta, delta)
+	p.updateTick(key.Upper, delta, new(big.Int).Neg(delta))
+
+	if p.tick >= key.Lower && p.tick < key.Upper {
+		p.liquidity.Add(p.liquidity, delta)
+	}
+}
+
+func (p *ConcentratedPool) updateTick(tick int, grossDelta, netDelta *big.Int) {
+	info, ok := p.ticks[tick]
+	if !ok {
+		info = &tickInfo{gross: new(big.Int), net: new(big.Int)}
+		p.ticks[tick] = info
+		i := sort.SearchInts(p.initialized, tick)
+		p.initialized = append(p.initialized, 0)
+		copy(p.initialized[i+1:], p.initialized[i:])
+		p.initialized[i] = tick
+	}
+	info.gross.Add(info.gross, grossDelta)
+	info.net.Add(info.net, netDelta)
+
+	if info.gross.Sign() == 0 {
+		delete(p.ticks, tick)
+		i := sort.SearchInts(p.initialized, tick)
+		p.initialized = append(p.initialized[:i], p.initialized[i+1:]...)
+	}
+}
+
+func (p *ConcentratedPool) validateRange(key PositionKey) error {
+	switch {
+	case key.Lower >= key.Upper:
+		return fmt.Errorf("%w: lower %d must be below upper %d", ErrInvalidTickRange, key.Lower, key.Upper)
+	case key.Lower < MinTick || key.Upper > MaxTick:
+		return fmt.Errorf("%w: [%d, %d] out of bounds", ErrInvalidTickRange, key.Lower, key.Upper)
+	case key.Lower%p.tickSpacing != 0 || key.Upper%p.tickSpacing != 0:
+		return fmt.Errorf("%w: ticks must be multiples of %d", ErrInvalidTickRange, p.tickSpacing)
+	}
+	return nil
+}
+
+// PriceAInB returns the spot price of TokenA in terms of TokenB
+func (p *ConcentratedPool) PriceAInB() float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return PriceFromSqrtPrice(p.sqrtPrice)
+}
+
+// PriceBInA returns the spot price of TokenB in terms of TokenA
+func (p *ConcentratedPool) PriceBInA() float64 {
+	return 1 / p.PriceAInB()
+}
+
+// Snapshot returns the pool state read under a single lock
+// Reserves are the tokens held by the pool; K is the invariant of the
+// active liquidity (L^2), which only holds until the next tick is crossed
+func (p *ConcentratedPool) Snapshot() PoolSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	price := PriceFromSqrtPrice(p.sqrtPrice)
+	return PoolSnapshot{
+		TokenA:    p.tokenA,
+		TokenB:    p.tokenB,
+		ReserveA:  new(big.Int).Set(p.balanceA),
+		ReserveB:  new(big.Int).Set(p.balanceB),
+		K:         new(big.Int).Mul(p.liquidity, p.liquidity),
+		PriceAInB: price,
+		PriceBInA: 1 / price,
+	}
+}
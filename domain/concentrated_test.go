@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestSqrtPriceAtTick(t *testing.T) {
+	if SqrtPriceAtTick(0).Cmp(q96) != 0 {
+		t.Errorf("tick 0 should be sqrt price 1 (2^96), got %s", SqrtPriceAtTick(0))
+	}
+
+	for _, tick := range []int{-887272, -50000, -1, 0, 1, 6931, 50000, 887271} {
+		sqrtPrice := SqrtPriceAtTick(tick)
+		if SqrtPriceAtTick(tick+1).Cmp(sqrtPrice) <= 0 {
+			t.Errorf("sqrt price must increase from tick %d", tick)
+		}
+		if got := TickAtSqrtPrice(sqrtPrice); got != tick {
+			t.Errorf("TickAtSqrtPrice(SqrtPriceAtTick(%d)) = %d", tick, got)
+		}
+		// just below the tick price belongs to the previous tick
+		below := new(big.Int).Sub(sqrtPrice, big.NewInt(1))
+		if got := TickAtSqrtPrice(below); got != tick-1 && tick > MinTick {
+			t.Errorf("TickAtSqrtPrice just below tick %d = %d", tick, got)
+		}
+	}
+
+	// 1.0001^6931 ~ 2
+	if price := PriceFromSqrtPrice(SqrtPriceAtTick(6931)); math.Abs(price-2) > 0.001 {
+		t.Errorf("expected price ~2 at tick 6931, got %f", price)
+	}
+}
+
+// newRangePool creates a pool at price 2 with one position over [lower, upper]
+func newRangePool(t *testing.T, lower, upper int, liquidity int64) *ConcentratedPool {
+	t.Helper()
+	pool, err := NewConcentratedPool("ETH", "USDC", 2, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := pool.Mint(PositionKey{Owner: 1, Lower: lower, Upper: upper}, big.NewInt(liquidity)); err != nil {
+		t.Fatalf("unexpected mint error: %v", err)
+	}
+	return pool
+}
+
+func TestConcentratedPool_Mint(t *testing.T) {
+	pool, _ := NewConcentratedPool("ETH", "USDC", 2, 10)
+	liquidity := big.NewInt(1000000000)
+
+	// in range: both tokens, in the ratio of the price
+	a, b, err := pool.Mint(PositionKey{Owner: 1, Lower: 0, Upper: 13860}, liquidity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Sign() <= 0 || b.Sign() <= 0 {
+		t.Errorf("in-range position needs both tokens, got %s A and %s B", a, b)
+	}
+	if pool.Liquidity().Cmp(liquidity) != 0 {
+		t.Errorf("expected active liquidity %s, got %s", liquidity, pool.Liquidity())
+	}
+
+	// above the price: only A, below: only B, neither is active
+	a, b, _ = pool.Mint(PositionKey{Owner: 2, Lower: 20000, Upper: 30000}, liquidity)
+	if a.Sign() <= 0 || b.Sign() != 0 {
+		t.Errorf("position above the price should be all A, got %s A and %s B", a, b)
+	}
+	a, b, _ = pool.Mint(PositionKey{Owner: 3, Lower: -10000, Upper: 0}, liquidity)
+	if a.Sign() != 0 || b.Sign() <= 0 {
+		t.Errorf("position below the price should be all B, got %s A and %s B", a, b)
+	}
+	if pool.Liquidity().Cmp(liquidity) != 0 {
+		t.Errorf("out-of-range positions should not be active, got %s", pool.Liquidity())
+	}
+
+	for _, key := range []PositionKey{
+		{Lower: 100, Upper: 100},
+		{Lower: 5, Upper: 100},
+		{Lower: MinTick - 10, Upper: 0},
+	} {
+		if _, _, err := pool.Mint(key, liquidity); !errors.Is(err, ErrInvalidTickRange) {
+			t.Errorf("expected ErrInvalidTickRange for %+v, got %v", key, err)
+		}
+	}
+}
+
+func TestConcentratedPool_SwapInRange(t *testing.T) {
+	pool := newRangePool(t, -20000, 40000, 1000000000000)
+	before := pool.Snapshot()
+
+	out, err := pool.Swap(1, AToB, big.NewInt(1000000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	after := pool.Snapshot()
+
+	// inside a range the pool behaves as x*y=L^2 on virtual reserves:
+	// selling dx gives L^2/x - L^2/(x+dx) with x = L/sqrtP
+	l := 1000000000000.0
+	x := l / math.Sqrt(2)
+	expected := l*l/x - l*l/(x+1000000)
+	if math.Abs(float64(out.Int64())-expected) > 2 {
+		t.Errorf("expected ~%f out, got %s", expected, out)
+	}
+	if after.PriceAInB >= before.PriceAInB {
+		t.Error("selling A should lower its price")
+	}
+	if after.ReserveA.Int64()-before.ReserveA.Int64() != 1000000 ||
+		before.ReserveB.Int64()-after.ReserveB.Int64() != out.Int64() {
+		t.Errorf("balances do not match the swap: %+v -> %+v", before, after)
+	}
+
+	// the pool keeps the rounding: a round trip never profits
+	back, err := pool.Swap(1, BToA, out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back.Cmp(big.NewInt(1000000)) > 0 {
+		t.Errorf("round trip returned %s A for 1000000 A", back)
+	}
+}
+
+func TestConcentratedPool_CrossesTicks(t *testing.T) {
+	pool, _ := NewConcentratedPool("ETH", "USDC", 2, 10)
+	narrow := PositionKey{Owner: 1, Lower: 6900, Upper: 6960}
+	wide := PositionKey{Owner: 2, Lower: 0, Upper: 13860}
+	pool.Mint(narrow, big.NewInt(1000000000000))
+	pool.Mint(wide, big.NewInt(1000000000))
+	total := new(big.Int).Add(pool.Position(narrow), pool.Position(wide))
+	if pool.Liquidity().Cmp(total) != 0 {
+		t.Fatalf("both positions should be active, got %s", pool.Liquidity())
+	}
+
+	// sell enough A to leave the narrow range: only the wide one remains
+	if _, err := pool.Swap(1, AToB, big.NewInt(1200000000)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.Tick() >= narrow.Lower {
+		t.Fatalf("expected the price below the narrow range, at tick %d", pool.Tick())
+	}
+	if pool.Liquidity().Cmp(pool.Position(wide)) != 0 {
+		t.Errorf("expected only the wide position active, got %s", pool.Liquidity())
+	}
+
+	// buy A back into the narrow range: it is active again
+	if _, err := pool.Swap(2, BToA, big.NewInt(2000000000)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tick := pool.Tick(); tick < narrow.Lower || tick >= narrow.Upper {
+		t.Fatalf("expected the price back in the narrow range, at tick %d", tick)
+	}
+	if pool.Liquidity().Cmp(total) != 0 {
+		t.Errorf("narrow position should be active again, got %s", pool.Liquidity())
+	}
+}
+
+func TestConcentratedPool_InsufficientLiquidity(t *testing.T) {
+	pool := newRangePool(t, 6000, 8000, 1000000)
+	before := pool.Snapshot()
+
+	if _, err := pool.Swap(1, AToB, big.NewInt(1000000000)); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Fatalf("expected ErrInsufficientLiquidity, got %v", err)
+	}
+	after := pool.Snapshot()
+	if after.PriceAInB != before.PriceAInB || after.ReserveA.Cmp(before.ReserveA) != 0 {
+		t.Error("a failed swap must not change the pool")
+	}
+
+	empty, _ := NewConcentratedPool("ETH", "USDC", 2, 10)
+	if _, err := empty.Swap(1, BToA, big.NewInt(1)); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("expected ErrInsufficientLiquidity on an empty pool, got %v", err)
+	}
+}
+
+func TestConcentratedPool_Burn(t *testing.T) {
+	pool, _ := NewConcentratedPool("ETH", "USDC", 2, 10)
+	key := PositionKey{Owner: 1, Lower: 0, Upper: 13860}
+	liquidity := big.NewInt(1000000000)
+	mintA, mintB, _ := pool.Mint(key, liquidity)
+
+	if _, _, err := pool.Burn(key, big.NewInt(2000000000)); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("expected ErrInsufficientLiquidity burning more than the position, got %v", err)
+	}
+
+	burnA, burnB, err := pool.Burn(key, liquidity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if burnA.Cmp(mintA) > 0 || burnB.Cmp(mintB) > 0 {
+		t.Errorf("burn returned more than minted: %s/%s vs %s/%s", burnA, burnB, mintA, mintB)
+	}
+	if pool.Liquidity().Sign() != 0 || pool.Position(key).Sign() != 0 || len(pool.initialized) != 0 {
+		t.Error("burning the whole position should clear its liquidity and ticks")
+	}
+}
+
+func TestConcentratedPool_Events(t *testing.T) {
+	pool := newRangePool(t, 0, 13860, 1000000000)
+	sub := pool.Subscribe(1)
+	defer sub.Close()
+
+	pool.Swap(4, BToA, big.NewInt(1000))
+	e := <-sub.Events()
+	if e.Trader != 4 || e.Direction != BToA || e.PriceAInB != pool.PriceAInB() {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
+
+func TestLiquidityForAmounts(t *testing.T) {
+	pool, _ := NewConcentratedPool("ETH", "USDC", 2, 10)
+	lower, upper := SqrtPriceAtTick(0), SqrtPriceAtTick(13860)
+	amountA, amountB := big.NewInt(1000000), big.NewInt(1000000)
+
+	liquidity := LiquidityForAmounts(pool.SqrtPriceX96(), lower, upper, amountA, amountB)
+	a, b, err := pool.Mint(PositionKey{Lower: 0, Upper: 13860}, liquidity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the liquidity fits in the amounts (up to rounding) and uses one side fully
+	if a.Cmp(new(big.Int).Add(amountA, big.NewInt(1))) > 0 || b.Cmp(new(big.Int).Add(amountB, big.NewInt(1))) > 0 {
+		t.Errorf("liquidity needs %s A and %s B, more than provided", a, b)
+	}
+	if a.Cmp(big.NewInt(999990)) < 0 && b.Cmp(big.NewInt(999990)) < 0 {
+		t.Errorf("liquidity should use one of the amounts fully, used %s A and %s B", a, b)
+	}
+}
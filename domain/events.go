@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Direction tells which side of the pool a swap goes through
+type Direction int
+
+const (
+	// AToB sells TokenA for TokenB
+	AToB Direction = iota
+	// BToA sells TokenB for TokenA
+	BToA
+)
+
+// String returns a short label for logs and reports
+func (d Direction) String() string {
+	if d == AToB {
+		return "A->B"
+	}
+	return "B->A"
+}
+
+// SwapEvent describes a swap executed on a pool
+// Trader is the bot ID (0 for swaps without a trader, e.g. SwapAForB)
+type SwapEvent struct {
+	Trader    int
+	Direction Direction
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	ReserveA  *big.Int // reserves right after the swap
+	ReserveB  *big.Int
+	PriceAInB float64 // spot price right after the swap
+	Time      time.Time
+}
+
+// Subscription receives the swap events of a pool
+type Subscription struct {
+	hub     *eventHub
+	ch      chan SwapEvent
+	dropped atomic.Uint64
+}
+
+// eventHub fans swap events out to subscribers, embedded by every pool type
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscribe registers a subscriber with the given channel buffer
+// Swaps never block on subscribers: events that do not fit in the buffer
+// are dropped and counted. Events arrive in swap order
+func (h *eventHub) Subscribe(buffer int) *Subscription {
+	sub := &Subscription{hub: h, ch: make(chan SwapEvent, buffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers == nil {
+		h.subscribers = make(map[*Subscription]struct{})
+	}
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Events returns the event channel, closed by Close
+func (s *Subscription) Events() <-chan SwapEvent {
+	return s.ch
+}
+
+// Dropped returns how many events did not fit in the buffer
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes and closes the event channel
+func (s *Subscription) Close() {
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.ch)
+	}
+}
+
+// publish notifies subscribers of a swap
+// The event is only built when someone listens. Pools call it with their
+// lock held, so reserves are consistent and events ordered
+func (h *eventHub) publish(build func() SwapEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subscribers) == 0 {
+		return
+	}
+
+	event := build()
+	for sub := range h.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// SwapFeed lets AMM implementations outside this package (e.g. on-chain
+// adapters) offer the same event stream as the in-memory pools
+// The zero value is ready to use
+type SwapFeed struct {
+	hub eventHub
+}
+
+// Subscribe registers a subscriber, see Pool.Subscribe
+func (f *SwapFeed) Subscribe(buffer int) *Subscription {
+	return f.hub.Subscribe(buffer)
+}
+
+// Publish notifies subscribers of a swap
+func (f *SwapFeed) Publish(event SwapEvent) {
+	f.hub.publish(func() SwapEvent { return event })
+}
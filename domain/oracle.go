@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// DefaultOracleCardinality is how many observations a pool keeps for TWAPs
+const DefaultOracleCardinality = 1024
+
+// ErrOracleWindow is returned when a TWAP window starts before the oldest
+// observation the pool still keeps
+var ErrOracleWindow = errors.New("twap window exceeds the oracle history")
+
+// q112 is the UQ112x112 fixed point unit of the cumulative prices
+var q112 = new(big.Int).Lsh(big.NewInt(1), 112)
+
+// Observation is the price accumulators of a pool at a point in time
+// Like Uniswap v2 price0CumulativeLast, each accumulator is the sum of the
+// spot price (UQ112x112 fixed point) times the time it held, here in
+// nanoseconds. The TWAP between two observations is the accumulator delta
+// divided by the elapsed time
+type Observation struct {
+	Time             time.Time `json:"time"`
+	PriceACumulative *big.Int  `json:"price_a_cumulative"` // price of A in B
+	PriceBCumulative *big.Int  `json:"price_b_cumulative"` // price of B in A
+}
+
+// TWAPBetween returns the time-weighted average prices between two
+// observations of the same pool, older first
+func TWAPBetween(older, newer Observation) (priceAInB, priceBInA float64, err error) {
+	elapsed := newer.Time.Sub(older.Time)
+	if elapsed <= 0 {
+		return 0, 0, fmt.Errorf("observations must be in time order")
+	}
+	priceAInB = average(older.PriceACumulative, newer.PriceACumulative, elapsed)
+	priceBInA = average(older.PriceBCumulative, newer.PriceBCumulative, elapsed)
+	return priceAInB, priceBInA, nil
+}
+
+// average converts an accumulator delta over elapsed into a float price
+func average(older, newer *big.Int, elapsed time.Duration) float64 {
+	delta := new(big.Int).Sub(newer, older)
+	delta.Div(delta, big.NewInt(int64(elapsed)))
+	return ratio(delta, q112)
+}
+
+// SetClock replaces the time source of the oracle and swap events
+// Used by tests and simulations; must be called before any swap
+func (p *Pool) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.now = now
+	p.initOracle(now())
+}
+
+// SetOracleCardinality changes how many observations the pool keeps
+// Larger values allow longer TWAP windows; older observations are discarded
+func (p *Pool) SetOracleCardinality(n int) {
+	if n < 1 {
+		n = 1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	observations := p.orderedObservations()
+	if len(observations) > n {
+		observations = observations[len(observations)-n:]
+	}
+	p.observations = observations
+	p.obsNext = len(observations) % n
+	p.obsCardinality = n
+}
+
+// Observe returns the accumulators as of now, including the time elapsed
+// since the last swap (like Uniswap's currentCumulativePrices)
+func (p *Pool) Observe() Observation {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current(p.now())
+}
+
+// TWAP returns the time-weighted average prices over the last window
+// Returns ErrOracleWindow if the pool does not remember that far back
+func (p *Pool) TWAP(window time.Duration) (priceAInB, priceBInA float64, err error) {
+	if window <= 0 {
+		return 0, 0, fmt.Errorf("twap window must be positive")
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	now := p.now()
+	start, err := p.observeAt(now.Add(-window))
+	if err != nil {
+		return 0, 0, err
+	}
+	return TWAPBetween(start, p.current(now))
+}
+
+// initOracle resets the accumulators with a first observation at t
+func (p *Pool) initOracle(t time.Time) {
+	p.priceACumulative = new(big.Int)
+	p.priceBCumulative = new(big.Int)
+	p.lastUpdate = t
+	if p.obsCardinality == 0 {
+		p.obsCardinality = DefaultOracleCardinality
+	}
+	p.observations = nil
+	p.obsNext = 0
+	p.writeObservation()
+}
+
+// updateOracle accumulates the current prices up to now
+// Called with p.mu held, before the reserves change
+func (p *Pool) updateOracle(now time.Time) {
+	elapsed := now.Sub(p.lastUpdate)
+	if elapsed <= 0 {
+		return
+	}
+	p.priceACumulative.Add(p.priceACumulative, accumulate(p.ReserveB, p.ReserveA, elapsed))
+	p.priceBCumulative.Add(p.priceBCumulative, accumulate(p.ReserveA, p.ReserveB, elapsed))
+	p.lastUpdate = now
+	p.writeObservation()
+}
+
+// accumulate returns (x / y) in UQ112x112 times elapsed nanoseconds
+func accumulate(x, y *big.Int, elapsed time.Duration) *big.Int {
+	if y.Sign() == 0 {
+		return new(big.Int)
+	}
+	price := new(big.Int).Lsh(x, 112)
+	price.Div(price, y)
+	return price.Mul(price, big.NewInt(int64(elapsed)))
+}
+
+// writeObservation stores the accumulators at lastUpdate in the ring
+func (p *Pool) writeObservation() {
+	obs := Observation{
+		Time:             p.lastUpdate,
+		PriceACumulative: new(big.Int).Set(p.priceACumulative),
+		PriceBCumulative: new(big.Int).Set(p.priceBCumulative),
+	}
+	if len(p.observations) < p.obsCardinality {
+		p.observations = append(p.observations, obs)
+	} else {
+		p.observations[p.obsNext] = obs
+	}
+	p.obsNext = (p.obsNext + 1) % p.obsCardinality
+}
+
+// orderedObservations returns the ring oldest first
+func (p *Pool) orderedObservations() []Observation {
+	if len(p.observations) < p.obsCardinality {
+		return append([]Observation(nil), p.observations...)
+	}
+	return append(append([]Observation(nil), p.observations[p.obsNext:]...), p.observations[:p.obsNext]...)
+}
+
+// current returns the accumulators extrapolated from the last update to now
+func (p *Pool) current(now time.Time) Observation {
+	obs := Observation{
+		Time:             now,
+		PriceACumulative: new(big.Int).Set(p.priceACumulative),
+		PriceBCumulative: new(big.Int).Set(p.priceBCumulative),
+	}
+	if elapsed := now.Sub(p.lastUpdate); elapsed > 0 {
+		obs.PriceACumulative.Add(obs.PriceACumulative, accumulate(p.ReserveB, p.ReserveA, elapsed))
+		obs.PriceBCumulative.Add(obs.PriceBCumulative, accumulate(p.ReserveA, p.ReserveB, elapsed))
+	}
+	return obs
+}
+
+// observeAt returns the accumulators at t
+// The price is constant between two observations, so interpolating the
+// surrounding pair gives the exact value
+func (p *Pool) observeAt(t time.Time) (Observation, error) {
+	if !t.Before(p.lastUpdate) {
+		return p.current(t), nil
+	}
+
+	observations := p.orderedObservations()
+	if t.Before(observations[0].Time) {
+		return Observation{}, fmt.Errorf("%w: oldest observation is %s old",
+			ErrOracleWindow, p.now().Sub(observations[0].Time).Round(time.Millisecond))
+	}
+
+	// first observation after t; there is one since t < lastUpdate
+	i := sort.Search(len(observations), func(i int) bool {
+		return observations[i].Time.After(t)
+	})
+	before, after := observations[i-1], observations[i]
+	span := after.Time.Sub(before.Time)
+	offset := t.Sub(before.Time)
+
+	return Observation{
+		Time:             t,
+		PriceACumulative: interpolate(before.PriceACumulative, after.PriceACumulative, offset, span),
+		PriceBCumulative: interpolate(before.PriceBCumulative, after.PriceBCumulative, offset, span),
+	}, nil
+}
+
+// interpolate returns from + (to - from) * offset / span
+func interpolate(from, to *big.Int, offset, span time.Duration) *big.Int {
+	delta := new(big.Int).Sub(to, from)
+	delta.Mul(delta, big.NewInt(int64(offset)))
+	delta.Div(delta, big.NewInt(int64(span)))
+	return delta.Add(delta, from)
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced time source
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newClockedPool(reserveA, reserveB int64) (*Pool, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	pool := MustNewPool("ETH", "USDC", big.NewInt(reserveA), big.NewInt(reserveB))
+	pool.SetClock(clock.now)
+	return pool, clock
+}
+
+func assertPrice(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s: expected %.10f, got %.10f", name, want, got)
+	}
+}
+
+func TestPool_TWAP(t *testing.T) {
+	pool, clock := newClockedPool(1000000, 2000000)
+
+	// 60s at 2.0, then 30s at the post-swap price
+	clock.advance(60 * time.Second)
+	pool.SwapBForA(big.NewInt(1000000))
+	after := pool.PriceAInB()
+	clock.advance(30 * time.Second)
+
+	aInB, bInA, err := pool.TWAP(90 * time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertPrice(t, "twap A in B", aInB, (2.0*60+after*30)/90)
+	assertPrice(t, "twap B in A", bInA, (0.5*60+(1/after)*30)/90)
+
+	// a window inside a constant-price segment is the spot price
+	aInB, _, err = pool.TWAP(20 * time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertPrice(t, "recent twap", aInB, after)
+
+	// a window starting in the middle of the first segment is interpolated
+	aInB, _, err = pool.TWAP(45 * time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertPrice(t, "interpolated twap", aInB, (2.0*15+after*30)/45)
+}
+
+func TestPool_TWAP_ResistsManipulation(t *testing.T) {
+	pool, clock := newClockedPool(1000000, 2000000)
+	clock.advance(10 * time.Minute)
+
+	// pump the spot price 4x and read the oracle in the same instant
+	pool.SwapBForA(big.NewInt(2000000))
+	if spot := pool.PriceAInB(); spot < 7.9 {
+		t.Fatalf("expected the spot price to be pumped, got %f", spot)
+	}
+
+	aInB, _, err := pool.TWAP(10 * time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertPrice(t, "twap right after the pump", aInB, 2.0)
+
+	// a pump held 6s out of 10 minutes barely moves it
+	clock.advance(6 * time.Second)
+	pool.SwapAForB(big.NewInt(1000000))
+	aInB, _, _ = pool.TWAP(10 * time.Minute)
+	if aInB > 2.07 {
+		t.Errorf("short manipulation moved the twap too much: %f", aInB)
+	}
+}
+
+func TestPool_TWAP_Errors(t *testing.T) {
+	pool, clock := newClockedPool(1000000, 2000000)
+	clock.advance(time.Minute)
+
+	if _, _, err := pool.TWAP(0); err == nil {
+		t.Error("expected error for an empty window")
+	}
+	if _, _, err := pool.TWAP(2 * time.Minute); !errors.Is(err, ErrOracleWindow) {
+		t.Errorf("expected ErrOracleWindow before the pool existed, got %v", err)
+	}
+
+	// a small ring forgets old observations: only the last 2 swaps are kept
+	pool.SetOracleCardinality(2)
+	for i := 0; i < 3; i++ {
+		clock.advance(time.Second)
+		pool.SwapAForB(big.NewInt(10))
+	}
+	if _, _, err := pool.TWAP(time.Second); err != nil {
+		t.Errorf("unexpected error within the history: %v", err)
+	}
+	if _, _, err := pool.TWAP(2 * time.Second); !errors.Is(err, ErrOracleWindow) {
+		t.Errorf("expected ErrOracleWindow past the history, got %v", err)
+	}
+}
+
+func TestTWAPBetween(t *testing.T) {
+	pool, clock := newClockedPool(1000000, 2000000)
+	first := pool.Observe()
+
+	clock.advance(time.Minute)
+	pool.SwapAForB(big.NewInt(1000000))
+	clock.advance(time.Minute)
+	second := pool.Observe()
+
+	aInB, _, err := TWAPBetween(first, second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertPrice(t, "twap between observations", aInB, (2.0+pool.PriceAInB())/2)
+
+	if _, _, err := TWAPBetween(second, first); err == nil {
+		t.Error("expected error for observations out of order")
+	}
+}
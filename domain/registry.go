@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownToken is returned when a symbol or address is not registered
+	ErrUnknownToken = errors.New("unknown token")
+
+	// ErrDuplicateToken is returned when a symbol or address is already
+	// registered for another token
+	ErrDuplicateToken = errors.New("duplicate token")
+)
+
+// TokenRegistry maps symbols and contract addresses to token metadata
+// It is shared by the pool, the bots and the chain client so a token is
+// named the same way everywhere. Symbols are case-insensitive, addresses
+// are compared without their checksum case. Safe for concurrent use
+type TokenRegistry struct {
+	mu        sync.RWMutex
+	bySymbol  map[string]Token
+	byAddress map[string]Token
+}
+
+// NewTokenRegistry returns a registry holding tokens
+func NewTokenRegistry(tokens ...Token) (*TokenRegistry, error) {
+	r := &TokenRegistry{
+		bySymbol:  make(map[string]Token),
+		byAddress: make(map[string]Token),
+	}
+	for _, token := range tokens {
+		if err := r.Register(token); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a token. Registering a token again is a no-op, and fills in
+// its name if it had none; a symbol or address already used by a token with
+// other metadata is refused with ErrDuplicateToken
+func (r *TokenRegistry) Register(token Token) error {
+	if token.Symbol == "" || strings.ContainsAny(token.Symbol, " \t\n") {
+		return fmt.Errorf("invalid token symbol %q", token.Symbol)
+	}
+	if token.Decimals > MaxDecimals {
+		return fmt.Errorf("token %s has %d decimals, more than %d", token.Symbol, token.Decimals, MaxDecimals)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	symbol, address := symbolKey(token.Symbol), addressKey(token.Address)
+	existing, ok := r.bySymbol[symbol]
+	if !ok && address != "" {
+		existing, ok = r.byAddress[address]
+	}
+	if ok {
+		if existing.Symbol != token.Symbol || existing.Decimals != token.Decimals ||
+			addressKey(existing.Address) != address {
+			return fmt.Errorf("%w: %s (%s, %d decimals) conflicts with %s (%s, %d decimals)", ErrDuplicateToken,
+				token.Symbol, displayAddress(token.Address), token.Decimals,
+				existing.Symbol, displayAddress(existing.Address), existing.Decimals)
+		}
+		if existing.Name != "" {
+			return nil
+		}
+		token.Address = existing.Address
+	}
+
+	r.bySymbol[symbol] = token
+	if address != "" {
+		r.byAddress[address] = token
+	}
+	return nil
+}
+
+// Lookup returns the token with a symbol
+func (r *TokenRegistry) Lookup(symbol string) (Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	token, ok := r.bySymbol[symbolKey(symbol)]
+	if !ok {
+		return Token{}, fmt.Errorf("%w: %s", ErrUnknownToken, symbol)
+	}
+	return token, nil
+}
+
+// ByAddress returns the token deployed at a contract address
+func (r *TokenRegistry) ByAddress(address string) (Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	token, ok := r.byAddress[addressKey(address)]
+	if !ok || address == "" {
+		return Token{}, fmt.Errorf("%w: %s", ErrUnknownToken, displayAddress(address))
+	}
+	return token, nil
+}
+
+// Tokens returns every registered token, sorted by symbol
+func (r *TokenRegistry) Tokens() []Token {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tokens := make([]Token, 0, len(r.bySymbol))
+	for _, token := range r.bySymbol {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Symbol < tokens[j].Symbol })
+	return tokens
+}
+
+// ParseAmount reads an amount naming its token, e.g. "1.5 KEVZ", with the
+// decimals of the registered token
+func (r *TokenRegistry) ParseAmount(s string) (TokenAmount, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return TokenAmount{}, fmt.Errorf("%w: %q must be an amount and a symbol", ErrInvalidAmount, s)
+	}
+	token, err := r.Lookup(fields[1])
+	if err != nil {
+		return TokenAmount{}, err
+	}
+	return ParseTokenAmount(s, token)
+}
+
+func symbolKey(symbol string) string {
+	return strings.ToUpper(symbol)
+}
+
+func addressKey(address string) string {
+	return strings.ToLower(address)
+}
+
+func displayAddress(address string) string {
+	if address == "" {
+		return "no address"
+	}
+	return address
+}
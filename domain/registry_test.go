@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	testKEVZAddress = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	testUSDCAddress = "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512"
+)
+
+func newTestRegistry(t *testing.T) *TokenRegistry {
+	t.Helper()
+	r, err := NewTokenRegistry(
+		Token{Symbol: "KEVZ", Decimals: 18, Name: "Kevz Token", Address: testKEVZAddress},
+		Token{Symbol: "USDC", Decimals: 6, Address: testUSDCAddress},
+		Token{Symbol: "ETH", Decimals: 18},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return r
+}
+
+func TestTokenRegistry_Lookup(t *testing.T) {
+	r := newTestRegistry(t)
+
+	kevz, err := r.Lookup("kevz")
+	if err != nil || kevz.Name != "Kevz Token" || kevz.Decimals != 18 {
+		t.Errorf("unexpected KEVZ: %+v (%v)", kevz, err)
+	}
+	usdc, err := r.ByAddress("0xE7F1725E7734CE288F8367E1BB143E90BB3F0512")
+	if err != nil || usdc.Symbol != "USDC" || usdc.Address != testUSDCAddress {
+		t.Errorf("unexpected token at the USDC address: %+v (%v)", usdc, err)
+	}
+
+	if _, err := r.Lookup("DAI"); !errors.Is(err, ErrUnknownToken) {
+		t.Errorf("expected ErrUnknownToken, got %v", err)
+	}
+	if _, err := r.ByAddress(""); !errors.Is(err, ErrUnknownToken) {
+		t.Errorf("expected ErrUnknownToken for no address, got %v", err)
+	}
+
+	var symbols []string
+	for _, token := range r.Tokens() {
+		symbols = append(symbols, token.Symbol)
+	}
+	if len(symbols) != 3 || symbols[0] != "ETH" || symbols[1] != "KEVZ" || symbols[2] != "USDC" {
+		t.Errorf("expected tokens sorted by symbol, got %v", symbols)
+	}
+}
+
+func TestTokenRegistry_Register(t *testing.T) {
+	r := newTestRegistry(t)
+
+	// same token again: no-op, the missing name is filled in
+	if err := r.Register(Token{Symbol: "USDC", Decimals: 6, Name: "USD Coin", Address: testUSDCAddress}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usdc, _ := r.Lookup("USDC"); usdc.Name != "USD Coin" {
+		t.Errorf("expected the name to be filled in, got %+v", usdc)
+	}
+	if err := r.Register(Token{Symbol: "KEVZ", Decimals: 18, Address: testKEVZAddress}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kevz, _ := r.Lookup("KEVZ"); kevz.Name != "Kevz Token" {
+		t.Errorf("expected the name to be kept, got %+v", kevz)
+	}
+
+	for _, token := range []Token{
+		{Symbol: "USDC", Decimals: 18, Address: testUSDCAddress}, // other decimals
+		{Symbol: "USDC", Decimals: 6},                            // no address
+		{Symbol: "USDT", Decimals: 6, Address: testUSDCAddress},  // address taken
+		{Symbol: "ETH", Decimals: 18, Address: testKEVZAddress},  // address of another token
+		{Symbol: "kevz", Decimals: 18, Address: "0x0000000000000000000000000000000000000001"},
+	} {
+		if err := r.Register(token); !errors.Is(err, ErrDuplicateToken) {
+			t.Errorf("%+v: expected ErrDuplicateToken, got %v", token, err)
+		}
+	}
+	for _, token := range []Token{{}, {Symbol: "TWO WORDS"}, {Symbol: "BIG", Decimals: MaxDecimals + 1}} {
+		if err := r.Register(token); err == nil || errors.Is(err, ErrDuplicateToken) {
+			t.Errorf("%+v: expected a validation error, got %v", token, err)
+		}
+	}
+	if _, err := NewTokenRegistry(testUSDC, Token{Symbol: "USDC", Decimals: 18}); !errors.Is(err, ErrDuplicateToken) {
+		t.Errorf("expected ErrDuplicateToken from NewTokenRegistry, got %v", err)
+	}
+}
+
+func TestTokenRegistry_ParseAmount(t *testing.T) {
+	r := newTestRegistry(t)
+
+	amount, err := r.ParseAmount("2.5 usdc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if amount.Raw().Int64() != 2500000 || amount.Token.Address != testUSDCAddress {
+		t.Errorf("expected 2500000 raw USDC, got %s of %+v", amount.Raw(), amount.Token)
+	}
+
+	if _, err := r.ParseAmount("2.5"); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount without a symbol, got %v", err)
+	}
+	if _, err := r.ParseAmount("1 DAI"); !errors.Is(err, ErrUnknownToken) {
+		t.Errorf("expected ErrUnknownToken, got %v", err)
+	}
+	if _, err := r.ParseAmount("0.0000001 USDC"); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount past the USDC decimals, got %v", err)
+	}
+}
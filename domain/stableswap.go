@@ -0,0 +1,470 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// stableSwapIterations bounds the Newton iterations, as in Curve
+const stableSwapIterations = 255
+
+// StableSwapPool is a Curve style pool for assets meant to trade near 1:1
+// It uses the StableSwap invariant
+//
+//	A * n^n * sum(x) + D = A * n^n * D + D^(n+1) / (n^n * prod(x))
+//
+// which is flat (constant sum) around the balanced point and curves into
+// constant product as the pool gets imbalanced. A higher amplification A
+// means lower slippage near the peg. All balances use the same decimals and
+// there is no swap fee, like Pool
+//
+// The pool holds 2 or more tokens. As an AMM, TokenA and TokenB are the
+// first two tokens; the swap event stream covers every exchange between them
+// Thread-safe for concurrent access
+type StableSwapPool struct {
+	mu       sync.RWMutex
+	tokens   []string
+	balances []*big.Int
+	amp      *big.Int
+	supply   *big.Int // LP tokens
+
+	now func() time.Time
+	eventHub
+}
+
+// NewStableSwapPool creates a pool with initial balances and amplification
+// The initial liquidity mints D LP tokens
+func NewStableSwapPool(tokens []string, balances []*big.Int, amp int64) (*StableSwapPool, error) {
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("stableswap pool needs at least 2 tokens")
+	}
+	if len(balances) != len(tokens) {
+		return nil, fmt.Errorf("expected %d balances, got %d", len(tokens), len(balances))
+	}
+	if amp <= 0 {
+		return nil, fmt.Errorf("amplification must be positive")
+	}
+
+	p := &StableSwapPool{
+		tokens: append([]string(nil), tokens...),
+		amp:    big.NewInt(amp),
+		now:    time.Now,
+	}
+	for i, b := range balances {
+		if b.Sign() <= 0 {
+			return nil, fmt.Errorf("balance of %s must be positive", tokens[i])
+		}
+		p.balances = append(p.balances, new(big.Int).Set(b))
+	}
+	p.supply = p.invariant(p.balances)
+	return p, nil
+}
+
+// SetClock replaces the time source of swap events
+func (p *StableSwapPool) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now = now
+}
+
+// TokenList returns all the pool tokens, in index order
+func (p *StableSwapPool) TokenList() []string {
+	return append([]string(nil), p.tokens...)
+}
+
+// Balances returns copies of the token balances
+func (p *StableSwapPool) Balances() []*big.Int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return copyAmounts(p.balances)
+}
+
+// D returns the invariant: the total amount of tokens when the pool is balanced
+func (p *StableSwapPool) D() *big.Int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.invariant(p.balances)
+}
+
+// Supply returns the LP token supply
+func (p *StableSwapPool) Supply() *big.Int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return new(big.Int).Set(p.supply)
+}
+
+// QuoteExchange returns how much of token j selling dx of token i would give
+func (p *StableSwapPool) QuoteExchange(i, j int, dx *big.Int) (*big.Int, error) {
+	if err := p.validateExchange(i, j, dx); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.amountOut(i, j, dx)
+}
+
+// QuoteExchangeExactOut returns how much of token i buying dy of token j
+// would cost
+func (p *StableSwapPool) QuoteExchangeExactOut(i, j int, dy *big.Int) (*big.Int, error) {
+	if err := p.validateExchange(i, j, dy); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.amountIn(i, j, dy)
+}
+
+// Exchange sells dx of token i for token j on behalf of a trader and
+// returns the amount received
+func (p *StableSwapPool) Exchange(trader, i, j int, dx *big.Int) (*big.Int, error) {
+	if err := p.validateExchange(i, j, dx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	dy, err := p.amountOut(i, j, dx)
+	if err != nil {
+		return nil, err
+	}
+	p.apply(trader, i, j, dx, dy)
+	return dy, nil
+}
+
+// ExchangeExactOut buys exactly dy of token j with token i on behalf of a
+// trader and returns the amount paid
+func (p *StableSwapPool) ExchangeExactOut(trader, i, j int, dy *big.Int) (*big.Int, error) {
+	if err := p.validateExchange(i, j, dy); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	dx, err := p.amountIn(i, j, dy)
+	if err != nil {
+		return nil, err
+	}
+	p.apply(trader, i, j, dx, dy)
+	return dx, nil
+}
+
+// apply moves the balances of an exchange and notifies subscribers
+// Called with p.mu held
+func (p *StableSwapPool) apply(trader, i, j int, dx, dy *big.Int) {
+	p.balances[i].Add(p.balances[i], dx)
+	p.balances[j].Sub(p.balances[j], dy)
+
+	if (i == 0 && j == 1) || (i == 1 && j == 0) {
+		direction := AToB
+		if i == 1 {
+			direction = BToA
+		}
+		now := p.now()
+		p.publish(func() SwapEvent {
+			return SwapEvent{
+				Trader:    trader,
+				Direction: direction,
+				AmountIn:  new(big.Int).Set(dx),
+				AmountOut: new(big.Int).Set(dy),
+				ReserveA:  new(big.Int).Set(p.balances[0]),
+				ReserveB:  new(big.Int).Set(p.balances[1]),
+				PriceAInB: p.price(0, 1),
+				Time:      now,
+			}
+		})
+	}
+}
+
+// amountOut computes the exchange output, called with p.mu held
+// One unit is kept by the pool so rounding never favours the trader
+func (p *StableSwapPool) amountOut(i, j int, dx *big.Int) (*big.Int, error) {
+	d := p.invariant(p.balances)
+	x := new(big.Int).Add(p.balances[i], dx)
+	y := p.solveBalance(i, j, x, d)
+
+	dy := new(big.Int).Sub(p.balances[j], y)
+	dy.Sub(dy, big.NewInt(1))
+	if dy.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %s in gives nothing out", ErrInsufficientLiquidity, dx)
+	}
+	return dy, nil
+}
+
+// amountIn computes the input needed for an exact output, called with p.mu
+// held. One unit is added so rounding never favours the trader
+func (p *StableSwapPool) amountIn(i, j int, dy *big.Int) (*big.Int, error) {
+	y := new(big.Int).Sub(p.balances[j], dy)
+	if y.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: cannot buy %s of %s balance", ErrInsufficientLiquidity, dy, p.balances[j])
+	}
+
+	d := p.invariant(p.balances)
+	x := p.solveBalance(j, i, y, d)
+	dx := new(big.Int).Sub(x, p.balances[i])
+	return dx.Add(dx, big.NewInt(1)), nil
+}
+
+// AddLiquidity deposits amounts of every token and returns the LP tokens
+// minted, proportional to the increase of D
+func (p *StableSwapPool) AddLiquidity(amounts []*big.Int) (*big.Int, error) {
+	if len(amounts) != len(p.tokens) {
+		return nil, fmt.Errorf("expected %d amounts, got %d", len(p.tokens), len(amounts))
+	}
+	for _, a := range amounts {
+		if a.Sign() < 0 {
+			return nil, fmt.Errorf("amounts must not be negative")
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d0 := p.invariant(p.balances)
+	balances := copyAmounts(p.balances)
+	for k, a := range amounts {
+		balances[k].Add(balances[k], a)
+	}
+	d1 := p.invariant(balances)
+	if d1.Cmp(d0) <= 0 {
+		return nil, fmt.Errorf("deposit does not add liquidity")
+	}
+
+	minted := new(big.Int).Sub(d1, d0)
+	minted.Mul(minted, p.supply)
+	minted.Quo(minted, d0)
+
+	p.balances = balances
+	p.supply.Add(p.supply, minted)
+	return minted, nil
+}
+
+// RemoveLiquidity burns LP tokens and returns the share of every balance
+func (p *StableSwapPool) RemoveLiquidity(lp *big.Int) ([]*big.Int, error) {
+	if lp.Sign() <= 0 {
+		return nil, fmt.Errorf("lp amount must be positive")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if lp.Cmp(p.supply) >= 0 {
+		return nil, fmt.Errorf("%w: cannot remove %s of %s lp tokens", ErrInsufficientLiquidity, lp, p.supply)
+	}
+
+	amounts := make([]*big.Int, len(p.balances))
+	for k, b := range p.balances {
+		amounts[k] = new(big.Int).Mul(b, lp)
+		amounts[k].Quo(amounts[k], p.supply)
+		b.Sub(b, amounts[k])
+	}
+	p.supply.Sub(p.supply, lp)
+	return amounts, nil
+}
+
+// invariant computes D by Newton iteration:
+//
+//	D = (Ann * S + n * D_P) * D / ((Ann - 1) * D + (n + 1) * D_P)
+//
+// with Ann = A * n and D_P = D^(n+1) / (n^n * prod(x)), as in Curve
+func (p *StableSwapPool) invariant(balances []*big.Int) *big.Int {
+	n := big.NewInt(int64(len(balances)))
+	sum := new(big.Int)
+	for _, x := range balances {
+		sum.Add(sum, x)
+	}
+	if sum.Sign() == 0 {
+		return new(big.Int)
+	}
+
+	ann := new(big.Int).Mul(p.amp, n)
+	d := new(big.Int).Set(sum)
+	annSum := new(big.Int).Mul(ann, sum)
+	one := big.NewInt(1)
+
+	for i := 0; i < stableSwapIterations; i++ {
+		dp := new(big.Int).Set(d)
+		for _, x := range balances {
+			dp.Mul(dp, d)
+			dp.Quo(dp, new(big.Int).Mul(x, n))
+		}
+		prev := d
+
+		num := new(big.Int).Mul(dp, n)
+		num.Add(num, annSum)
+		num.Mul(num, prev)
+
+		den := new(big.Int).Sub(ann, one)
+		den.Mul(den, prev)
+		den.Add(den, new(big.Int).Mul(new(big.Int).Add(n, one), dp))
+
+		d = num.Quo(num, den)
+		if converged(d, prev) {
+			break
+		}
+	}
+	return d
+}
+
+// solveBalance returns the balance of token j keeping D when token i has
+// balance x, by Newton iteration on y^2 + (b - D) * y = c
+func (p *StableSwapPool) solveBalance(i, j int, x, d *big.Int) *big.Int {
+	n := big.NewInt(int64(len(p.balances)))
+	ann := new(big.Int).Mul(p.amp, n)
+
+	c := new(big.Int).Set(d)
+	sum := new(big.Int)
+	for k, balance := range p.balances {
+		if k == j {
+			continue
+		}
+		xk := balance
+		if k == i {
+			xk = x
+		}
+		sum.Add(sum, xk)
+		c.Mul(c, d)
+		c.Quo(c, new(big.Int).Mul(xk, n))
+	}
+	c.Mul(c, d)
+	c.Quo(c, new(big.Int).Mul(ann, n))
+	b := new(big.Int).Quo(d, ann)
+	b.Add(b, sum)
+
+	y := new(big.Int).Set(d)
+	for it := 0; it < stableSwapIterations; it++ {
+		prev := y
+		num := new(big.Int).Mul(prev, prev)
+		num.Add(num, c)
+		den := new(big.Int).Lsh(prev, 1)
+		den.Add(den, b)
+		den.Sub(den, d)
+		y = num.Quo(num, den)
+		if converged(y, prev) {
+			break
+		}
+	}
+	return y
+}
+
+// converged reports whether two iterations are within 1 unit
+func converged(a, b *big.Int) bool {
+	diff := new(big.Int).Sub(a, b)
+	return diff.CmpAbs(big.NewInt(1)) <= 0
+}
+
+// Price returns the spot price of token i in token j: how much j one unit of
+// i is worth for an infinitesimal trade. It is the ratio of the partial
+// derivatives of the invariant, Ann + D^(n+1) / (n^n * prod(x) * x_k)
+func (p *StableSwapPool) Price(i, j int) float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.price(i, j)
+}
+
+func (p *StableSwapPool) price(i, j int) float64 {
+	n := float64(len(p.balances))
+	d := new(big.Float).SetInt(p.invariant(p.balances))
+
+	// D^(n+1) / (n^n * prod(x)), kept as a product of D/(n*x) ratios
+	dp := new(big.Float).Set(d)
+	for _, x := range p.balances {
+		ratio := new(big.Float).Quo(d, new(big.Float).SetInt(x))
+		dp.Mul(dp, ratio.Quo(ratio, big.NewFloat(n)))
+	}
+	ann := new(big.Float).SetInt(new(big.Int).Mul(p.amp, big.NewInt(int64(len(p.balances)))))
+
+	partial := func(k int) *big.Float {
+		term := new(big.Float).Quo(dp, new(big.Float).SetInt(p.balances[k]))
+		return term.Add(term, ann)
+	}
+	result, _ := new(big.Float).Quo(partial(i), partial(j)).Float64()
+	return result
+}
+
+// Tokens returns the first two tokens, the AMM pair
+func (p *StableSwapPool) Tokens() (tokenA, tokenB string) {
+	return p.tokens[0], p.tokens[1]
+}
+
+// pair maps an AMM direction to token indexes
+func pair(direction Direction) (i, j int) {
+	if direction == AToB {
+		return 0, 1
+	}
+	return 1, 0
+}
+
+// Quote quotes an exchange between the first two tokens, see AMM
+func (p *StableSwapPool) Quote(direction Direction, amountIn *big.Int) (*big.Int, error) {
+	i, j := pair(direction)
+	return p.QuoteExchange(i, j, amountIn)
+}
+
+// QuoteExactOut quotes an exact-out exchange between the first two tokens
+func (p *StableSwapPool) QuoteExactOut(direction Direction, amountOut *big.Int) (*big.Int, error) {
+	i, j := pair(direction)
+	return p.QuoteExchangeExactOut(i, j, amountOut)
+}
+
+// Swap exchanges between the first two tokens, see AMM
+func (p *StableSwapPool) Swap(trader int, direction Direction, amountIn *big.Int) (*big.Int, error) {
+	i, j := pair(direction)
+	return p.Exchange(trader, i, j, amountIn)
+}
+
+// SwapExactOut buys exactly amountOut between the first two tokens
+func (p *StableSwapPool) SwapExactOut(trader int, direction Direction, amountOut *big.Int) (*big.Int, error) {
+	i, j := pair(direction)
+	return p.ExchangeExactOut(trader, i, j, amountOut)
+}
+
+// PriceAInB returns the spot price of the first token in the second one
+func (p *StableSwapPool) PriceAInB() float64 {
+	return p.Price(0, 1)
+}
+
+// PriceBInA returns the spot price of the second token in the first one
+func (p *StableSwapPool) PriceBInA() float64 {
+	return p.Price(1, 0)
+}
+
+// Snapshot returns the state of the first two tokens; K is the invariant D
+func (p *StableSwapPool) Snapshot() PoolSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return PoolSnapshot{
+		TokenA:    p.tokens[0],
+		TokenB:    p.tokens[1],
+		ReserveA:  new(big.Int).Set(p.balances[0]),
+		ReserveB:  new(big.Int).Set(p.balances[1]),
+		K:         p.invariant(p.balances),
+		PriceAInB: p.price(0, 1),
+		PriceBInA: p.price(1, 0),
+	}
+}
+
+func (p *StableSwapPool) validateExchange(i, j int, dx *big.Int) error {
+	n := len(p.tokens)
+	if i < 0 || i >= n || j < 0 || j >= n || i == j {
+		return fmt.Errorf("invalid token pair %d -> %d", i, j)
+	}
+	if dx.Sign() <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
+}
+
+func copyAmounts(amounts []*big.Int) []*big.Int {
+	result := make([]*big.Int, len(amounts))
+	for k, a := range amounts {
+		result[k] = new(big.Int).Set(a)
+	}
+	return result
+}
@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func newStablePool(t *testing.T, amp int64, balances ...int64) *StableSwapPool {
+	t.Helper()
+	tokens := []string{"USDC", "USDT", "DAI"}[:len(balances)]
+	amounts := make([]*big.Int, len(balances))
+	for i, b := range balances {
+		amounts[i] = big.NewInt(b)
+	}
+	pool, err := NewStableSwapPool(tokens, amounts, amp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return pool
+}
+
+func TestNewStableSwapPool_Validation(t *testing.T) {
+	one := big.NewInt(1)
+	for name, tc := range map[string]struct {
+		tokens   []string
+		balances []*big.Int
+		amp      int64
+	}{
+		"one token":       {[]string{"USDC"}, []*big.Int{one}, 100},
+		"missing balance": {[]string{"USDC", "USDT"}, []*big.Int{one}, 100},
+		"zero balance":    {[]string{"USDC", "USDT"}, []*big.Int{one, big.NewInt(0)}, 100},
+		"zero amplifier":  {[]string{"USDC", "USDT"}, []*big.Int{one, one}, 0},
+	} {
+		if _, err := NewStableSwapPool(tc.tokens, tc.balances, tc.amp); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestStableSwapPool_Balanced(t *testing.T) {
+	pool := newStablePool(t, 100, 1000000000, 1000000000)
+
+	// D of a balanced pool is the sum of the balances
+	if d := pool.D(); d.Cmp(big.NewInt(2000000000)) != 0 {
+		t.Errorf("expected D = 2000000000, got %s", d)
+	}
+	if price := pool.PriceAInB(); math.Abs(price-1) > 1e-12 {
+		t.Errorf("expected price 1, got %f", price)
+	}
+
+	// selling 1% of the pool: near 1:1, far better than x*y=k
+	dx := big.NewInt(10000000)
+	out, err := pool.Swap(1, AToB, dx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cp := MustNewPool("USDC", "USDT", big.NewInt(1000000000), big.NewInt(1000000000))
+	cpOut, _ := cp.SwapAForB(dx)
+
+	slippage := 1 - float64(out.Int64())/float64(dx.Int64())
+	cpSlippage := 1 - float64(cpOut.Int64())/float64(dx.Int64())
+	if slippage > 0.0001 || slippage >= cpSlippage/50 {
+		t.Errorf("stableswap slippage %.6f should be tiny vs constant product %.6f", slippage, cpSlippage)
+	}
+	if out.Cmp(dx) >= 0 {
+		t.Errorf("swap must not return more than it takes: %s for %s", out, dx)
+	}
+}
+
+func TestStableSwapPool_Amplification(t *testing.T) {
+	// the same imbalanced trade slips more with a lower amplification
+	dx := big.NewInt(300000000)
+	var outs []int64
+	for _, amp := range []int64{1, 10, 100, 1000} {
+		pool := newStablePool(t, amp, 1000000000, 1000000000)
+		out, err := pool.Swap(1, AToB, dx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		outs = append(outs, out.Int64())
+	}
+	for i := 1; i < len(outs); i++ {
+		if outs[i] <= outs[i-1] {
+			t.Errorf("higher amplification should give more out: %v", outs)
+		}
+	}
+}
+
+func TestStableSwapPool_InvariantHolds(t *testing.T) {
+	pool := newStablePool(t, 200, 1000000000, 2000000000, 1500000000)
+	d := pool.D()
+
+	trades := []struct{ i, j, dx int64 }{
+		{0, 1, 50000000}, {2, 0, 700000000}, {1, 2, 1000}, {1, 0, 300000000}, {2, 1, 1},
+	}
+	for _, tr := range trades {
+		out, err := pool.Exchange(1, int(tr.i), int(tr.j), big.NewInt(tr.dx))
+		if err != nil {
+			// dust can round to nothing
+			if tr.dx > 1 {
+				t.Fatalf("unexpected error for %+v: %v", tr, err)
+			}
+			continue
+		}
+		if out.Sign() <= 0 {
+			t.Errorf("expected a positive output for %+v", tr)
+		}
+
+		// rounding only ever leaves value in the pool
+		next := pool.D()
+		if next.Cmp(d) < 0 {
+			t.Errorf("D decreased after %+v: %s -> %s", tr, d, next)
+		}
+		d = next
+	}
+
+	// quoting does not change the pool
+	quote, _ := pool.QuoteExchange(0, 2, big.NewInt(1000000))
+	out, _ := pool.Exchange(1, 0, 2, big.NewInt(1000000))
+	if quote.Cmp(out) != 0 {
+		t.Errorf("quote %s should match the exchange %s", quote, out)
+	}
+	if _, err := pool.QuoteExchange(0, 0, big.NewInt(1)); err == nil {
+		t.Error("expected error exchanging a token for itself")
+	}
+}
+
+func TestStableSwapPool_Price(t *testing.T) {
+	pool := newStablePool(t, 50, 1000000000, 3000000000)
+
+	// the spot price matches a tiny trade
+	price := pool.Price(0, 1)
+	out, _ := pool.QuoteExchange(0, 1, big.NewInt(1000000))
+	if effective := float64(out.Int64()) / 1000000; math.Abs(effective-price)/price > 0.001 {
+		t.Errorf("spot price %f should match a small trade %f", price, effective)
+	}
+	if price <= 1 || math.Abs(price*pool.Price(1, 0)-1) > 1e-9 {
+		t.Errorf("the scarce token should be worth more, and prices be inverse: %f, %f", price, pool.Price(1, 0))
+	}
+}
+
+func TestStableSwapPool_Liquidity(t *testing.T) {
+	pool := newStablePool(t, 100, 1000000, 1000000, 1000000)
+	supply := pool.Supply()
+
+	// a proportional deposit mints a proportional share
+	minted, err := pool.AddLiquidity([]*big.Int{big.NewInt(500000), big.NewInt(500000), big.NewInt(500000)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := new(big.Int).Quo(supply, big.NewInt(2)); new(big.Int).Sub(minted, want).CmpAbs(big.NewInt(1)) > 0 {
+		t.Errorf("expected ~%s lp minted, got %s", want, minted)
+	}
+
+	amounts, err := pool.RemoveLiquidity(minted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for k, a := range amounts {
+		if a.Cmp(big.NewInt(500000)) > 0 || a.Cmp(big.NewInt(499990)) < 0 {
+			t.Errorf("token %d: expected ~500000 back, got %s", k, a)
+		}
+	}
+
+	if _, err := pool.RemoveLiquidity(pool.Supply()); err == nil {
+		t.Error("expected error removing the whole supply")
+	}
+	if _, err := pool.AddLiquidity([]*big.Int{big.NewInt(1)}); err == nil {
+		t.Error("expected error for a wrong number of amounts")
+	}
+}
+
+func TestStableSwapPool_Events(t *testing.T) {
+	pool := newStablePool(t, 100, 1000000, 1000000, 1000000)
+	sub := pool.Subscribe(4)
+
+	pool.Exchange(3, 1, 0, big.NewInt(1000))
+	pool.Exchange(3, 2, 0, big.NewInt(1000)) // not between A and B
+	sub.Close()
+
+	var events []SwapEvent
+	for e := range sub.Events() {
+		events = append(events, e)
+	}
+	if len(events) != 1 || events[0].Direction != BToA || events[0].Trader != 3 {
+		t.Errorf("expected a single B->A event, got %+v", events)
+	}
+}
@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"math"
+	"math/big"
+)
+
+// Tick bounds of a concentrated liquidity pool, as in Uniswap v3
+// A tick i is the price 1.0001^i (TokenB per TokenA)
+const (
+	MinTick = -887272
+	MaxTick = 887272
+)
+
+// q96 is the Q64.96 fixed point unit of sqrt prices
+var q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+
+// tickMathPrec is the big.Float precision used to derive sqrt prices
+const tickMathPrec = 256
+
+// sqrtTickBase is sqrt(1.0001), the sqrt price ratio between two ticks
+var sqrtTickBase = new(big.Float).SetPrec(tickMathPrec).Sqrt(
+	new(big.Float).SetPrec(tickMathPrec).Quo(big.NewFloat(10001).SetPrec(tickMathPrec), big.NewFloat(10000)))
+
+// SqrtPriceAtTick returns sqrt(1.0001^tick) in Q64.96 fixed point, rounded down
+func SqrtPriceAtTick(tick int) *big.Int {
+	n := tick
+	if n < 0 {
+		n = -n
+	}
+
+	// exponentiation by squaring
+	result := new(big.Float).SetPrec(tickMathPrec).SetInt64(1)
+	base := new(big.Float).SetPrec(tickMathPrec).Set(sqrtTickBase)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result.Mul(result, base)
+		}
+		base.Mul(base, base)
+	}
+	if tick < 0 {
+		result.Quo(new(big.Float).SetPrec(tickMathPrec).SetInt64(1), result)
+	}
+
+	result.Mul(result, new(big.Float).SetPrec(tickMathPrec).SetInt(q96))
+	sqrtPrice, _ := result.Int(nil)
+	return sqrtPrice
+}
+
+// TickAtSqrtPrice returns the greatest tick whose sqrt price is <= sqrtPrice
+func TickAtSqrtPrice(sqrtPrice *big.Int) int {
+	// float estimate, then fix the off-by-ones exactly
+	f := sqrtPriceToFloat(sqrtPrice)
+	tick := int(math.Floor(2 * math.Log(f) / math.Log(1.0001)))
+	tick = max(MinTick, min(MaxTick, tick))
+
+	for tick < MaxTick && SqrtPriceAtTick(tick+1).Cmp(sqrtPrice) <= 0 {
+		tick++
+	}
+	for tick > MinTick && SqrtPriceAtTick(tick).Cmp(sqrtPrice) > 0 {
+		tick--
+	}
+	return tick
+}
+
+// SqrtPriceFromPrice converts a price of A in B to Q64.96, rounded down
+func SqrtPriceFromPrice(price float64) *big.Int {
+	f := new(big.Float).SetPrec(tickMathPrec).SetFloat64(price)
+	f.Sqrt(f)
+	f.Mul(f, new(big.Float).SetPrec(tickMathPrec).SetInt(q96))
+	sqrtPrice, _ := f.Int(nil)
+	return sqrtPrice
+}
+
+// PriceFromSqrtPrice converts a Q64.96 sqrt price to a price of A in B
+func PriceFromSqrtPrice(sqrtPrice *big.Int) float64 {
+	f := sqrtPriceToFloat(sqrtPrice)
+	return f * f
+}
+
+func sqrtPriceToFloat(sqrtPrice *big.Int) float64 {
+	f := new(big.Float).SetPrec(tickMathPrec).SetInt(sqrtPrice)
+	f.Quo(f, new(big.Float).SetPrec(tickMathPrec).SetInt(q96))
+	result, _ := f.Float64()
+	return result
+}
+
+// amountADelta returns the TokenA amount between two sqrt prices for
+// liquidity: L * (sqrtB - sqrtA) / (sqrtA * sqrtB), with sqrtA <= sqrtB
+func amountADelta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+	num := new(big.Int).Lsh(liquidity, 96)
+	num.Mul(num, new(big.Int).Sub(sqrtB, sqrtA))
+	if roundUp {
+		return divUp(divUp(num, sqrtB), sqrtA)
+	}
+	num.Quo(num, sqrtB)
+	return num.Quo(num, sqrtA)
+}
+
+// amountBDelta returns the TokenB amount between two sqrt prices for
+// liquidity: L * (sqrtB - sqrtA), with sqrtA <= sqrtB
+func amountBDelta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+	num := new(big.Int).Mul(liquidity, new(big.Int).Sub(sqrtB, sqrtA))
+	if roundUp {
+		return divUp(num, q96)
+	}
+	return num.Rsh(num, 96)
+}
+
+// nextSqrtPriceFromAmountA returns the sqrt price after adding amountIn of
+// TokenA: L * sqrtP / (L + amountIn * sqrtP), rounded up so the price never
+// moves further than the input pays for
+func nextSqrtPriceFromAmountA(sqrtPrice, liquidity, amountIn *big.Int) *big.Int {
+	num := new(big.Int).Lsh(liquidity, 96)
+	den := new(big.Int).Mul(amountIn, sqrtPrice)
+	den.Add(den, num)
+	return divUp(num.Mul(num, sqrtPrice), den)
+}
+
+// nextSqrtPriceFromAmountB returns the sqrt price after adding amountIn of
+// TokenB: sqrtP + amountIn / L, rounded down
+func nextSqrtPriceFromAmountB(sqrtPrice, liquidity, amountIn *big.Int) *big.Int {
+	delta := new(big.Int).Lsh(amountIn, 96)
+	delta.Quo(delta, liquidity)
+	return delta.Add(delta, sqrtPrice)
+}
+
+// nextSqrtPriceFromOutputA returns the sqrt price after removing amountOut
+// of TokenA: L * sqrtP / (L - amountOut * sqrtP), rounded up so the price
+// moves at least as far as the output requires
+func nextSqrtPriceFromOutputA(sqrtPrice, liquidity, amountOut *big.Int) *big.Int {
+	num := new(big.Int).Lsh(liquidity, 96)
+	den := new(big.Int).Mul(amountOut, sqrtPrice)
+	den.Sub(num, den)
+	return divUp(num.Mul(num, sqrtPrice), den)
+}
+
+// nextSqrtPriceFromOutputB returns the sqrt price after removing amountOut
+// of TokenB: sqrtP - amountOut / L, rounded down
+func nextSqrtPriceFromOutputB(sqrtPrice, liquidity, amountOut *big.Int) *big.Int {
+	delta := divUp(new(big.Int).Lsh(amountOut, 96), liquidity)
+	return delta.Sub(sqrtPrice, delta)
+}
+
+// divUp returns ceil(x / y) for non-negative x and positive y
+func divUp(x, y *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+	if r.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// LiquidityForAmounts returns the largest liquidity that amountA and amountB
+// can provide over [sqrtLower, sqrtUpper] at the current sqrt price
+func LiquidityForAmounts(sqrtPrice, sqrtLower, sqrtUpper, amountA, amountB *big.Int) *big.Int {
+	// liquidity for an amount of A: amountA * sqrtA * sqrtB / (sqrtB - sqrtA)
+	forA := func(sqrtA, sqrtB *big.Int) *big.Int {
+		num := new(big.Int).Mul(amountA, sqrtA)
+		num.Mul(num, sqrtB)
+		num.Rsh(num, 96)
+		return num.Quo(num, new(big.Int).Sub(sqrtB, sqrtA))
+	}
+	// liquidity for an amount of B: amountB / (sqrtB - sqrtA)
+	forB := func(sqrtA, sqrtB *big.Int) *big.Int {
+		num := new(big.Int).Lsh(amountB, 96)
+		return num.Quo(num, new(big.Int).Sub(sqrtB, sqrtA))
+	}
+
+	switch {
+	case sqrtPrice.Cmp(sqrtLower) <= 0:
+		return forA(sqrtLower, sqrtUpper)
+	case sqrtPrice.Cmp(sqrtUpper) >= 0:
+		return forB(sqrtLower, sqrtUpper)
+	default:
+		la, lb := forA(sqrtPrice, sqrtUpper), forB(sqrtLower, sqrtPrice)
+		if la.Cmp(lb) < 0 {
+			return la
+		}
+		return lb
+	}
+}
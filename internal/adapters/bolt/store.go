@@ -0,0 +1,159 @@
+package bolt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nexus-bot-swarm/ports"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	txBucket   = []byte("txs")    // id -> json StoredTx
+	hashBucket = []byte("hashes") // hash -> id
+)
+
+// Store implements ports.TxStore on an embedded bbolt database
+// Writes are synced to disk, so history survives crashes and restarts
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (or creates) the database file, creating parent directories
+func Open(path string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create db dir: %w", err)
+		}
+	}
+
+	// the timeout avoids hanging forever if another process holds the file lock
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tx store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{txBucket, hashBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init tx store: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// SaveTx stores a new submission and sets its ID
+func (s *Store) SaveTx(stored *ports.StoredTx) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		txs := tx.Bucket(txBucket)
+		id, err := txs.NextSequence()
+		if err != nil {
+			return err
+		}
+		stored.ID = id
+
+		if err := put(txs, id, stored); err != nil {
+			return err
+		}
+		if stored.Hash != "" {
+			return tx.Bucket(hashBucket).Put([]byte(stored.Hash), itob(id))
+		}
+		return nil
+	})
+}
+
+// UpdateTx applies fn to the stored tx with the given hash
+func (s *Store) UpdateTx(hash string, fn func(*ports.StoredTx)) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		idBytes := tx.Bucket(hashBucket).Get([]byte(hash))
+		if idBytes == nil {
+			return fmt.Errorf("tx %s: %w", hash, ports.ErrTxNotFound)
+		}
+
+		txs := tx.Bucket(txBucket)
+		var stored ports.StoredTx
+		if err := json.Unmarshal(txs.Get(idBytes), &stored); err != nil {
+			return fmt.Errorf("failed to decode tx %s: %w", hash, err)
+		}
+
+		fn(&stored)
+		return put(txs, stored.ID, &stored)
+	})
+}
+
+// Txs returns matching transactions ordered by ID
+// With a Limit, the most recent matches are returned (still in ID order)
+func (s *Store) Txs(filter ports.TxFilter) ([]ports.StoredTx, error) {
+	var result []ports.StoredTx
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// walk backwards so Limit keeps the most recent txs
+		c := tx.Bucket(txBucket).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var stored ports.StoredTx
+			if err := json.Unmarshal(v, &stored); err != nil {
+				return fmt.Errorf("failed to decode tx %d: %w", btoi(k), err)
+			}
+			if !matches(&stored, filter) {
+				continue
+			}
+			result = append(result, stored)
+			if filter.Limit > 0 && len(result) == filter.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// matches reports whether tx passes the filter
+// Addresses are compared case-insensitively: checksummed and lowercase forms
+// of the same wallet must match, or nonce recovery would miss its txs
+func matches(tx *ports.StoredTx, f ports.TxFilter) bool {
+	return (f.BotID == 0 || tx.BotID == f.BotID) &&
+		(f.From == "" || strings.EqualFold(tx.From, f.From)) &&
+		(f.Status == "" || tx.Status == f.Status)
+}
+
+func put(b *bolt.Bucket, id uint64, stored *ports.StoredTx) error {
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return b.Put(itob(id), data)
+}
+
+// itob encodes ids big-endian so keys sort in insertion order
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func btoi(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
+}
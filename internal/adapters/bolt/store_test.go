@@ -0,0 +1,122 @@
+package bolt
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nexus-bot-swarm/ports"
+)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "data", "txs.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	return store, path
+}
+
+func TestStore_SaveUpdate(t *testing.T) {
+	store, _ := openTestStore(t)
+	defer store.Close()
+
+	tx := &ports.StoredTx{BotID: 1, Nonce: 7, Hash: "0xabc", Amount: big.NewInt(1), Status: ports.TxPending, SentAt: time.Now()}
+	if err := store.SaveTx(tx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.ID != 1 {
+		t.Errorf("expected ID 1, got %d", tx.ID)
+	}
+
+	err := store.UpdateTx("0xabc", func(stored *ports.StoredTx) {
+		stored.Status = ports.TxConfirmed
+		stored.BlockNumber = 42
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	txs, err := store.Txs(ports.TxFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(txs) != 1 || txs[0].Status != ports.TxConfirmed || txs[0].BlockNumber != 42 || txs[0].Amount.Int64() != 1 {
+		t.Errorf("unexpected stored txs: %+v", txs)
+	}
+
+	if err := store.UpdateTx("0xmissing", func(*ports.StoredTx) {}); !errors.Is(err, ports.ErrTxNotFound) {
+		t.Errorf("expected ErrTxNotFound, got %v", err)
+	}
+}
+
+func TestStore_Txs_Filter(t *testing.T) {
+	store, _ := openTestStore(t)
+	defer store.Close()
+
+	for i, status := range []ports.TxStatus{ports.TxPending, ports.TxFailed, ports.TxPending, ports.TxConfirmed} {
+		tx := &ports.StoredTx{BotID: i%2 + 1, Nonce: uint64(i), From: "0xAbC", Status: status}
+		if status != ports.TxFailed {
+			tx.Hash = "0x" + string(rune('a'+i))
+		}
+		if err := store.SaveTx(tx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	pending, _ := store.Txs(ports.TxFilter{Status: ports.TxPending})
+	if len(pending) != 2 || pending[0].Nonce != 0 || pending[1].Nonce != 2 {
+		t.Errorf("expected 2 pending txs in order, got %+v", pending)
+	}
+
+	bot2, _ := store.Txs(ports.TxFilter{BotID: 2})
+	if len(bot2) != 2 {
+		t.Errorf("expected 2 txs of bot 2, got %d", len(bot2))
+	}
+
+	latest, _ := store.Txs(ports.TxFilter{Limit: 2})
+	if len(latest) != 2 || latest[0].Nonce != 2 || latest[1].Nonce != 3 {
+		t.Errorf("expected the 2 most recent txs in order, got %+v", latest)
+	}
+
+	// the checksum casing of the address does not matter
+	wallet, _ := store.Txs(ports.TxFilter{From: "0xabc", Status: ports.TxPending})
+	if len(wallet) != 2 {
+		t.Errorf("expected 2 pending txs of the wallet in another case, got %d", len(wallet))
+	}
+
+	none, _ := store.Txs(ports.TxFilter{From: "0xother"})
+	if len(none) != 0 {
+		t.Errorf("expected no txs from another wallet, got %d", len(none))
+	}
+}
+
+func TestStore_SurvivesReopen(t *testing.T) {
+	store, path := openTestStore(t)
+	if err := store.SaveTx(&ports.StoredTx{Hash: "0xabc", Status: ports.TxPending}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.Close()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer store.Close()
+
+	txs, _ := store.Txs(ports.TxFilter{})
+	if len(txs) != 1 || txs[0].Hash != "0xabc" {
+		t.Errorf("expected the tx to survive a reopen, got %+v", txs)
+	}
+
+	// ids keep increasing after a reopen
+	next := &ports.StoredTx{Hash: "0xdef"}
+	store.SaveTx(next)
+	if next.ID != 2 {
+		t.Errorf("expected ID 2, got %d", next.ID)
+	}
+}
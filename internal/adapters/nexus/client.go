@@ -3,22 +3,28 @@ package nexus
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/ports"
 )
 
 // Client implements ports.BlockchainClient for Nexus testnet
 type Client struct {
 	rpcURL          string
+	fallbackURLs    []string
 	expectedChainID int64
 	client          *ethclient.Client
 	chainID         *big.Int
+	connectedURL    string
 }
 
 // NewClient creates a new Nexus client (does not connect yet)
@@ -29,30 +35,59 @@ func NewClient(rpcURL string, expectedChainID int64) *Client {
 	}
 }
 
+// SetFallbackURLs sets RPC endpoints tried in order when the main one
+// cannot be reached or serves another chain
+// Must be called before Connect
+func (c *Client) SetFallbackURLs(urls ...string) {
+	c.fallbackURLs = urls
+}
+
 // Connect establishes connection to Nexus RPC and validates chain ID
+// The fallback URLs are tried in order until one connects
 func (c *Client) Connect(ctx context.Context) error {
-	client, err := ethclient.DialContext(ctx, c.rpcURL)
+	var errs []error
+	for _, url := range append([]string{c.rpcURL}, c.fallbackURLs...) {
+		err := c.connect(ctx, url)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Client) connect(ctx context.Context, url string) error {
+	client, err := ethclient.DialContext(ctx, url)
 	if err != nil {
-		return fmt.Errorf("failed to connect to RPC %s: %w", c.rpcURL, err)
+		return fmt.Errorf("failed to connect to RPC %s: %w", url, err)
 	}
 
 	// Validate chain ID as requested - fail fast if wrong network
 	chainID, err := client.ChainID(ctx)
 	if err != nil {
 		client.Close()
-		return fmt.Errorf("failed to get chain ID: %w", err)
+		return fmt.Errorf("failed to get chain ID from %s: %w", url, err)
 	}
 
 	if chainID.Int64() != c.expectedChainID {
 		client.Close()
-		return fmt.Errorf("chain ID mismatch: expected %d, got %d", c.expectedChainID, chainID.Int64())
+		return fmt.Errorf("chain ID mismatch on %s: expected %d, got %d", url, c.expectedChainID, chainID.Int64())
 	}
 
 	c.client = client
 	c.chainID = chainID
+	c.connectedURL = url
 	return nil
 }
 
+// URL returns the RPC endpoint the client is connected to
+func (c *Client) URL() string {
+	return c.connectedURL
+}
+
 // ChainID returns the connected chain's ID
 func (c *Client) ChainID() *big.Int {
 	return c.chainID
@@ -105,32 +140,36 @@ func (c *Client) SendETH(ctx context.Context, privateKeyHex string, to string, a
 		return "", fmt.Errorf("failed to get nonce: %w", err)
 	}
 
-	return c.SendETHWithNonce(ctx, privateKeyHex, to, amount, nonce)
+	sent, err := c.SendETHWithNonce(ctx, privateKeyHex, to, amount, nonce)
+	if err != nil {
+		return "", err
+	}
+	return sent.Hash, nil
 }
 
 // SendETHWithNonce sends native currency with a specific nonce
 // Use this for concurrent bots with a shared nonce manager
-func (c *Client) SendETHWithNonce(ctx context.Context, privateKeyHex string, to string, amount *big.Int, nonce uint64) (string, error) {
+func (c *Client) SendETHWithNonce(ctx context.Context, privateKeyHex string, to string, amount *big.Int, nonce uint64) (*ports.SentTx, error) {
 	if c.client == nil {
-		return "", fmt.Errorf("client not connected")
+		return nil, fmt.Errorf("client not connected")
 	}
 
 	// parse private key
 	privateKey, err := crypto.HexToECDSA(privateKeyHex)
 	if err != nil {
-		return "", fmt.Errorf("invalid private key: %w", err)
+		return nil, fmt.Errorf("invalid private key: %w", err)
 	}
 
 	// validate to address
 	if !common.IsHexAddress(to) {
-		return "", fmt.Errorf("invalid to address: %s", to)
+		return nil, fmt.Errorf("invalid to address: %s", to)
 	}
 	toAddress := common.HexToAddress(to)
 
 	// estimate gas price
 	gasPrice, err := c.client.SuggestGasPrice(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %w", err)
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
 	}
 
 	// gas limit for simple transfer
@@ -142,16 +181,16 @@ func (c *Client) SendETHWithNonce(ctx context.Context, privateKeyHex string, to
 	// sign transaction
 	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(c.chainID), privateKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign tx: %w", err)
+		return nil, fmt.Errorf("failed to sign tx: %w", err)
 	}
 
 	// send transaction
 	err = c.client.SendTransaction(ctx, signedTx)
 	if err != nil {
-		return "", fmt.Errorf("failed to send tx: %w", err)
+		return nil, fmt.Errorf("failed to send tx: %w", err)
 	}
 
-	return signedTx.Hash().Hex(), nil
+	return &ports.SentTx{Hash: signedTx.Hash().Hex(), GasPrice: gasPrice}, nil
 }
 
 // GetNonce returns the current pending nonce for an address
@@ -165,6 +204,36 @@ func (c *Client) GetNonce(ctx context.Context, address string) (uint64, error) {
 	return c.client.PendingNonceAt(ctx, common.HexToAddress(address))
 }
 
+// TransactionReceipt returns the receipt of a mined transaction
+// Returns ports.ErrReceiptNotFound while the tx is pending
+func (c *Client) TransactionReceipt(ctx context.Context, txHash string) (*ports.Receipt, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	receipt, err := c.client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, ports.ErrReceiptNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	return &ports.Receipt{
+		TxHash:            receipt.TxHash.Hex(),
+		Status:            receipt.Status,
+		BlockNumber:       receipt.BlockNumber.Uint64(),
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+	}, nil
+}
+
+// Backend returns the underlying RPC client for contract bindings
+// (e.g. the on-chain pool adapter), nil before Connect
+func (c *Client) Backend() *ethclient.Client {
+	return c.client
+}
+
 // Close gracefully closes the RPC connection
 func (c *Client) Close() {
 	if c.client != nil {
@@ -212,21 +281,76 @@ func (c *Client) TokenBalance(ctx context.Context, tokenAddress string, walletAd
 	return balance, nil
 }
 
+// TokenInfo reads the name, symbol and decimals of an ERC20 token
+func (c *Client) TokenInfo(ctx context.Context, tokenAddress string) (domain.Token, error) {
+	if c.client == nil {
+		return domain.Token{}, fmt.Errorf("client not connected")
+	}
+	if !common.IsHexAddress(tokenAddress) {
+		return domain.Token{}, fmt.Errorf("invalid address")
+	}
+	token := common.HexToAddress(tokenAddress)
+
+	// name() selector = 0x06fdde03, symbol() selector = 0x95d89b41, decimals() selector = 0x313ce567
+	name, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: []byte{0x06, 0xfd, 0xde, 0x03}}, nil)
+	if err != nil {
+		return domain.Token{}, fmt.Errorf("failed to call name: %w", err)
+	}
+	symbol, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: []byte{0x95, 0xd8, 0x9b, 0x41}}, nil)
+	if err != nil {
+		return domain.Token{}, fmt.Errorf("failed to call symbol: %w", err)
+	}
+	decimals, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: []byte{0x31, 0x3c, 0xe5, 0x67}}, nil)
+	if err != nil {
+		return domain.Token{}, fmt.Errorf("failed to call decimals: %w", err)
+	}
+	info, err := decodeTokenInfo(name, symbol, decimals)
+	if err != nil {
+		return domain.Token{}, err
+	}
+	info.Address = token.Hex()
+	return info, nil
+}
+
+// decodeTokenInfo parses the results of name() and symbol() (string) and
+// decimals() (uint8)
+func decodeTokenInfo(name, symbol, decimals []byte) (domain.Token, error) {
+	stringType, _ := abi.NewType("string", "", nil)
+	names, err := abi.Arguments{{Type: stringType}}.Unpack(name)
+	if err != nil {
+		return domain.Token{}, fmt.Errorf("invalid name result: %w", err)
+	}
+	symbols, err := abi.Arguments{{Type: stringType}}.Unpack(symbol)
+	if err != nil {
+		return domain.Token{}, fmt.Errorf("invalid symbol result: %w", err)
+	}
+
+	// uint8 is returned as a 32 byte word
+	if len(decimals) != 32 {
+		return domain.Token{}, fmt.Errorf("invalid decimals result: %d bytes", len(decimals))
+	}
+	n := new(big.Int).SetBytes(decimals)
+	if n.Cmp(big.NewInt(domain.MaxDecimals)) > 0 {
+		return domain.Token{}, fmt.Errorf("token has %s decimals, more than %d", n, domain.MaxDecimals)
+	}
+	return domain.Token{Symbol: symbols[0].(string), Decimals: uint8(n.Uint64()), Name: names[0].(string)}, nil
+}
+
 // TransferToken sends ERC20 tokens to an address
-func (c *Client) TransferToken(ctx context.Context, tokenAddress string, privateKeyHex string, to string, amount *big.Int, nonce uint64) (string, error) {
+func (c *Client) TransferToken(ctx context.Context, tokenAddress string, privateKeyHex string, to string, amount *big.Int, nonce uint64) (*ports.SentTx, error) {
 	if c.client == nil {
-		return "", fmt.Errorf("client not connected")
+		return nil, fmt.Errorf("client not connected")
 	}
 
 	// parse private key
 	privateKey, err := crypto.HexToECDSA(privateKeyHex)
 	if err != nil {
-		return "", fmt.Errorf("invalid private key: %w", err)
+		return nil, fmt.Errorf("invalid private key: %w", err)
 	}
 
 	// validate addresses
 	if !common.IsHexAddress(tokenAddress) || !common.IsHexAddress(to) {
-		return "", fmt.Errorf("invalid address")
+		return nil, fmt.Errorf("invalid address")
 	}
 
 	token := common.HexToAddress(tokenAddress)
@@ -246,7 +370,7 @@ func (c *Client) TransferToken(ctx context.Context, tokenAddress string, private
 	// estimate gas price
 	gasPrice, err := c.client.SuggestGasPrice(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %w", err)
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
 	}
 
 	// gas limit for token transfer (higher than simple ETH transfer)
@@ -258,14 +382,14 @@ func (c *Client) TransferToken(ctx context.Context, tokenAddress string, private
 	// sign transaction
 	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(c.chainID), privateKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign tx: %w", err)
+		return nil, fmt.Errorf("failed to sign tx: %w", err)
 	}
 
 	// send transaction
 	err = c.client.SendTransaction(ctx, signedTx)
 	if err != nil {
-		return "", fmt.Errorf("failed to send tx: %w", err)
+		return nil, fmt.Errorf("failed to send tx: %w", err)
 	}
 
-	return signedTx.Hash().Hex(), nil
+	return &ports.SentTx{Hash: signedTx.Hash().Hex(), GasPrice: gasPrice}, nil
 }
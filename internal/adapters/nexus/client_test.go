@@ -2,10 +2,18 @@ package nexus
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func TestClient_Connect_Success(t *testing.T) {
@@ -64,6 +72,47 @@ func TestClient_Connect_InvalidURL(t *testing.T) {
 	}
 }
 
+// chainIDServer is an RPC endpoint answering eth_chainId only
+func chainIDServer(t *testing.T, chainID int64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x%x"}`, req.ID, chainID)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_Connect_Fallback(t *testing.T) {
+	wrongChain := chainIDServer(t, 1)
+	good := chainIDServer(t, 3945)
+
+	client := NewClient("http://127.0.0.1:1", 3945)
+	client.SetFallbackURLs(wrongChain.URL, good.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("expected the last endpoint to connect: %v", err)
+	}
+	defer client.Close()
+	if client.URL() != good.URL || client.ChainID().Int64() != 3945 {
+		t.Errorf("expected %s on chain 3945, got %s on %d", good.URL, client.URL(), client.ChainID().Int64())
+	}
+
+	// every endpoint fails: all the errors are reported
+	client = NewClient("http://127.0.0.1:1", 3945)
+	client.SetFallbackURLs(wrongChain.URL)
+	err := client.Connect(ctx)
+	if err == nil || !strings.Contains(err.Error(), "127.0.0.1:1") || !strings.Contains(err.Error(), "chain ID mismatch") {
+		t.Errorf("expected both endpoint errors, got %v", err)
+	}
+}
+
 func TestClient_BlockNumber(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
@@ -207,3 +256,31 @@ func TestClient_SendETH_InvalidPrivateKey(t *testing.T) {
 		t.Fatal("expected error for invalid private key")
 	}
 }
+
+func TestDecodeTokenInfo(t *testing.T) {
+	stringType, _ := abi.NewType("string", "", nil)
+	name, _ := abi.Arguments{{Type: stringType}}.Pack("USD Coin")
+	symbol, _ := abi.Arguments{{Type: stringType}}.Pack("USDC")
+	decimals := common.LeftPadBytes([]byte{6}, 32)
+
+	token, err := decodeTokenInfo(name, symbol, decimals)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.Symbol != "USDC" || token.Decimals != 6 || token.Name != "USD Coin" {
+		t.Errorf("expected USD Coin (USDC) with 6 decimals, got %+v", token)
+	}
+
+	if _, err := decodeTokenInfo(name, symbol, decimals[:31]); err == nil {
+		t.Error("expected error for a short decimals result")
+	}
+	if _, err := decodeTokenInfo(name, symbol, common.LeftPadBytes([]byte{1, 0}, 32)); err == nil {
+		t.Error("expected error for 256 decimals")
+	}
+	if _, err := decodeTokenInfo(name, []byte{1, 2, 3}, decimals); err == nil {
+		t.Error("expected error for an invalid symbol result")
+	}
+	if _, err := decodeTokenInfo([]byte{1, 2, 3}, symbol, decimals); err == nil {
+		t.Error("expected error for an invalid name result")
+	}
+}
@@ -0,0 +1,59 @@
+package uniswap
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// Minimal ABIs of the UniswapV2 contracts, only the methods the adapter uses
+
+const pairABIJSON = `[
+	{"type":"function","name":"token0","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"token1","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"getReserves","stateMutability":"view","inputs":[],"outputs":[
+		{"name":"reserve0","type":"uint112"},{"name":"reserve1","type":"uint112"},{"name":"blockTimestampLast","type":"uint32"}]},
+	{"type":"event","name":"Swap","anonymous":false,"inputs":[
+		{"name":"sender","type":"address","indexed":true},
+		{"name":"amount0In","type":"uint256","indexed":false},
+		{"name":"amount1In","type":"uint256","indexed":false},
+		{"name":"amount0Out","type":"uint256","indexed":false},
+		{"name":"amount1Out","type":"uint256","indexed":false},
+		{"name":"to","type":"address","indexed":true}]}
+]`
+
+const routerABIJSON = `[
+	{"type":"function","name":"getAmountsOut","stateMutability":"view","inputs":[
+		{"name":"amountIn","type":"uint256"},{"name":"path","type":"address[]"}],"outputs":[{"name":"amounts","type":"uint256[]"}]},
+	{"type":"function","name":"getAmountsIn","stateMutability":"view","inputs":[
+		{"name":"amountOut","type":"uint256"},{"name":"path","type":"address[]"}],"outputs":[{"name":"amounts","type":"uint256[]"}]},
+	{"type":"function","name":"swapExactTokensForTokens","stateMutability":"nonpayable","inputs":[
+		{"name":"amountIn","type":"uint256"},{"name":"amountOutMin","type":"uint256"},{"name":"path","type":"address[]"},
+		{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"outputs":[{"name":"amounts","type":"uint256[]"}]},
+	{"type":"function","name":"swapTokensForExactTokens","stateMutability":"nonpayable","inputs":[
+		{"name":"amountOut","type":"uint256"},{"name":"amountInMax","type":"uint256"},{"name":"path","type":"address[]"},
+		{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}],"outputs":[{"name":"amounts","type":"uint256[]"}]}
+]`
+
+const erc20ABIJSON = `[
+	{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"type":"function","name":"allowance","stateMutability":"view","inputs":[
+		{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"approve","stateMutability":"nonpayable","inputs":[
+		{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+var (
+	pairABI   = mustParseABI(pairABIJSON)
+	routerABI = mustParseABI(routerABIJSON)
+	erc20ABI  = mustParseABI(erc20ABIJSON)
+)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic("uniswap: invalid ABI: " + err.Error())
+	}
+	return parsed
+}
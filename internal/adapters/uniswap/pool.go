@@ -0,0 +1,715 @@
+package uniswap
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/internal/nonce"
+	"github.com/nexus-bot-swarm/ports"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+const (
+	// DefaultSlippageBps is the price move tolerated between quote and swap
+	DefaultSlippageBps = 50 // 0.5%
+
+	// DefaultTimeout bounds a read, or a swap from send to receipt
+	DefaultTimeout = 2 * time.Minute
+
+	// DefaultRefreshInterval is how often Run rereads the pair reserves
+	DefaultRefreshInterval = 15 * time.Second
+)
+
+// ErrReverted is returned when an approval or swap transaction is mined
+// with a failed status, e.g. when the price moved beyond the slippage
+var ErrReverted = errors.New("transaction reverted")
+
+// maxUint256 is the allowance granted to the router, so each token is
+// approved only once
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// Backend is what the adapter needs from an RPC client: contract calls,
+// transactions and receipts. *ethclient.Client implements it
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Pool implements domain.AMM against a deployed UniswapV2-compatible pair,
+// trading through its router from a single wallet
+// Quotes are eth_calls; swaps send a transaction and block until it is
+// mined. Prices and snapshots come from reserves cached at startup, after
+// each swap and by Run, so observers never wait on the RPC. Swaps are sent
+// one at a time, so approvals and nonces never race, and their receipts
+// are waited for concurrently
+type Pool struct {
+	backend Backend
+	router  *bind.BoundContract
+	pair    *bind.BoundContract
+
+	routerAddress common.Address
+	pairAddress   common.Address
+	tokenA        common.Address
+	tokenB        common.Address
+	tokens        map[common.Address]*bind.BoundContract
+	infoA         domain.Token
+	infoB         domain.Token
+	aIsToken0     bool
+
+	key     *ecdsa.PrivateKey
+	from    common.Address
+	chainID *big.Int
+
+	nonces          *nonce.Manager
+	slippageBps     int64
+	timeout         time.Duration
+	refreshInterval time.Duration
+	logger          *slog.Logger
+	observer        swarm.Observer
+	now             func() time.Time
+
+	swapMu   sync.Mutex // one swap sent at a time
+	mu       sync.Mutex // guards the fields below
+	reserveA *big.Int   // last reserves read, served by Snapshot
+	reserveB *big.Int
+	approved map[common.Address]bool
+
+	events domain.SwapFeed
+}
+
+// NewPool connects to a pair and its router. tokenAAddress picks which pair
+// token is TokenA; the other one is TokenB. Token symbols and reserves are
+// read from the chain, so a wrong address fails here rather than on a swap
+func NewPool(ctx context.Context, backend Backend, routerAddress, pairAddress, tokenAAddress, privateKeyHex string) (*Pool, error) {
+	for _, addr := range []string{routerAddress, pairAddress, tokenAAddress} {
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid address: %q", addr)
+		}
+	}
+	key, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	p := &Pool{
+		backend:         backend,
+		routerAddress:   common.HexToAddress(routerAddress),
+		pairAddress:     common.HexToAddress(pairAddress),
+		tokenA:          common.HexToAddress(tokenAAddress),
+		tokens:          make(map[common.Address]*bind.BoundContract),
+		key:             key,
+		from:            crypto.PubkeyToAddress(key.PublicKey),
+		chainID:         chainID,
+		slippageBps:     DefaultSlippageBps,
+		timeout:         DefaultTimeout,
+		refreshInterval: DefaultRefreshInterval,
+		logger:          slog.Default(),
+		observer:        swarm.NopObserver{},
+		now:             time.Now,
+		approved:        make(map[common.Address]bool),
+	}
+	p.router = bind.NewBoundContract(p.routerAddress, routerABI, backend, backend, backend)
+	p.pair = bind.NewBoundContract(p.pairAddress, pairABI, backend, backend, backend)
+
+	token0, err := p.callAddress(ctx, "token0")
+	if err != nil {
+		return nil, err
+	}
+	token1, err := p.callAddress(ctx, "token1")
+	if err != nil {
+		return nil, err
+	}
+	switch p.tokenA {
+	case token0:
+		p.aIsToken0, p.tokenB = true, token1
+	case token1:
+		p.tokenB = token0
+	default:
+		return nil, fmt.Errorf("token %s is not in pair %s (%s, %s)", p.tokenA.Hex(), p.pairAddress.Hex(), token0.Hex(), token1.Hex())
+	}
+
+	for _, token := range []common.Address{p.tokenA, p.tokenB} {
+		p.tokens[token] = bind.NewBoundContract(token, erc20ABI, backend, backend, backend)
+	}
+	if p.infoA, err = p.tokenInfo(ctx, p.tokenA); err != nil {
+		return nil, err
+	}
+	if p.infoB, err = p.tokenInfo(ctx, p.tokenB); err != nil {
+		return nil, err
+	}
+
+	if _, _, err := p.reserves(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetNonceManager makes swaps take their nonces from a manager shared with
+// other senders of the same wallet (e.g. the bots)
+// Must be called before the first swap
+func (p *Pool) SetNonceManager(m *nonce.Manager) {
+	p.nonces = m
+}
+
+// SetSlippage sets the tolerated price move in basis points (50 = 0.5%)
+// Must be called before the first swap
+func (p *Pool) SetSlippage(bps int64) {
+	p.slippageBps = bps
+}
+
+// SetTimeout bounds reads, and swaps from send to receipt
+// Must be called before the first swap
+func (p *Pool) SetTimeout(d time.Duration) {
+	p.timeout = d
+}
+
+// SetRefreshInterval sets how often Run rereads the pair reserves
+// Must be called before Run
+func (p *Pool) SetRefreshInterval(d time.Duration) {
+	p.refreshInterval = d
+}
+
+// SetObserver reports the approval and swap transactions to o, like the
+// transfers of the bots: sent, failed, then mined. The trader of a swap is
+// the bot ID of the transaction
+// Must be called before the first swap
+func (p *Pool) SetObserver(o swarm.Observer) {
+	p.observer = o
+}
+
+// SetLogger replaces the logger used to report failed reads
+func (p *Pool) SetLogger(logger *slog.Logger) {
+	p.logger = logger
+}
+
+// SetClock replaces the time source of swap events
+func (p *Pool) SetClock(now func() time.Time) {
+	p.now = now
+}
+
+// Address returns the pair contract address
+func (p *Pool) Address() string {
+	return p.pairAddress.Hex()
+}
+
+// Tokens returns the token symbols, see domain.AMM
+func (p *Pool) Tokens() (tokenA, tokenB string) {
+	return p.infoA.Symbol, p.infoB.Symbol
+}
+
+// TokenInfo returns the symbols, decimals and addresses of the pair tokens
+func (p *Pool) TokenInfo() (tokenA, tokenB domain.Token) {
+	return p.infoA, p.infoB
+}
+
+// Quote returns what selling amountIn would give, from the router
+func (p *Pool) Quote(direction domain.Direction, amountIn *big.Int) (*big.Int, error) {
+	if err := validateAmount(amountIn); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	return p.quote(ctx, direction, amountIn)
+}
+
+// QuoteExactOut returns what buying amountOut would cost, from the router
+func (p *Pool) QuoteExactOut(direction domain.Direction, amountOut *big.Int) (*big.Int, error) {
+	if err := validateAmount(amountOut); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	return p.quoteExactOut(ctx, direction, amountOut)
+}
+
+// Swap sells amountIn through the router and returns the amount received
+// The minimum output is the quote less the slippage tolerance
+func (p *Pool) Swap(trader int, direction domain.Direction, amountIn *big.Int) (*big.Int, error) {
+	return p.SwapContext(context.Background(), trader, direction, amountIn)
+}
+
+// SwapContext is Swap bounded by ctx as well as the timeout, see
+// domain.ContextSwapper. Cancelling ctx stops the wait for the receipt,
+// the swap may still be mined
+func (p *Pool) SwapContext(ctx context.Context, trader int, direction domain.Direction, amountIn *big.Int) (*big.Int, error) {
+	if err := validateAmount(amountIn); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	sent, err := p.sendSwap(ctx, trader, direction, amountIn, true)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := p.waitMined(ctx, sent)
+	if err != nil {
+		return nil, err
+	}
+	in, out, err := p.swapAmounts(receipt, direction)
+	if err != nil {
+		return nil, err
+	}
+	p.publishSwap(ctx, trader, direction, in, out)
+	return out, nil
+}
+
+// SwapExactOut buys exactly amountOut through the router and returns the
+// amount paid. The maximum input is the quote plus the slippage tolerance
+func (p *Pool) SwapExactOut(trader int, direction domain.Direction, amountOut *big.Int) (*big.Int, error) {
+	return p.SwapExactOutContext(context.Background(), trader, direction, amountOut)
+}
+
+// SwapExactOutContext is SwapExactOut bounded by ctx as well as the timeout
+func (p *Pool) SwapExactOutContext(ctx context.Context, trader int, direction domain.Direction, amountOut *big.Int) (*big.Int, error) {
+	if err := validateAmount(amountOut); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	sent, err := p.sendSwap(ctx, trader, direction, amountOut, false)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := p.waitMined(ctx, sent)
+	if err != nil {
+		return nil, err
+	}
+	in, out, err := p.swapAmounts(receipt, direction)
+	if err != nil {
+		return nil, err
+	}
+	p.publishSwap(ctx, trader, direction, in, out)
+	return in, nil
+}
+
+// sendSwap quotes an exact-in (amount is the input) or exact-out swap,
+// approves the router if needed and broadcasts the swap
+// Holds swapMu until the swap is broadcast, but not while it is mined
+func (p *Pool) sendSwap(ctx context.Context, trader int, direction domain.Direction, amount *big.Int, exactIn bool) (*sentTx, error) {
+	p.swapMu.Lock()
+	defer p.swapMu.Unlock()
+
+	var (
+		method          string
+		amountIn, limit *big.Int
+	)
+	if exactIn {
+		expected, err := p.quote(ctx, direction, amount)
+		if err != nil {
+			return nil, err
+		}
+		if expected.Sign() == 0 {
+			return nil, fmt.Errorf("%w: %s in gives nothing out", domain.ErrInsufficientLiquidity, amount)
+		}
+		minOut := new(big.Int).Mul(expected, big.NewInt(10000-p.slippageBps))
+		minOut.Quo(minOut, big.NewInt(10000))
+		method, amountIn, limit = "swapExactTokensForTokens", amount, minOut
+	} else {
+		expected, err := p.quoteExactOut(ctx, direction, amount)
+		if err != nil {
+			return nil, err
+		}
+		maxIn := new(big.Int).Mul(expected, big.NewInt(10000+p.slippageBps))
+		maxIn.Quo(maxIn, big.NewInt(10000))
+		method, amountIn, limit = "swapTokensForExactTokens", maxIn, maxIn
+	}
+
+	tokenIn, _ := p.path(direction)
+	if err := p.approve(ctx, trader, tokenIn, amountIn); err != nil {
+		return nil, err
+	}
+	return p.broadcast(ctx, p.swapTx(trader, tokenIn, amountIn), p.router, method,
+		amount, limit, p.pathSlice(direction), p.from, p.deadline())
+}
+
+// PriceAInB returns the spot price from the cached pair reserves
+func (p *Pool) PriceAInB() float64 {
+	return p.Snapshot().PriceAInB
+}
+
+// PriceBInA returns the spot price from the cached pair reserves
+func (p *Pool) PriceBInA() float64 {
+	return p.Snapshot().PriceBInA
+}
+
+// Snapshot returns the last pair reserves read, without an RPC call
+// They follow this adapter's swaps, and other traders' every refresh
+// interval while Run is active
+func (p *Pool) Snapshot() domain.PoolSnapshot {
+	p.mu.Lock()
+	reserveA, reserveB := new(big.Int).Set(p.reserveA), new(big.Int).Set(p.reserveB)
+	p.mu.Unlock()
+	return domain.PoolSnapshot{
+		TokenA:    p.infoA.Symbol,
+		TokenB:    p.infoB.Symbol,
+		ReserveA:  reserveA,
+		ReserveB:  reserveB,
+		K:         new(big.Int).Mul(reserveA, reserveB),
+		PriceAInB: ratio(reserveB, reserveA),
+		PriceBInA: ratio(reserveA, reserveB),
+		DecimalsA: p.infoA.Decimals,
+		DecimalsB: p.infoB.Decimals,
+	}
+}
+
+// Run rereads the pair reserves every refresh interval until ctx is done
+// A failed read is logged and the last known reserves are kept
+func (p *Pool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := p.Refresh(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Warn("pair reserves read failed, using last known", "pair", p.pairAddress.Hex(), "error", err)
+		}
+	}
+}
+
+// Refresh rereads the pair reserves served by Snapshot
+func (p *Pool) Refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	_, _, err := p.reserves(ctx)
+	return err
+}
+
+// Subscribe streams the swaps sent through this adapter
+// Swaps made by other wallets on the same pair are not included
+func (p *Pool) Subscribe(buffer int) *domain.Subscription {
+	return p.events.Subscribe(buffer)
+}
+
+// quote asks the router for the output of an exact input
+func (p *Pool) quote(ctx context.Context, direction domain.Direction, amountIn *big.Int) (*big.Int, error) {
+	amounts, err := p.callAmounts(ctx, "getAmountsOut", amountIn, p.pathSlice(direction))
+	if err != nil {
+		return nil, err
+	}
+	return amounts[len(amounts)-1], nil
+}
+
+// quoteExactOut asks the router for the input of an exact output
+// Outputs the pair cannot pay are rejected before the call, the router
+// would revert on them
+func (p *Pool) quoteExactOut(ctx context.Context, direction domain.Direction, amountOut *big.Int) (*big.Int, error) {
+	reserveA, reserveB, err := p.reserves(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reserveOut := reserveB
+	if direction == domain.BToA {
+		reserveOut = reserveA
+	}
+	if amountOut.Cmp(reserveOut) >= 0 {
+		return nil, fmt.Errorf("%w: cannot buy %s of %s reserve", domain.ErrInsufficientLiquidity, amountOut, reserveOut)
+	}
+
+	amounts, err := p.callAmounts(ctx, "getAmountsIn", amountOut, p.pathSlice(direction))
+	if err != nil {
+		return nil, err
+	}
+	return amounts[0], nil
+}
+
+// reserves reads the pair reserves as TokenA, TokenB and caches them
+func (p *Pool) reserves(ctx context.Context) (reserveA, reserveB *big.Int, err error) {
+	var out []interface{}
+	if err := p.pair.Call(&bind.CallOpts{Context: ctx}, &out, "getReserves"); err != nil {
+		return nil, nil, fmt.Errorf("failed to call getReserves: %w", err)
+	}
+	reserve0, reserve1 := out[0].(*big.Int), out[1].(*big.Int)
+	reserveA, reserveB = reserve0, reserve1
+	if !p.aIsToken0 {
+		reserveA, reserveB = reserve1, reserve0
+	}
+
+	p.mu.Lock()
+	p.reserveA, p.reserveB = new(big.Int).Set(reserveA), new(big.Int).Set(reserveB)
+	p.mu.Unlock()
+	return reserveA, reserveB, nil
+}
+
+// approve lets the router spend amount of a token, once per token
+// The approval is reported to the observer as a tx of trader
+func (p *Pool) approve(ctx context.Context, trader int, token common.Address, amount *big.Int) error {
+	p.mu.Lock()
+	approved := p.approved[token]
+	p.mu.Unlock()
+	if approved {
+		return nil
+	}
+
+	var out []interface{}
+	if err := p.tokens[token].Call(&bind.CallOpts{Context: ctx}, &out, "allowance", p.from, p.routerAddress); err != nil {
+		return fmt.Errorf("failed to call allowance: %w", err)
+	}
+	if out[0].(*big.Int).Cmp(amount) < 0 {
+		approval := swarm.TxInfo{BotID: trader, From: p.from.Hex(), To: token.Hex(), Token: token.Hex(), Amount: new(big.Int)}
+		sent, err := p.broadcast(ctx, approval, p.tokens[token], "approve", p.routerAddress, maxUint256)
+		if err != nil {
+			return err
+		}
+		if _, err := p.waitMined(ctx, sent); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	p.approved[token] = true
+	p.mu.Unlock()
+	return nil
+}
+
+// swapTx describes a swap transaction selling amountIn of tokenIn for the observer
+func (p *Pool) swapTx(trader int, tokenIn common.Address, amountIn *big.Int) swarm.TxInfo {
+	return swarm.TxInfo{BotID: trader, From: p.from.Hex(), To: p.routerAddress.Hex(), Token: tokenIn.Hex(), Amount: amountIn}
+}
+
+// sentTx is a broadcast transaction and its description for the observer
+type sentTx struct {
+	tx     *types.Transaction
+	method string
+	info   swarm.TxInfo
+}
+
+// broadcast signs and sends a transaction, and reports it to the observer
+// as sent or failed. info describes the transaction to the observer
+// Once signed, the send outlives ctx (bounded by the timeout): cancelling
+// mid-send would leave us unsure whether the tx was broadcast
+func (p *Pool) broadcast(ctx context.Context, info swarm.TxInfo, contract *bind.BoundContract, method string, params ...interface{}) (*sentTx, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(p.key, p.chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transactor: %w", err)
+	}
+	opts.Context = ctx
+	info.SentAt = time.Now()
+	if p.nonces != nil {
+		// estimate without sending first: a call that would revert must not
+		// take a nonce from the shared manager and leave a gap
+		opts.NoSend = true
+		opts.Nonce = new(big.Int)
+		dryRun, err := contract.Transact(opts, method, params...)
+		if err != nil {
+			return nil, p.sendFailed(info, fmt.Errorf("failed to send %s: %w", method, err))
+		}
+		opts.NoSend = false
+		opts.GasLimit = dryRun.Gas()
+		opts.Nonce = new(big.Int).SetUint64(p.nonces.GetNonce())
+		info.Nonce = opts.Nonce.Uint64()
+	}
+
+	sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.timeout)
+	defer cancel()
+	opts.Context = sendCtx
+	tx, err := contract.Transact(opts, method, params...)
+	if err != nil {
+		if p.nonces != nil {
+			p.releaseNonce(sendCtx, opts.Nonce.Uint64())
+		}
+		return nil, p.sendFailed(info, fmt.Errorf("failed to send %s: %w", method, err))
+	}
+	info.Nonce, info.Hash, info.GasPrice = tx.Nonce(), tx.Hash().Hex(), tx.GasPrice()
+	p.observer.TxSent(info)
+	return &sentTx{tx: tx, method: method, info: info}, nil
+}
+
+// waitMined waits for the receipt of a broadcast transaction, reports it to
+// the observer and returns it if the transaction succeeded
+func (p *Pool) waitMined(ctx context.Context, sent *sentTx) (*types.Receipt, error) {
+	hash := sent.tx.Hash().Hex()
+	receipt, err := bind.WaitMined(ctx, p.backend, sent.tx)
+	if err != nil {
+		// like the bots, a receipt that never comes is not reported
+		return nil, fmt.Errorf("failed to wait for %s tx %s: %w", sent.method, hash, err)
+	}
+	mined := &ports.Receipt{
+		TxHash:            receipt.TxHash.Hex(),
+		Status:            receipt.Status,
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+	}
+	if receipt.BlockNumber != nil {
+		mined.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		p.observer.TxFailed(sent.info, swarm.ErrTxReverted)
+		p.observer.TxConfirmed(sent.info, mined)
+		return nil, fmt.Errorf("%w: %s tx %s", ErrReverted, sent.method, hash)
+	}
+	p.observer.TxConfirmed(sent.info, mined)
+	return receipt, nil
+}
+
+// sendFailed reports a transaction that was never broadcast and returns err
+func (p *Pool) sendFailed(info swarm.TxInfo, err error) error {
+	p.observer.TxFailed(info, err)
+	return err
+}
+
+// releaseNonce undoes taking a nonce whose transaction failed to broadcast
+// If other senders took nonces since, the gap is closed by resyncing the
+// shared manager to the pending nonce of the wallet
+func (p *Pool) releaseNonce(ctx context.Context, txNonce uint64) {
+	if p.nonces.Release(txNonce) {
+		return
+	}
+	// the send may have failed on ctx, the resync gets its own deadline
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.timeout)
+	defer cancel()
+	pending, err := p.backend.PendingNonceAt(ctx, p.from)
+	if err != nil {
+		p.logger.Warn("nonce resync failed", "nonce", txNonce, "error", err)
+		return
+	}
+	if current := p.nonces.Current(); pending < current {
+		p.nonces.Reset(pending)
+		p.logger.Info("nonce synced", "nonce_from", current, "nonce_to", pending)
+	}
+}
+
+// swapLog is the Swap event of a UniswapV2 pair
+type swapLog struct {
+	Sender     common.Address
+	Amount0In  *big.Int
+	Amount1In  *big.Int
+	Amount0Out *big.Int
+	Amount1Out *big.Int
+	To         common.Address
+}
+
+// swapAmounts reads what the pair actually took and paid from its Swap event
+func (p *Pool) swapAmounts(receipt *types.Receipt, direction domain.Direction) (amountIn, amountOut *big.Int, err error) {
+	for _, log := range receipt.Logs {
+		if log.Address != p.pairAddress || len(log.Topics) == 0 || log.Topics[0] != pairABI.Events["Swap"].ID {
+			continue
+		}
+		var event swapLog
+		if err := p.pair.UnpackLog(&event, "Swap", *log); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode Swap event: %w", err)
+		}
+		// token0 goes in when selling A with A = token0, or B with B = token0
+		if (direction == domain.AToB) == p.aIsToken0 {
+			return event.Amount0In, event.Amount1Out, nil
+		}
+		return event.Amount1In, event.Amount0Out, nil
+	}
+	return nil, nil, fmt.Errorf("no Swap event from pair %s in tx %s", p.pairAddress.Hex(), receipt.TxHash.Hex())
+}
+
+// publishSwap notifies subscribers with the reserves after the swap
+func (p *Pool) publishSwap(ctx context.Context, trader int, direction domain.Direction, amountIn, amountOut *big.Int) {
+	reserveA, reserveB, err := p.reserves(ctx)
+	if err != nil {
+		// copies: subscribers must not share the cache
+		p.mu.Lock()
+		reserveA, reserveB = new(big.Int).Set(p.reserveA), new(big.Int).Set(p.reserveB)
+		p.mu.Unlock()
+	}
+	p.events.Publish(domain.SwapEvent{
+		Trader:    trader,
+		Direction: direction,
+		AmountIn:  amountIn,
+		AmountOut: amountOut,
+		ReserveA:  reserveA,
+		ReserveB:  reserveB,
+		PriceAInB: ratio(reserveB, reserveA),
+		Time:      p.now(),
+	})
+}
+
+func (p *Pool) callAddress(ctx context.Context, method string) (common.Address, error) {
+	var out []interface{}
+	if err := p.pair.Call(&bind.CallOpts{Context: ctx}, &out, method); err != nil {
+		return common.Address{}, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	return out[0].(common.Address), nil
+}
+
+func (p *Pool) callAmounts(ctx context.Context, method string, amount *big.Int, path []common.Address) ([]*big.Int, error) {
+	var out []interface{}
+	if err := p.router.Call(&bind.CallOpts{Context: ctx}, &out, method, amount, path); err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	amounts := out[0].([]*big.Int)
+	if len(amounts) != len(path) {
+		return nil, fmt.Errorf("%s returned %d amounts for a path of %d", method, len(amounts), len(path))
+	}
+	return amounts, nil
+}
+
+// tokenInfo reads the symbol and decimals of a pair token
+func (p *Pool) tokenInfo(ctx context.Context, token common.Address) (domain.Token, error) {
+	var symbol, decimals []interface{}
+	if err := p.tokens[token].Call(&bind.CallOpts{Context: ctx}, &symbol, "symbol"); err != nil {
+		return domain.Token{}, fmt.Errorf("failed to call symbol on %s: %w", token.Hex(), err)
+	}
+	if err := p.tokens[token].Call(&bind.CallOpts{Context: ctx}, &decimals, "decimals"); err != nil {
+		return domain.Token{}, fmt.Errorf("failed to call decimals on %s: %w", token.Hex(), err)
+	}
+	info := domain.Token{Symbol: symbol[0].(string), Decimals: decimals[0].(uint8), Address: token.Hex()}
+	if info.Decimals > domain.MaxDecimals {
+		return domain.Token{}, fmt.Errorf("token %s has %d decimals, more than %d", token.Hex(), info.Decimals, domain.MaxDecimals)
+	}
+	return info, nil
+}
+
+// path returns the token sold and the token bought in a direction
+func (p *Pool) path(direction domain.Direction) (tokenIn, tokenOut common.Address) {
+	if direction == domain.AToB {
+		return p.tokenA, p.tokenB
+	}
+	return p.tokenB, p.tokenA
+}
+
+func (p *Pool) pathSlice(direction domain.Direction) []common.Address {
+	tokenIn, tokenOut := p.path(direction)
+	return []common.Address{tokenIn, tokenOut}
+}
+
+// deadline is the router deadline of a swap sent now, in unix seconds
+func (p *Pool) deadline() *big.Int {
+	return big.NewInt(time.Now().Add(p.timeout).Unix())
+}
+
+func validateAmount(amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
+}
+
+// ratio returns x / y as a float, 0 for an empty pair
+func ratio(x, y *big.Int) float64 {
+	if y.Sign() == 0 {
+		return 0
+	}
+	result, _ := new(big.Float).Quo(new(big.Float).SetInt(x), new(big.Float).SetInt(y)).Float64()
+	return result
+}
+
+var (
+	_ domain.AMM            = (*Pool)(nil)
+	_ domain.ContextSwapper = (*Pool)(nil)
+)
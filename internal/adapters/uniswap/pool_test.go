@@ -0,0 +1,608 @@
+package uniswap
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/internal/nonce"
+	"github.com/nexus-bot-swarm/ports"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+var (
+	routerAddress = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	pairAddress   = common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	token0Address = common.HexToAddress("0x00000000000000000000000000000000000000b0")
+	token1Address = common.HexToAddress("0x00000000000000000000000000000000000000b1")
+)
+
+// fakeChain answers the router, pair and token calls of the adapter with a
+// UniswapV2 pair (0.3% fee) kept in memory. Transactions are mined on send
+// Log filtering is not needed and panics through the nil embedded interface
+type fakeChain struct {
+	ethereum.LogFilterer
+
+	mu         sync.Mutex
+	reserve0   *big.Int
+	reserve1   *big.Int
+	allowance  map[common.Address]*big.Int
+	approvals  int
+	nonce      uint64
+	sentNonces []uint64
+	receipts   map[common.Hash]*types.Receipt
+
+	// beforeSwap runs before a swap is applied, e.g. to move the price
+	beforeSwap func()
+
+	// sendErr, when set, fails a send before it is broadcast
+	sendErr func() error
+
+	// reservesErr, when set, fails getReserves
+	reservesErr error
+
+	// unmined, when set, hides the receipts of sent transactions
+	unmined bool
+}
+
+func newFakeChain(reserve0, reserve1 int64) *fakeChain {
+	return &fakeChain{
+		reserve0:  big.NewInt(reserve0),
+		reserve1:  big.NewInt(reserve1),
+		allowance: map[common.Address]*big.Int{token0Address: new(big.Int), token1Address: new(big.Int)},
+		receipts:  make(map[common.Hash]*types.Receipt),
+	}
+}
+
+func (c *fakeChain) ChainID(context.Context) (*big.Int, error) { return big.NewInt(1337), nil }
+
+func (c *fakeChain) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (c *fakeChain) PendingCodeAt(context.Context, common.Address) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (c *fakeChain) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1)}, nil
+}
+
+func (c *fakeChain) SuggestGasPrice(context.Context) (*big.Int, error)  { return big.NewInt(1), nil }
+func (c *fakeChain) SuggestGasTipCap(context.Context) (*big.Int, error) { return big.NewInt(1), nil }
+
+func (c *fakeChain) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (c *fakeChain) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nonce, nil
+}
+
+func (c *fakeChain) TransactionReceipt(_ context.Context, hash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if receipt, ok := c.receipts[hash]; ok && !c.unmined {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeChain) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	contract := pairABI
+	switch *msg.To {
+	case routerAddress:
+		contract = routerABI
+	case token0Address, token1Address:
+		contract = erc20ABI
+	}
+	method, err := contract.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch method.Name {
+	case "token0":
+		return method.Outputs.Pack(token0Address)
+	case "token1":
+		return method.Outputs.Pack(token1Address)
+	case "getReserves":
+		if c.reservesErr != nil {
+			return nil, c.reservesErr
+		}
+		return method.Outputs.Pack(c.reserve0, c.reserve1, uint32(0))
+	case "symbol":
+		if *msg.To == token0Address {
+			return method.Outputs.Pack("USDC")
+		}
+		return method.Outputs.Pack("WETH")
+	case "decimals":
+		if *msg.To == token0Address {
+			return method.Outputs.Pack(uint8(6))
+		}
+		return method.Outputs.Pack(uint8(18))
+	case "allowance":
+		return method.Outputs.Pack(c.allowance[*msg.To])
+	case "getAmountsOut":
+		path := args[1].([]common.Address)
+		rIn, rOut := c.reservesFor(path[0])
+		return method.Outputs.Pack([]*big.Int{args[0].(*big.Int), amountOut(args[0].(*big.Int), rIn, rOut)})
+	case "getAmountsIn":
+		path := args[1].([]common.Address)
+		rIn, rOut := c.reservesFor(path[0])
+		return method.Outputs.Pack([]*big.Int{amountIn(args[0].(*big.Int), rIn, rOut), args[0].(*big.Int)})
+	}
+	return nil, errors.New("unexpected call " + method.Name)
+}
+
+func (c *fakeChain) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	if c.sendErr != nil {
+		if err := c.sendErr(); err != nil {
+			return err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sentNonces = append(c.sentNonces, tx.Nonce())
+	c.nonce = tx.Nonce() + 1
+	receipt := &types.Receipt{TxHash: tx.Hash(), Status: types.ReceiptStatusFailed}
+	c.receipts[tx.Hash()] = receipt
+
+	if *tx.To() != routerAddress {
+		// approve(router, amount)
+		args, _ := erc20ABI.Methods["approve"].Inputs.Unpack(tx.Data()[4:])
+		c.allowance[*tx.To()] = args[1].(*big.Int)
+		c.approvals++
+		receipt.Status = types.ReceiptStatusSuccessful
+		return nil
+	}
+
+	method, err := routerABI.MethodById(tx.Data()[:4])
+	if err != nil {
+		return err
+	}
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return err
+	}
+	path := args[2].([]common.Address)
+
+	if c.beforeSwap != nil {
+		c.mu.Unlock()
+		c.beforeSwap()
+		c.mu.Lock()
+	}
+
+	rIn, rOut := c.reservesFor(path[0])
+	var in, out *big.Int
+	if method.Name == "swapExactTokensForTokens" {
+		in, out = args[0].(*big.Int), amountOut(args[0].(*big.Int), rIn, rOut)
+		if out.Cmp(args[1].(*big.Int)) < 0 {
+			return nil // INSUFFICIENT_OUTPUT_AMOUNT
+		}
+	} else {
+		in, out = amountIn(args[0].(*big.Int), rIn, rOut), args[0].(*big.Int)
+		if in.Cmp(args[1].(*big.Int)) > 0 {
+			return nil // EXCESSIVE_INPUT_AMOUNT
+		}
+	}
+	if c.allowance[path[0]].Cmp(in) < 0 {
+		return nil // TRANSFER_FROM_FAILED
+	}
+
+	zero := new(big.Int)
+	amount0In, amount1In, amount0Out, amount1Out := in, zero, zero, out
+	if path[0] == token0Address {
+		c.reserve0.Add(c.reserve0, in)
+		c.reserve1.Sub(c.reserve1, out)
+	} else {
+		amount0In, amount1In, amount0Out, amount1Out = zero, in, out, zero
+		c.reserve1.Add(c.reserve1, in)
+		c.reserve0.Sub(c.reserve0, out)
+	}
+
+	event := pairABI.Events["Swap"]
+	data, err := event.Inputs.NonIndexed().Pack(amount0In, amount1In, amount0Out, amount1Out)
+	if err != nil {
+		return err
+	}
+	receipt.Logs = []*types.Log{{
+		Address: pairAddress,
+		Topics:  []common.Hash{event.ID, common.BytesToHash(routerAddress.Bytes()), common.BytesToHash(args[3].(common.Address).Bytes())},
+		Data:    data,
+	}}
+	receipt.Status = types.ReceiptStatusSuccessful
+	return nil
+}
+
+// reservesFor returns the reserves in and out when selling tokenIn
+func (c *fakeChain) reservesFor(tokenIn common.Address) (reserveIn, reserveOut *big.Int) {
+	if tokenIn == token0Address {
+		return c.reserve0, c.reserve1
+	}
+	return c.reserve1, c.reserve0
+}
+
+// amountOut and amountIn are UniswapV2Library.getAmountOut / getAmountIn
+func amountOut(in, reserveIn, reserveOut *big.Int) *big.Int {
+	withFee := new(big.Int).Mul(in, big.NewInt(997))
+	num := new(big.Int).Mul(withFee, reserveOut)
+	den := new(big.Int).Mul(reserveIn, big.NewInt(1000))
+	return num.Quo(num, den.Add(den, withFee))
+}
+
+func amountIn(out, reserveIn, reserveOut *big.Int) *big.Int {
+	num := new(big.Int).Mul(reserveIn, out)
+	num.Mul(num, big.NewInt(1000))
+	den := new(big.Int).Sub(reserveOut, out)
+	den.Mul(den, big.NewInt(997))
+	return num.Quo(num, den).Add(num, big.NewInt(1))
+}
+
+func newTestPool(t *testing.T, chain *fakeChain, tokenA common.Address) *Pool {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool, err := NewPool(context.Background(), chain, routerAddress.Hex(), pairAddress.Hex(), tokenA.Hex(), hex.EncodeToString(crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return pool
+}
+
+func TestNewPool(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+
+	// TokenA is token1: reserves and symbols are swapped
+	pool := newTestPool(t, chain, token1Address)
+	if a, b := pool.Tokens(); a != "WETH" || b != "USDC" {
+		t.Errorf("expected WETH/USDC, got %s/%s", a, b)
+	}
+	snap := pool.Snapshot()
+	if snap.ReserveA.Int64() != 2000000 || snap.ReserveB.Int64() != 1000000 || snap.PriceAInB != 0.5 {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+	if a, b := pool.TokenInfo(); a.Decimals != 18 || b.Decimals != 6 || snap.DecimalsA != 18 || snap.DecimalsB != 6 {
+		t.Errorf("expected 18/6 decimals, got %+v/%+v, snapshot %d/%d", a, b, snap.DecimalsA, snap.DecimalsB)
+	}
+
+	key, _ := crypto.GenerateKey()
+	privateKey := hex.EncodeToString(crypto.FromECDSA(key))
+	other := common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	if _, err := NewPool(context.Background(), chain, routerAddress.Hex(), pairAddress.Hex(), other.Hex(), privateKey); err == nil {
+		t.Error("expected error for a token outside the pair")
+	}
+	if _, err := NewPool(context.Background(), chain, "not-an-address", pairAddress.Hex(), token0Address.Hex(), privateKey); err == nil {
+		t.Error("expected error for an invalid router address")
+	}
+	if _, err := NewPool(context.Background(), chain, routerAddress.Hex(), pairAddress.Hex(), token0Address.Hex(), "zz"); err == nil {
+		t.Error("expected error for an invalid private key")
+	}
+}
+
+func TestPool_Swap(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+	pool := newTestPool(t, chain, token0Address)
+	sub := pool.Subscribe(4)
+	defer sub.Close()
+
+	for _, dir := range []domain.Direction{domain.AToB, domain.BToA} {
+		quote, err := pool.Quote(dir, big.NewInt(10000))
+		if err != nil {
+			t.Fatalf("unexpected quote error: %v", err)
+		}
+		before := pool.Snapshot()
+		out, err := pool.Swap(3, dir, big.NewInt(10000))
+		if err != nil {
+			t.Fatalf("unexpected swap error: %v", err)
+		}
+		if out.Cmp(quote) != 0 {
+			t.Errorf("%s: quoted %s, swap gave %s", dir, quote, out)
+		}
+		if pool.Snapshot().K.Cmp(before.K) <= 0 {
+			t.Errorf("%s: the pair fee should grow k", dir)
+		}
+
+		e := <-sub.Events()
+		if e.Trader != 3 || e.Direction != dir || e.AmountIn.Int64() != 10000 || e.AmountOut.Cmp(out) != 0 {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	}
+
+	// one approval per token, then the allowance is reused
+	pool.Swap(3, domain.AToB, big.NewInt(10000))
+	if chain.approvals != 2 {
+		t.Errorf("expected 2 approvals, got %d", chain.approvals)
+	}
+
+	if _, err := pool.Swap(3, domain.BToA, big.NewInt(1)); !errors.Is(err, domain.ErrInsufficientLiquidity) {
+		t.Errorf("expected ErrInsufficientLiquidity for a swap giving nothing, got %v", err)
+	}
+}
+
+func TestPool_SwapExactOut(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+	pool := newTestPool(t, chain, token0Address)
+
+	quote, err := pool.QuoteExactOut(domain.BToA, big.NewInt(5000))
+	if err != nil {
+		t.Fatalf("unexpected quote error: %v", err)
+	}
+	before := pool.Snapshot()
+	in, err := pool.SwapExactOut(1, domain.BToA, big.NewInt(5000))
+	if err != nil {
+		t.Fatalf("unexpected swap error: %v", err)
+	}
+	after := pool.Snapshot()
+	if in.Cmp(quote) != 0 {
+		t.Errorf("quoted %s in, swap took %s", quote, in)
+	}
+	if new(big.Int).Sub(before.ReserveA, after.ReserveA).Int64() != 5000 {
+		t.Errorf("expected exactly 5000 A out, reserves %s -> %s", before.ReserveA, after.ReserveA)
+	}
+
+	if _, err := pool.QuoteExactOut(domain.AToB, after.ReserveB); !errors.Is(err, domain.ErrInsufficientLiquidity) {
+		t.Errorf("expected ErrInsufficientLiquidity buying the whole reserve, got %v", err)
+	}
+}
+
+func TestPool_Swap_Slippage(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+	pool := newTestPool(t, chain, token0Address)
+	pool.SetSlippage(100)
+
+	// another trader moves the price 5% between quote and swap
+	chain.beforeSwap = func() {
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		chain.reserve1.Sub(chain.reserve1, big.NewInt(100000))
+	}
+	if _, err := pool.Swap(1, domain.AToB, big.NewInt(10000)); !errors.Is(err, ErrReverted) {
+		t.Errorf("expected ErrReverted past the slippage, got %v", err)
+	}
+}
+
+func TestPool_NonceManager(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+	pool := newTestPool(t, chain, token0Address)
+	manager := nonce.NewManager(7)
+	pool.SetNonceManager(manager)
+
+	pool.Swap(1, domain.AToB, big.NewInt(10000))
+	pool.Swap(1, domain.AToB, big.NewInt(10000))
+
+	// approve + 2 swaps, in order from the shared manager
+	if len(chain.sentNonces) != 3 || chain.sentNonces[0] != 7 || chain.sentNonces[2] != 9 {
+		t.Errorf("expected nonces 7..9, got %v", chain.sentNonces)
+	}
+	if manager.Current() != 10 {
+		t.Errorf("expected the manager at 10, got %d", manager.Current())
+	}
+}
+
+func TestPool_NonceManager_FailedSend(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+	pool := newTestPool(t, chain, token0Address)
+	manager := nonce.NewManager(0)
+	pool.SetNonceManager(manager)
+
+	// nothing else sent: the nonce is given back
+	chain.sendErr = func() error { return errors.New("replacement transaction underpriced") }
+	if _, err := pool.Swap(1, domain.AToB, big.NewInt(10000)); err == nil {
+		t.Fatal("expected the failed send to be returned")
+	}
+	if manager.Current() != 0 {
+		t.Errorf("expected nonce 0 released, manager at %d", manager.Current())
+	}
+
+	// a bot took the next nonce meanwhile: resync to the pending nonce
+	chain.sendErr = func() error {
+		manager.GetNonce()
+		return errors.New("timeout")
+	}
+	pool.Swap(1, domain.AToB, big.NewInt(10000))
+	if manager.Current() != 0 {
+		t.Errorf("expected a resync to the pending nonce 0, manager at %d", manager.Current())
+	}
+
+	chain.sendErr = nil
+	if _, err := pool.Swap(1, domain.AToB, big.NewInt(10000)); err != nil {
+		t.Fatalf("unexpected swap error: %v", err)
+	}
+	if len(chain.sentNonces) != 2 || chain.sentNonces[0] != 0 || chain.sentNonces[1] != 1 {
+		t.Errorf("expected nonces 0 and 1 without a gap, got %v", chain.sentNonces)
+	}
+}
+
+func TestPool_SnapshotCached(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+	pool := newTestPool(t, chain, token0Address)
+	pool.SetRefreshInterval(time.Millisecond)
+
+	// another trader moves the pair: snapshots keep the cached reserves
+	chain.mu.Lock()
+	chain.reserve1 = big.NewInt(1000000)
+	chain.mu.Unlock()
+	if snap := pool.Snapshot(); snap.ReserveB.Int64() != 2000000 || pool.PriceAInB() != 2 {
+		t.Errorf("expected the cached reserves, got %+v", snap)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for pool.PriceAInB() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+	if pool.PriceAInB() != 1 {
+		t.Errorf("expected Run to refresh the reserves, price %f", pool.PriceAInB())
+	}
+}
+
+func TestPool_Swap_ReservesReadFails(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+	pool := newTestPool(t, chain, token0Address)
+	sub := pool.Subscribe(1)
+	defer sub.Close()
+
+	chain.reservesErr = errors.New("rpc down")
+	if _, err := pool.Swap(1, domain.AToB, big.NewInt(10000)); err != nil {
+		t.Fatalf("unexpected swap error: %v", err)
+	}
+
+	// the event carries the last known reserves, a subscriber cannot alter them
+	e := <-sub.Events()
+	if e.ReserveA.Int64() != 1000000 {
+		t.Errorf("expected the last known reserve, got %s", e.ReserveA)
+	}
+	e.ReserveA.SetInt64(1)
+	if pool.Snapshot().ReserveA.Int64() != 1000000 {
+		t.Errorf("the event shares the adapter cache: %s", pool.Snapshot().ReserveA)
+	}
+}
+
+// txObserver records the tx events reported by the adapter
+type txObserver struct {
+	swarm.NopObserver
+
+	mu        sync.Mutex
+	sent      []swarm.TxInfo
+	failed    []error
+	confirmed []*ports.Receipt
+}
+
+func (o *txObserver) TxSent(tx swarm.TxInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sent = append(o.sent, tx)
+}
+
+func (o *txObserver) TxFailed(_ swarm.TxInfo, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.failed = append(o.failed, err)
+}
+
+func (o *txObserver) TxConfirmed(_ swarm.TxInfo, receipt *ports.Receipt) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.confirmed = append(o.confirmed, receipt)
+}
+
+func TestPool_Observer(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+	pool := newTestPool(t, chain, token0Address)
+	pool.SetNonceManager(nonce.NewManager(5))
+	observer := &txObserver{}
+	pool.SetObserver(observer)
+
+	if _, err := pool.Swap(3, domain.AToB, big.NewInt(10000)); err != nil {
+		t.Fatalf("unexpected swap error: %v", err)
+	}
+
+	// the approval, then the swap of bot 3
+	if len(observer.sent) != 2 || len(observer.confirmed) != 2 || len(observer.failed) != 0 {
+		t.Fatalf("expected 2 txs sent and mined, got %+v", observer)
+	}
+	swap := observer.sent[1]
+	if swap.BotID != 3 || swap.Nonce != 6 || swap.To != routerAddress.Hex() || swap.Token != token0Address.Hex() ||
+		swap.Amount.Int64() != 10000 || swap.Hash == "" || swap.GasPrice == nil {
+		t.Errorf("unexpected swap tx: %+v", swap)
+	}
+	if observer.confirmed[1].TxHash != swap.Hash || observer.confirmed[1].Status != 1 {
+		t.Errorf("unexpected swap receipt: %+v", observer.confirmed[1])
+	}
+
+	// a revert is reported as failed, then mined
+	pool.SetSlippage(100)
+	chain.beforeSwap = func() {
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		chain.reserve1.Sub(chain.reserve1, big.NewInt(100000))
+	}
+	pool.Swap(3, domain.AToB, big.NewInt(10000))
+	if len(observer.failed) != 1 || !errors.Is(observer.failed[0], swarm.ErrTxReverted) || observer.confirmed[2].Status != 0 {
+		t.Errorf("expected a reverted swap, got %v and %+v", observer.failed, observer.confirmed)
+	}
+
+	// a send rejected by the RPC is reported as failed, never as sent
+	chain.beforeSwap = nil
+	chain.sendErr = func() error { return errors.New("replacement transaction underpriced") }
+	pool.Swap(3, domain.AToB, big.NewInt(10000))
+	if len(observer.sent) != 3 || len(observer.failed) != 2 {
+		t.Errorf("expected the failed send reported once, got %d sent and %v", len(observer.sent), observer.failed)
+	}
+}
+
+func TestPool_SwapContext_WaitsUnlocked(t *testing.T) {
+	chain := newFakeChain(1000000, 2000000)
+	pool := newTestPool(t, chain, token0Address)
+	if _, err := pool.Swap(1, domain.AToB, big.NewInt(10000)); err != nil {
+		t.Fatalf("unexpected swap error: %v", err)
+	}
+
+	chain.mu.Lock()
+	chain.unmined = true
+	chain.mu.Unlock()
+	sent := func() int {
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		return len(chain.sentNonces)
+	}
+
+	// two bots swap while no block comes: the second one is sent anyway
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	for trader := 1; trader <= 2; trader++ {
+		go func() {
+			_, err := pool.SwapContext(ctx, trader, domain.AToB, big.NewInt(10000))
+			errs <- err
+		}()
+	}
+	deadline := time.Now().Add(time.Second)
+	for sent() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := sent(); n != 4 {
+		t.Fatalf("expected both swaps sent while waiting for receipts, got %d txs", n)
+	}
+
+	// stopping the bots ends the wait
+	cancel()
+	for range 2 {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected the wait cancelled, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("swap still waiting after cancel")
+		}
+	}
+}
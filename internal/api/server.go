@@ -0,0 +1,269 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// Server is the admin HTTP API of a running swarm
+//
+//	GET    /bots                 list bots and their status
+//	POST   /bots                 add a bot          {"strategy": "random"}
+//	DELETE /bots/{id}            stop and remove a bot
+//	PUT    /bots/count           scale to N bots    {"count": 10}
+//	POST   /bots/{id}/pause      pause a bot
+//	POST   /bots/{id}/resume     resume a bot
+//	PUT    /bots/{id}/strategy   change strategy    {"strategy": "mean-reversion"}
+//	GET    /tx-interval          current real TX cadence
+//	PUT    /tx-interval          change cadence     {"interval": "5s"}
+//	GET    /pool                 pool reserves and price
+type Server struct {
+	swarm *swarm.Swarm
+	mux   *http.ServeMux
+}
+
+// NewServer creates the admin API for the given swarm
+func NewServer(s *swarm.Swarm) *Server {
+	srv := &Server{
+		swarm: s,
+		mux:   http.NewServeMux(),
+	}
+
+	srv.mux.HandleFunc("GET /bots", srv.listBots)
+	srv.mux.HandleFunc("POST /bots", srv.addBot)
+	srv.mux.HandleFunc("DELETE /bots/{id}", srv.removeBot)
+	srv.mux.HandleFunc("PUT /bots/count", srv.scaleBots)
+	srv.mux.HandleFunc("POST /bots/{id}/pause", srv.pauseBot)
+	srv.mux.HandleFunc("POST /bots/{id}/resume", srv.resumeBot)
+	srv.mux.HandleFunc("PUT /bots/{id}/strategy", srv.setStrategy)
+	srv.mux.HandleFunc("GET /tx-interval", srv.getTxInterval)
+	srv.mux.HandleFunc("PUT /tx-interval", srv.setTxInterval)
+	srv.mux.HandleFunc("GET /pool", srv.getPool)
+	return srv
+}
+
+// ServeHTTP implements http.Handler
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.mux.ServeHTTP(w, r)
+}
+
+type strategyRequest struct {
+	Strategy string `json:"strategy"`
+}
+
+type countRequest struct {
+	Count int `json:"count"`
+}
+
+type intervalRequest struct {
+	Interval string `json:"interval"`
+}
+
+type poolResponse struct {
+	TokenA    string  `json:"token_a"`
+	TokenB    string  `json:"token_b"`
+	ReserveA  string  `json:"reserve_a"`
+	ReserveB  string  `json:"reserve_b"`
+	K         string  `json:"k"`
+	PriceAInB float64 `json:"price_a_in_b"`
+	PriceBInA float64 `json:"price_b_in_a"`
+}
+
+func (srv *Server) listBots(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, srv.swarm.BotStatuses())
+}
+
+func (srv *Server) addBot(w http.ResponseWriter, r *http.Request) {
+	var req strategyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+			return
+		}
+	}
+
+	var strategy swarm.Strategy
+	if req.Strategy != "" {
+		s, err := swarm.NewStrategy(req.Strategy, nil)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		strategy = s
+	}
+
+	bot, err := srv.swarm.AddBot(strategy)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	srv.writeBot(w, http.StatusCreated, bot.ID)
+}
+
+func (srv *Server) removeBot(w http.ResponseWriter, r *http.Request) {
+	id, ok := botID(w, r)
+	if !ok {
+		return
+	}
+	if err := srv.swarm.RemoveBot(id); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *Server) scaleBots(w http.ResponseWriter, r *http.Request) {
+	var req countRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	if req.Count < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("count must not be negative"))
+		return
+	}
+
+	n, err := srv.swarm.Scale(req.Count)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, countRequest{Count: n})
+}
+
+func (srv *Server) pauseBot(w http.ResponseWriter, r *http.Request) {
+	id, ok := botID(w, r)
+	if !ok {
+		return
+	}
+	if err := srv.swarm.PauseBot(id); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	srv.writeBot(w, http.StatusOK, id)
+}
+
+func (srv *Server) resumeBot(w http.ResponseWriter, r *http.Request) {
+	id, ok := botID(w, r)
+	if !ok {
+		return
+	}
+	if err := srv.swarm.ResumeBot(id); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	srv.writeBot(w, http.StatusOK, id)
+}
+
+func (srv *Server) setStrategy(w http.ResponseWriter, r *http.Request) {
+	id, ok := botID(w, r)
+	if !ok {
+		return
+	}
+
+	var req strategyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	strategy, err := swarm.NewStrategy(req.Strategy, nil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := srv.swarm.SetBotStrategy(id, strategy); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	srv.writeBot(w, http.StatusOK, id)
+}
+
+func (srv *Server) getTxInterval(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, intervalRequest{Interval: srv.swarm.TxInterval().String()})
+}
+
+func (srv *Server) setTxInterval(w http.ResponseWriter, r *http.Request) {
+	var req intervalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+	d, err := time.ParseDuration(req.Interval)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid interval: %w", err))
+		return
+	}
+
+	if err := srv.swarm.SetTxInterval(d); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, intervalRequest{Interval: d.String()})
+}
+
+func (srv *Server) getPool(w http.ResponseWriter, r *http.Request) {
+	snap := srv.swarm.Pool().Snapshot()
+
+	writeJSON(w, http.StatusOK, poolResponse{
+		TokenA:    snap.TokenA,
+		TokenB:    snap.TokenB,
+		ReserveA:  snap.ReserveA.String(),
+		ReserveB:  snap.ReserveB.String(),
+		K:         snap.K.String(),
+		PriceAInB: snap.PriceAInB,
+		PriceBInA: snap.PriceBInA,
+	})
+}
+
+// writeBot responds with the status of a single bot
+func (srv *Server) writeBot(w http.ResponseWriter, code int, id int) {
+	for _, status := range srv.swarm.BotStatuses() {
+		if status.ID == id {
+			writeJSON(w, code, status)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("bot %d: %w", id, swarm.ErrBotNotFound))
+}
+
+// botID parses the {id} path value, writing a 400 on failure
+func botID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid bot id: %q", r.PathValue("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// statusFor maps swarm errors to HTTP status codes
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, swarm.ErrBotNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, swarm.ErrSwarmStopped), errors.Is(err, swarm.ErrTooManyBots):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to write api response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+func newTestServer(t *testing.T, bots int) (*httptest.Server, *swarm.Swarm) {
+	t.Helper()
+
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	s := swarm.NewSwarm(bots, pool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh, err := s.Start(ctx)
+	if err != nil {
+		t.Fatalf("failed to start swarm: %v", err)
+	}
+
+	srv := httptest.NewServer(NewServer(s))
+	t.Cleanup(func() {
+		srv.Close()
+		cancel()
+		for range errCh {
+		}
+	})
+	return srv, s
+}
+
+func do(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestServer_ListBots(t *testing.T) {
+	srv, _ := newTestServer(t, 2)
+
+	resp := do(t, "GET", srv.URL+"/bots", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var bots []swarm.BotStatus
+	if err := json.NewDecoder(resp.Body).Decode(&bots); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(bots) != 2 || bots[0].ID != 1 || !bots[0].Running {
+		t.Errorf("unexpected bots: %+v", bots)
+	}
+}
+
+func TestServer_PauseResume(t *testing.T) {
+	srv, s := newTestServer(t, 1)
+
+	if resp := do(t, "POST", srv.URL+"/bots/1/pause", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("pause: expected 200, got %d", resp.StatusCode)
+	}
+	bot, _ := s.Bot(1)
+	if !bot.Paused() {
+		t.Error("bot should be paused")
+	}
+
+	if resp := do(t, "POST", srv.URL+"/bots/1/resume", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("resume: expected 200, got %d", resp.StatusCode)
+	}
+	if bot.Paused() {
+		t.Error("bot should be resumed")
+	}
+
+	if resp := do(t, "POST", srv.URL+"/bots/99/pause", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown bot: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_AddRemoveBot(t *testing.T) {
+	srv, s := newTestServer(t, 1)
+
+	resp := do(t, "POST", srv.URL+"/bots", `{"strategy": "mean-reversion"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("add: expected 201, got %d", resp.StatusCode)
+	}
+	var status swarm.BotStatus
+	json.NewDecoder(resp.Body).Decode(&status)
+	if status.ID != 2 || status.Strategy != "mean-reversion" {
+		t.Errorf("unexpected new bot: %+v", status)
+	}
+	if s.BotCount() != 2 {
+		t.Errorf("expected 2 bots, got %d", s.BotCount())
+	}
+
+	if resp := do(t, "DELETE", srv.URL+"/bots/1", ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("remove: expected 204, got %d", resp.StatusCode)
+	}
+	if s.BotCount() != 1 {
+		t.Errorf("expected 1 bot after remove, got %d", s.BotCount())
+	}
+
+	if resp := do(t, "POST", srv.URL+"/bots", `{"strategy": "nope"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad strategy: expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_SetStrategy(t *testing.T) {
+	srv, s := newTestServer(t, 1)
+
+	resp := do(t, "PUT", srv.URL+"/bots/1/strategy", `{"strategy": "mean-reversion"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	bot, _ := s.Bot(1)
+	if bot.Strategy().Name() != "mean-reversion" {
+		t.Errorf("expected mean-reversion, got %s", bot.Strategy().Name())
+	}
+}
+
+func TestServer_TxInterval(t *testing.T) {
+	srv, s := newTestServer(t, 2)
+
+	resp := do(t, "PUT", srv.URL+"/tx-interval", `{"interval": "3s"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	for _, b := range s.Bots() {
+		if b.TxInterval() != 3*time.Second {
+			t.Errorf("bot %d: expected 3s, got %s", b.ID, b.TxInterval())
+		}
+	}
+
+	if resp := do(t, "PUT", srv.URL+"/tx-interval", `{"interval": "-1s"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("negative interval: expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_Pool(t *testing.T) {
+	srv, _ := newTestServer(t, 0)
+
+	resp := do(t, "GET", srv.URL+"/pool", "")
+	var pool poolResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pool); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if pool.TokenA != "ETH" || pool.ReserveA != "1000000" || pool.PriceAInB != 2 {
+		t.Errorf("unexpected pool: %+v", pool)
+	}
+}
+
+func TestServer_ScaleBots(t *testing.T) {
+	srv, s := newTestServer(t, 1)
+
+	resp := do(t, "PUT", srv.URL+"/bots/count", `{"count": 4}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if s.BotCount() != 4 {
+		t.Errorf("expected 4 bots, got %d", s.BotCount())
+	}
+
+	do(t, "PUT", srv.URL+"/bots/count", `{"count": 2}`)
+	if s.BotCount() != 2 {
+		t.Errorf("expected 2 bots, got %d", s.BotCount())
+	}
+
+	if resp := do(t, "PUT", srv.URL+"/bots/count", `{"count": -3}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("negative count: expected 400, got %d", resp.StatusCode)
+	}
+}
@@ -4,12 +4,30 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// Bot defaults, the same as the swarm's when it is built without a config
+const (
+	DefaultSwapInterval = 500 * time.Millisecond
+	DefaultTxInterval   = 10 * time.Second
+	DefaultSwapLogEvery = 10
+)
+
+// Strategies are the bot strategy names, see swarm.NewStrategy
+var Strategies = []string{"random", "mean-reversion", "momentum"}
+
 // Config holds all configuration for the bot swarm
+// Values come from the defaults, then the YAML file named by CONFIG_FILE,
+// then the environment variables, which override the file
 type Config struct {
-	// Nexus RPC endpoint
-	RPCURL string
+	// YAML file the config was read from (empty = environment only)
+	File string
+
+	// Nexus RPC endpoints, tried in order at connect until one answers on
+	// the expected chain
+	RPCURLs []string
 
 	// Expected chain ID (Nexus Testnet III = 3945)
 	ExpectedChainID int64
@@ -17,6 +35,12 @@ type Config struct {
 	// Number of bots in the swarm
 	BotCount int
 
+	// Strategy of every bot unless overridden in Bots: random, mean-reversion, momentum
+	BotStrategy string
+
+	// Per-bot strategy and wallet, from the config file only
+	Bots []BotConfig
+
 	// Wallet address for balance queries
 	WalletAddress string
 
@@ -25,43 +49,362 @@ type Config struct {
 
 	// ERC20 token contract address (KevzToken)
 	TokenAddress string
+
+	// Amount of each bot ERC20 transfer in whole tokens, e.g. "1" or "0.5 KEVZ"
+	// Converted with the decimals read from the token contract
+	TokenTransferAmount string
+
+	// JSON file listing the tokens of the registry (empty = only the tokens
+	// read from chain: TOKEN_ADDRESS and the pool tokens)
+	TokensFile string
+
+	// Tokens listed in the config file, registered before TokensFile
+	Tokens []TokenConfig
+
+	// Transfers spread over the bots by symbol, e.g. ["1 KEVZ", "2.5 USDC"]
+	// Replaces TOKEN_ADDRESS/TOKEN_TRANSFER_AMOUNT when set
+	TokenTransfers []string
+
+	// Simulated pool: token symbols and initial reserves, in raw units
+	// ("1000000") or in whole tokens with the symbol ("1000 ETH"), converted
+	// with the decimals of the token registry
+	PoolSymbolA  string
+	PoolSymbolB  string
+	PoolReserveA string
+	PoolReserveB string
+
+	// Simulated pools traded instead of the one above, the bots taking them
+	// in turn by ID (only set by the config file)
+	Pools []PoolConfig
+
+	// UniswapV2-compatible router and pair the bots trade on instead of the
+	// simulated pool (empty = simulated). PoolTokenA is the pair token used as
+	// TokenA, TOKEN_ADDRESS by default
+	PoolRouterAddress string
+	PoolPairAddress   string
+	PoolTokenA        string
+
+	// Price move tolerated by on-chain swaps, in basis points
+	PoolSlippageBps int64
+
+	// Initial TokenA/TokenB balances of each bot's simulated portfolio, in
+	// raw units or whole tokens like the pool reserves
+	BotBalanceA string
+	BotBalanceB string
+
+	// Bot cadence: delay between simulated swaps and between real txs
+	SwapInterval time.Duration
+	TxInterval   time.Duration
+
+	// Most bots the admin API can scale to (0 = no limit)
+	MaxBots int
+
+	// Listen address for the Prometheus /metrics endpoint (empty = disabled)
+	MetricsAddr string
+
+	// Listen address for the admin HTTP API (empty = disabled)
+	AdminAddr string
+
+	// Log output: level (debug, info, warn, error) and format (text, json)
+	LogLevel  string
+	LogFormat string
+
+	// Log 1 simulated swap every N per bot (0 = no swap records)
+	LogSwapEvery int
+
+	// Directory for the JSON/Markdown run report written at shutdown (empty = disabled)
+	ReportDir string
+
+	// Pool candle intervals recorded for strategies and exported with the report (empty = disabled)
+	CandleIntervals []time.Duration
+
+	// Embedded database recording every real tx (empty = disabled)
+	TxDBPath string
+
+	// Graceful shutdown: max wait for in-flight sends/receipts, and whether to wait for receipts
+	ShutdownTimeout      time.Duration
+	ShutdownWaitReceipts bool
+}
+
+// BotConfig overrides the swarm settings for one bot
+type BotConfig struct {
+	// Bot ID, 1 to BotCount
+	ID int
+
+	// Strategy name (empty = BotStrategy)
+	Strategy string
+
+	// Wallet the bot sends real txs from (empty = the swarm wallet)
+	// Both must be set together
+	WalletAddress string
+	PrivateKey    string
+}
+
+// PoolConfig is a simulated pool of the Pools list, with the token symbols
+// and initial reserves of PoolSymbolA/B and PoolReserveA/B
+type PoolConfig struct {
+	SymbolA  string `yaml:"symbol_a"`
+	SymbolB  string `yaml:"symbol_b"`
+	ReserveA string `yaml:"reserve_a"`
+	ReserveB string `yaml:"reserve_b"`
+}
+
+// SimulatedPools returns the simulated pools to trade: the Pools list, or
+// the single pool of PoolSymbolA/B and PoolReserveA/B
+func (c *Config) SimulatedPools() []PoolConfig {
+	if len(c.Pools) > 0 {
+		return c.Pools
+	}
+	return []PoolConfig{{SymbolA: c.PoolSymbolA, SymbolB: c.PoolSymbolB, ReserveA: c.PoolReserveA, ReserveB: c.PoolReserveB}}
+}
+
+// TokenConfig is a token of the registry, with the fields of a TokensFile
+// entry: an address, or a symbol and decimals for a simulated token
+type TokenConfig struct {
+	Symbol   string `yaml:"symbol"`
+	Name     string `yaml:"name"`
+	Address  string `yaml:"address"`
+	Decimals *uint8 `yaml:"decimals"`
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	return &Config{
+		RPCURLs:             []string{"https://testnet.rpc.nexus.xyz"}, // default but not hardcoded in logic
+		ExpectedChainID:     3945,
+		BotCount:            3,
+		BotStrategy:         "random",
+		TokenTransferAmount: "1",
+
+		// 1000 ETH, 2000 USDC in wei-like units
+		PoolSymbolA:     "ETH",
+		PoolSymbolB:     "USDC",
+		PoolReserveA:    "1000000000",
+		PoolReserveB:    "2000000000",
+		PoolSlippageBps: 50,
+
+		BotBalanceA:  "1000000",
+		BotBalanceB:  "2000000",
+		SwapInterval: DefaultSwapInterval,
+		TxInterval:   DefaultTxInterval,
+
+		LogLevel:        "info",
+		LogFormat:       "text",
+		LogSwapEvery:    DefaultSwapLogEvery,
+		CandleIntervals: []time.Duration{time.Second, time.Minute, 5 * time.Minute},
+
+		ShutdownTimeout:      30 * time.Second,
+		ShutdownWaitReceipts: true,
+	}
 }
 
-// Load reads configuration from environment variables
+// Load reads the config file named by CONFIG_FILE, if any, and the
+// environment variables, then validates the result
 func Load() (*Config, error) {
-	rpcURL := os.Getenv("NEXUS_RPC_URL")
-	if rpcURL == "" {
-		rpcURL = "https://testnet.rpc.nexus.xyz" // default but not hardcoded in logic
+	return LoadFile(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadFile reads a YAML config file (empty path = none) and the environment
+// variables, which override it, then validates the result
+// Malformed YAML, malformed values and failed checks are all reported in one
+// ValidationError. Only a config file that cannot be read stops early
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+	var p problems
+	if path != "" {
+		if err := cfg.applyFile(path, &p); err != nil {
+			return nil, err
+		}
+	}
+	cfg.applyEnv(&p)
+
+	if err := cfg.Validate(); err != nil {
+		p = append(p, err.(*ValidationError).Problems...)
+	}
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides the config with the environment variables that are set
+// Values that do not parse are recorded in p and leave the field unchanged
+func (c *Config) applyEnv(p *problems) {
+	if value := os.Getenv("NEXUS_RPC_URL"); value != "" {
+		c.RPCURLs = splitList(value)
+	}
+	if value := os.Getenv("NEXUS_CHAIN_ID"); value != "" {
+		chainID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			p.add("invalid NEXUS_CHAIN_ID: %q", value)
+		} else {
+			c.ExpectedChainID = chainID
+		}
 	}
+	parseInt(p, "BOT_COUNT", &c.BotCount)
+	parseInt(p, "MAX_BOTS", &c.MaxBots)
+	c.BotStrategy = getenv("BOT_STRATEGY", c.BotStrategy)
 
-	chainIDStr := os.Getenv("NEXUS_CHAIN_ID")
-	if chainIDStr == "" {
-		chainIDStr = "3945"
+	c.WalletAddress = getenv("WALLET_ADDRESS", c.WalletAddress)
+	c.PrivateKey = getenv("NEXUS_PRIVATE_KEY", c.PrivateKey)
+	c.TokenAddress = getenv("TOKEN_ADDRESS", c.TokenAddress)
+	c.TokenTransferAmount = getenv("TOKEN_TRANSFER_AMOUNT", c.TokenTransferAmount)
+	c.TokensFile = getenv("TOKENS_FILE", c.TokensFile)
+	if value := os.Getenv("TOKEN_TRANSFERS"); value != "" {
+		c.TokenTransfers = splitList(value)
+	}
+
+	c.PoolReserveA = getenv("POOL_RESERVE_A", c.PoolReserveA)
+	c.PoolReserveB = getenv("POOL_RESERVE_B", c.PoolReserveB)
+	c.PoolRouterAddress = getenv("POOL_ROUTER_ADDRESS", c.PoolRouterAddress)
+	c.PoolPairAddress = getenv("POOL_PAIR_ADDRESS", c.PoolPairAddress)
+	c.PoolTokenA = getenv("POOL_TOKEN_A", c.PoolTokenA)
+	if c.PoolTokenA == "" {
+		c.PoolTokenA = c.TokenAddress
+	}
+	if value := os.Getenv("POOL_SLIPPAGE_BPS"); value != "" {
+		slippage, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			p.add("invalid POOL_SLIPPAGE_BPS: %q", value)
+		} else {
+			c.PoolSlippageBps = slippage
+		}
 	}
-	chainID, err := strconv.ParseInt(chainIDStr, 10, 64)
+
+	c.BotBalanceA = getenv("BOT_BALANCE_A", c.BotBalanceA)
+	c.BotBalanceB = getenv("BOT_BALANCE_B", c.BotBalanceB)
+	parseDuration(p, "SWAP_INTERVAL", &c.SwapInterval)
+	parseDuration(p, "TX_INTERVAL", &c.TxInterval)
+
+	c.MetricsAddr = getenvOptional("METRICS_ADDR", c.MetricsAddr)
+	c.AdminAddr = getenvOptional("ADMIN_ADDR", c.AdminAddr)
+	c.LogLevel = getenv("LOG_LEVEL", c.LogLevel)
+	c.LogFormat = getenv("LOG_FORMAT", c.LogFormat)
+	parseInt(p, "LOG_SWAP_EVERY", &c.LogSwapEvery)
+
+	// REPORT_DIR / TX_DB_PATH / CANDLE_INTERVALS set but empty disable the feature
+	c.ReportDir = getenvOptional("REPORT_DIR", c.ReportDir)
+	c.TxDBPath = getenvOptional("TX_DB_PATH", c.TxDBPath)
+	if value, ok := os.LookupEnv("CANDLE_INTERVALS"); ok {
+		intervals, err := parseIntervals(value)
+		if err != nil {
+			p.add("invalid CANDLE_INTERVALS: %v", err)
+		} else {
+			c.CandleIntervals = intervals
+		}
+	}
+
+	parseDuration(p, "SHUTDOWN_TIMEOUT", &c.ShutdownTimeout)
+	if value := os.Getenv("SHUTDOWN_WAIT_RECEIPTS"); value != "" {
+		wait, err := strconv.ParseBool(value)
+		if err != nil {
+			p.add("invalid SHUTDOWN_WAIT_RECEIPTS: %q", value)
+		} else {
+			c.ShutdownWaitReceipts = wait
+		}
+	}
+}
+
+// getenv returns the env var or a default when unset
+func getenv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// getenvOptional returns the env var, or a default when unset
+// Unlike getenv, an explicitly empty value is kept (used to disable features)
+func getenvOptional(key, def string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return def
+}
+
+// parseInt reads an integer env var into dst when set
+func parseInt(p *problems, key string, dst *int) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	n, err := strconv.Atoi(value)
 	if err != nil {
-		return nil, fmt.Errorf("invalid NEXUS_CHAIN_ID: %w", err)
+		p.add("invalid %s: %q", key, value)
+		return
 	}
+	*dst = n
+}
 
-	botCountStr := os.Getenv("BOT_COUNT")
-	if botCountStr == "" {
-		botCountStr = "3"
+// parseDuration reads a duration such as "500ms" from an env var when set
+func parseDuration(p *problems, key string, dst *time.Duration) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
 	}
-	botCount, err := strconv.Atoi(botCountStr)
+	d, err := time.ParseDuration(value)
 	if err != nil {
-		return nil, fmt.Errorf("invalid BOT_COUNT: %w", err)
+		p.add("invalid %s: %q", key, value)
+		return
 	}
+	*dst = d
+}
 
-	walletAddress := os.Getenv("WALLET_ADDRESS")
-	privateKey := os.Getenv("NEXUS_PRIVATE_KEY")
-	tokenAddress := os.Getenv("TOKEN_ADDRESS")
+// parseIntervals parses a comma separated list of durations, e.g. "1s,1m,5m"
+func parseIntervals(spec string) ([]time.Duration, error) {
+	var intervals []time.Duration
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid candle interval %q: %w", part, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid candle interval %q: must be positive", part)
+		}
+		intervals = append(intervals, d)
+	}
+	return intervals, nil
+}
 
-	return &Config{
-		RPCURL:          rpcURL,
-		ExpectedChainID: chainID,
-		BotCount:        botCount,
-		WalletAddress:   walletAddress,
-		PrivateKey:      privateKey,
-		TokenAddress:    tokenAddress,
-	}, nil
+// splitList splits a comma-separated list, dropping the spaces around items
+// A trailing comma gives an empty item, which validation reports
+func splitList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	items := strings.Split(value, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// problems collects configuration errors so they can be reported together
+type problems []string
+
+func (p *problems) add(format string, args ...any) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: p}
+}
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return "invalid config: " + e.Problems[0]
+	}
+	return fmt.Sprintf("invalid config, %d problems:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
 }
@@ -1,8 +1,20 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// well-known development key, never funded outside local chains
+const (
+	testKey    = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	testWallet = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
 )
 
 func TestLoad_Defaults(t *testing.T) {
@@ -16,8 +28,8 @@ func TestLoad_Defaults(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if cfg.RPCURL != "https://testnet.rpc.nexus.xyz" {
-		t.Errorf("expected default RPC URL, got %s", cfg.RPCURL)
+	if len(cfg.RPCURLs) != 1 || cfg.RPCURLs[0] != "https://testnet.rpc.nexus.xyz" {
+		t.Errorf("expected default RPC URL, got %v", cfg.RPCURLs)
 	}
 	if cfg.ExpectedChainID != 3945 {
 		t.Errorf("expected chain ID 3945, got %d", cfg.ExpectedChainID)
@@ -28,7 +40,7 @@ func TestLoad_Defaults(t *testing.T) {
 }
 
 func TestLoad_CustomValues(t *testing.T) {
-	os.Setenv("NEXUS_RPC_URL", "http://localhost:8545")
+	os.Setenv("NEXUS_RPC_URL", "http://localhost:8545, http://localhost:8546")
 	os.Setenv("NEXUS_CHAIN_ID", "1337")
 	os.Setenv("BOT_COUNT", "5")
 	defer func() {
@@ -42,8 +54,8 @@ func TestLoad_CustomValues(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if cfg.RPCURL != "http://localhost:8545" {
-		t.Errorf("expected custom RPC URL, got %s", cfg.RPCURL)
+	if len(cfg.RPCURLs) != 2 || cfg.RPCURLs[0] != "http://localhost:8545" || cfg.RPCURLs[1] != "http://localhost:8546" {
+		t.Errorf("expected custom RPC URLs, got %v", cfg.RPCURLs)
 	}
 	if cfg.ExpectedChainID != 1337 {
 		t.Errorf("expected chain ID 1337, got %d", cfg.ExpectedChainID)
@@ -72,3 +84,475 @@ func TestLoad_InvalidBotCount(t *testing.T) {
 		t.Error("expected error for invalid bot count")
 	}
 }
+
+func TestLoad_BotBalances(t *testing.T) {
+	t.Setenv("BOT_BALANCE_A", "500")
+	t.Setenv("BOT_BALANCE_B", "1000000000000000000000")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.BotBalanceA != "500" {
+		t.Errorf("expected BotBalanceA 500, got %s", cfg.BotBalanceA)
+	}
+	if cfg.BotBalanceB != "1000000000000000000000" {
+		t.Errorf("expected BotBalanceB 1e21, got %s", cfg.BotBalanceB)
+	}
+
+	// whole tokens with the symbol, converted once the decimals are known
+	t.Setenv("BOT_BALANCE_A", "1.5 ETH")
+	if cfg, err := Load(); err != nil || cfg.BotBalanceA != "1.5 ETH" {
+		t.Errorf("expected whole tokens to be accepted, got %v", err)
+	}
+}
+
+func TestLoad_InvalidBotBalance(t *testing.T) {
+	for _, value := range []string{"-1", "1.5", "-1 ETH", "1.5 ETH extra"} {
+		t.Setenv("BOT_BALANCE_A", value)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for bot balance %q", value)
+		}
+	}
+}
+
+func TestLoad_TokenTransferAmount(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TokenTransferAmount != "1" {
+		t.Errorf("expected default transfer amount 1, got %q", cfg.TokenTransferAmount)
+	}
+
+	defer os.Unsetenv("TOKEN_TRANSFER_AMOUNT")
+	for value, valid := range map[string]bool{
+		"0.5 KEVZ": true,
+		"0.000001": true,
+		"0":        false,
+		"-1":       false,
+		"1,5":      false,
+		"1 2 3":    false,
+	} {
+		os.Setenv("TOKEN_TRANSFER_AMOUNT", value)
+		cfg, err := Load()
+		if valid && (err != nil || cfg.TokenTransferAmount != value) {
+			t.Errorf("%q: expected a valid amount, got %v", value, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("%q: expected error", value)
+		}
+	}
+}
+
+func TestLoad_TokenTransfers(t *testing.T) {
+	defer os.Unsetenv("TOKEN_TRANSFERS")
+	os.Setenv("TOKEN_TRANSFERS", " 1 KEVZ, 2.5 USDC ")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.TokenTransfers) != 2 || cfg.TokenTransfers[0] != "1 KEVZ" || cfg.TokenTransfers[1] != "2.5 USDC" {
+		t.Errorf("unexpected transfers: %q", cfg.TokenTransfers)
+	}
+
+	for _, value := range []string{"1", "1 KEVZ,", "0 KEVZ", "1 KEVZ USDC"} {
+		os.Setenv("TOKEN_TRANSFERS", value)
+		if _, err := Load(); err == nil {
+			t.Errorf("%q: expected error", value)
+		}
+	}
+}
+
+func TestLoad_InvalidLogSwapEvery(t *testing.T) {
+	os.Setenv("LOG_SWAP_EVERY", "-2")
+	defer os.Unsetenv("LOG_SWAP_EVERY")
+
+	_, err := Load()
+	if err == nil {
+		t.Error("expected error for negative LOG_SWAP_EVERY")
+	}
+}
+
+func TestLoad_OptionalPaths(t *testing.T) {
+	os.Unsetenv("REPORT_DIR")
+	os.Unsetenv("TX_DB_PATH")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ReportDir != "" || cfg.TxDBPath != "" {
+		t.Errorf("expected no default paths, got %q and %q", cfg.ReportDir, cfg.TxDBPath)
+	}
+
+	// opt in to the run report and the tx history
+	os.Setenv("REPORT_DIR", "reports")
+	os.Setenv("TX_DB_PATH", "data/txs.db")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ReportDir != "reports" || cfg.TxDBPath != "data/txs.db" {
+		t.Errorf("expected the env to enable the features, got %q and %q", cfg.ReportDir, cfg.TxDBPath)
+	}
+
+	os.Setenv("REPORT_DIR", "")
+	os.Setenv("TX_DB_PATH", "")
+	defer func() {
+		os.Unsetenv("REPORT_DIR")
+		os.Unsetenv("TX_DB_PATH")
+	}()
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ReportDir != "" || cfg.TxDBPath != "" {
+		t.Errorf("empty values should disable the features, got %q and %q", cfg.ReportDir, cfg.TxDBPath)
+	}
+}
+
+func TestLoad_Shutdown(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ShutdownTimeout != 30*time.Second || !cfg.ShutdownWaitReceipts {
+		t.Errorf("unexpected shutdown defaults: %s, %v", cfg.ShutdownTimeout, cfg.ShutdownWaitReceipts)
+	}
+
+	os.Setenv("SHUTDOWN_TIMEOUT", "soon")
+	defer os.Unsetenv("SHUTDOWN_TIMEOUT")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for invalid SHUTDOWN_TIMEOUT")
+	}
+}
+
+func TestLoad_CandleIntervals(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.CandleIntervals) != 3 || cfg.CandleIntervals[2] != 5*time.Minute {
+		t.Errorf("unexpected default candle intervals: %v", cfg.CandleIntervals)
+	}
+
+	os.Setenv("CANDLE_INTERVALS", "")
+	defer os.Unsetenv("CANDLE_INTERVALS")
+	if cfg, err := Load(); err != nil || len(cfg.CandleIntervals) != 0 {
+		t.Errorf("empty value should disable candles, got %v (%v)", cfg.CandleIntervals, err)
+	}
+
+	os.Setenv("CANDLE_INTERVALS", "1s,often")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for invalid CANDLE_INTERVALS")
+	}
+}
+
+func TestLoad_OnChainPool(t *testing.T) {
+	t.Setenv("POOL_ROUTER_ADDRESS", "0x00000000000000000000000000000000000000a1")
+	t.Setenv("POOL_PAIR_ADDRESS", "0x00000000000000000000000000000000000000a2")
+	t.Setenv("TOKEN_ADDRESS", "0x00000000000000000000000000000000000000b0")
+	t.Setenv("NEXUS_PRIVATE_KEY", testKey)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PoolTokenA != "0x00000000000000000000000000000000000000b0" || cfg.PoolSlippageBps != 50 {
+		t.Errorf("expected TOKEN_ADDRESS as TokenA and 50 bps, got %q and %d", cfg.PoolTokenA, cfg.PoolSlippageBps)
+	}
+
+	t.Setenv("POOL_SLIPPAGE_BPS", "10000")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for a 100% slippage")
+	}
+	t.Setenv("POOL_SLIPPAGE_BPS", "")
+
+	t.Setenv("POOL_PAIR_ADDRESS", "")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for a router without a pair")
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeConfig(t, `
+rpc:
+  endpoints: [http://localhost:8545, "wss://backup.example.org"]
+  chain_id: 1337
+wallet:
+  address: "`+testWallet+`"
+  private_key: `+testKey+`
+tokens:
+  list:
+    - symbol: ETH
+      decimals: 18
+    - address: "0x00000000000000000000000000000000000000b0"
+  transfers: [1 KEVZ, 2.5 USDC]
+bots:
+  count: 4
+  strategy: momentum
+  balance_b: 1000000000000000000000
+  overrides:
+    - id: 2
+      strategy: mean-reversion
+    - id: 4
+      wallet:
+        address: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+        private_key: 59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d
+pool:
+  symbol_a: KEVZ
+  symbol_b: USDC
+  reserve_a: 5000000
+  reserve_b: 7000.5 USDC
+cadence:
+  swap_interval: 250ms
+  tx_interval: 1m
+  candle_intervals: []
+limits:
+  max_bots: 10
+  shutdown_timeout: 5s
+observability:
+  report_dir: ""
+  log_format: json
+`)
+	t.Setenv("BOT_COUNT", "5")
+	t.Setenv("TX_INTERVAL", "30s")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.File != path || len(cfg.RPCURLs) != 2 || cfg.RPCURLs[1] != "wss://backup.example.org" || cfg.ExpectedChainID != 1337 || cfg.WalletAddress != testWallet {
+		t.Errorf("unexpected rpc/wallet: %+v", cfg)
+	}
+	if len(cfg.Tokens) != 2 || cfg.Tokens[0].Symbol != "ETH" || *cfg.Tokens[0].Decimals != 18 || len(cfg.TokenTransfers) != 2 {
+		t.Errorf("unexpected tokens: %+v, %q", cfg.Tokens, cfg.TokenTransfers)
+	}
+	if cfg.BotStrategy != "momentum" || cfg.BotBalanceB != "1000000000000000000000" || cfg.BotBalanceA != "1000000" {
+		t.Errorf("unexpected bots section: %s, %s, %s", cfg.BotStrategy, cfg.BotBalanceA, cfg.BotBalanceB)
+	}
+	if len(cfg.Bots) != 2 || cfg.Bots[0].Strategy != "mean-reversion" || cfg.Bots[1].ID != 4 || cfg.Bots[1].PrivateKey == "" {
+		t.Errorf("unexpected overrides: %+v", cfg.Bots)
+	}
+	if cfg.PoolSymbolA != "KEVZ" || cfg.PoolReserveA != "5000000" || cfg.PoolReserveB != "7000.5 USDC" {
+		t.Errorf("unexpected pool: %s %s/%s", cfg.PoolSymbolA, cfg.PoolReserveA, cfg.PoolReserveB)
+	}
+	if cfg.SwapInterval != 250*time.Millisecond || len(cfg.CandleIntervals) != 0 || cfg.MaxBots != 10 || cfg.ShutdownTimeout != 5*time.Second {
+		t.Errorf("unexpected cadence/limits: %s, %v, %d, %s", cfg.SwapInterval, cfg.CandleIntervals, cfg.MaxBots, cfg.ShutdownTimeout)
+	}
+	if cfg.ReportDir != "" || cfg.LogFormat != "json" || cfg.TxDBPath != "" {
+		t.Errorf("unexpected observability: %q, %q, %q", cfg.ReportDir, cfg.LogFormat, cfg.TxDBPath)
+	}
+
+	// environment variables override the file
+	if cfg.BotCount != 5 || cfg.TxInterval != 30*time.Second {
+		t.Errorf("expected the env to override the file, got %d bots every %s", cfg.BotCount, cfg.TxInterval)
+	}
+}
+
+func TestLoadFile_Malformed(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown key":   "rpc:\n  endpoint: http://localhost:8545\n",
+		"wrong type":    "bots:\n  count: many\n",
+		"bad amount":    "pool:\n  reserve_a: 1.5\n",
+		"bad duration":  "cadence:\n  tx_interval: soon\n",
+		"not a mapping": "- rpc\n",
+		"bad syntax":    "rpc: [\n",
+	} {
+		var verr *ValidationError
+		if _, err := LoadFile(writeConfig(t, content)); !errors.As(err, &verr) {
+			t.Errorf("%s: expected a ValidationError, got %v", name, err)
+		}
+	}
+
+	// YAML errors, env errors and failed checks are reported together
+	t.Setenv("TX_INTERVAL", "soon")
+	_, err := LoadFile(writeConfig(t, "bots:\n  count: many\n  strategy: smart\npool:\n  reserve_a: 1.5\n"))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Problems) != 4 {
+		t.Fatalf("expected 4 problems, got %v", err)
+	}
+	for i, want := range []string{"`many`", "TX_INTERVAL", "bots.strategy", "amount"} {
+		if !strings.Contains(verr.Problems[i], want) {
+			t.Errorf("problem %d: expected %q in %q", i, want, verr.Problems[i])
+		}
+	}
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+
+	// an empty file keeps the defaults
+	t.Setenv("TX_INTERVAL", "")
+	cfg, err := LoadFile(writeConfig(t, ""))
+	if err != nil || cfg.BotCount != 3 {
+		t.Errorf("expected the defaults from an empty file, got %v", err)
+	}
+}
+
+func TestLoadFile_Pools(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, `
+pools:
+  - symbol_a: ETH
+    symbol_b: USDC
+    reserve_a: 1000 ETH
+    reserve_b: 2000000 USDC
+  - symbol_a: KEVZ
+    symbol_b: USDC
+    reserve_a: 5000000
+    reserve_b: 7000000
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pools := cfg.SimulatedPools()
+	if len(pools) != 2 || pools[0].ReserveA != "1000 ETH" || pools[1].SymbolA != "KEVZ" || pools[1].ReserveB != "7000000" {
+		t.Errorf("unexpected pools: %+v", pools)
+	}
+
+	// without a list the pool section is the only pool
+	if pools := Default().SimulatedPools(); len(pools) != 1 || pools[0].SymbolA != Default().PoolSymbolA {
+		t.Errorf("expected the pool section, got %+v", pools)
+	}
+}
+
+func TestValidate_Pools(t *testing.T) {
+	cfg := Default()
+	cfg.PoolPairAddress = "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+	cfg.BotBalanceA = "10 ETH"
+	cfg.Pools = []PoolConfig{
+		{SymbolA: "ETH", SymbolB: "USDC", ReserveA: "1000000", ReserveB: "2000000"},
+		{SymbolA: "KEVZ", SymbolB: "KEVZ", ReserveA: "1000000", ReserveB: "many"},
+		{SymbolA: "usdc", SymbolB: "eth", ReserveA: "1000000", ReserveB: "2000000"},
+	}
+
+	err := cfg.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	for _, want := range []string{
+		"pools, pool.pair", "pools[1].symbol_a, pools[1].symbol_b", "pools[1].reserve_b", "pools[2]: usdc/eth is already listed as pools[0]",
+		"bots.balance_a (BOT_BALANCE_A): \"10 ETH\" is not in KEVZ", "bots.balance_a (BOT_BALANCE_A): \"10 ETH\" is not in usdc",
+	} {
+		found := false
+		for _, problem := range verr.Problems {
+			found = found || strings.HasPrefix(problem, want)
+		}
+		if !found {
+			t.Errorf("expected a problem about %s in:\n%v", want, err)
+		}
+	}
+	// plus the two problems of the incomplete on-chain pool
+	if len(verr.Problems) != 8 {
+		t.Errorf("expected 8 problems, got %d:\n%v", len(verr.Problems), err)
+	}
+}
+
+func TestLoadFile_Example(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join("..", "..", "config.example.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	def := Default()
+	if cfg.PoolReserveA != def.PoolReserveA || cfg.SwapInterval != def.SwapInterval || cfg.TxInterval != def.TxInterval {
+		t.Errorf("expected the example to document the defaults, got %s, %s, %s", cfg.PoolReserveA, cfg.SwapInterval, cfg.TxInterval)
+	}
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("defaults must be valid: %v", err)
+	}
+
+	cfg.RPCURLs = []string{"localhost:8545"}
+	cfg.WalletAddress = "0xYourAddressHere"
+	cfg.PrivateKey = "0x" + testKey
+	cfg.BotStrategy = "grid"
+	cfg.PoolReserveA = ""
+	cfg.TxInterval = 0
+	cfg.LogLevel = "loud"
+	cfg.Bots = []BotConfig{{ID: 9}, {ID: 1, WalletAddress: testWallet}}
+
+	err := cfg.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	for _, want := range []string{
+		"rpc.endpoints[0]", "wallet.address (WALLET_ADDRESS)", "wallet.private_key (NEXUS_PRIVATE_KEY)", "bots.strategy",
+		"pool.reserve_a", "cadence.tx_interval", "observability.log_level", "bots.overrides[0].id", "bots.overrides[1].wallet",
+	} {
+		found := false
+		for _, problem := range verr.Problems {
+			found = found || strings.HasPrefix(problem, want)
+		}
+		if !found {
+			t.Errorf("expected a problem about %s in:\n%v", want, err)
+		}
+	}
+	if len(verr.Problems) != 9 {
+		t.Errorf("expected 9 problems, got %d:\n%v", len(verr.Problems), err)
+	}
+	if strings.Contains(err.Error(), testKey) {
+		t.Error("the private key must not appear in errors")
+	}
+}
+
+func TestValidate_Wallets(t *testing.T) {
+	cfg := Default()
+	cfg.WalletAddress = "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+	cfg.PrivateKey = testKey
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "the key signs for "+testWallet) {
+		t.Errorf("expected a key/address mismatch, got %v", err)
+	}
+
+	cfg.WalletAddress = strings.ToLower(testWallet)
+	cfg.Bots = []BotConfig{{ID: 2, Strategy: "momentum", WalletAddress: testWallet, PrivateKey: testKey}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Bots = append(cfg.Bots, BotConfig{ID: 2})
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for a bot overridden twice")
+	}
+}
+
+func TestParseIntervals(t *testing.T) {
+	intervals, err := parseIntervals("1s, 1m,5m")
+	if err != nil || len(intervals) != 3 || intervals[1] != time.Minute {
+		t.Errorf("unexpected intervals: %v (%v)", intervals, err)
+	}
+	if intervals, err := parseIntervals(""); err != nil || len(intervals) != 0 {
+		t.Errorf("empty spec should give no intervals: %v (%v)", intervals, err)
+	}
+	for _, spec := range []string{"1x", "0s", "-1m"} {
+		if _, err := parseIntervals(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+// the config keeps its own copy of the swarm defaults and strategy names
+func TestDefaults_MatchSwarm(t *testing.T) {
+	if DefaultSwapInterval != swarm.DefaultSwapInterval || DefaultTxInterval != swarm.DefaultTxInterval ||
+		DefaultSwapLogEvery != swarm.DefaultSwapLogEvery {
+		t.Error("config defaults differ from the swarm defaults")
+	}
+	for _, name := range Strategies {
+		if _, err := swarm.NewStrategy(name, nil); err != nil {
+			t.Errorf("strategy %q: %v", name, err)
+		}
+	}
+}
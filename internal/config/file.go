@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// file is the layout of a YAML config file, see config.example.yaml
+// Every field is optional: a field left out keeps its default
+type file struct {
+	RPC struct {
+		Endpoints *[]string `yaml:"endpoints"`
+		ChainID   *int64    `yaml:"chain_id"`
+	} `yaml:"rpc"`
+
+	Wallet fileWallet `yaml:"wallet"`
+
+	Tokens struct {
+		Address        *string       `yaml:"address"`
+		File           *string       `yaml:"file"`
+		List           []TokenConfig `yaml:"list"`
+		TransferAmount *string       `yaml:"transfer_amount"`
+		Transfers      *[]string     `yaml:"transfers"`
+	} `yaml:"tokens"`
+
+	Bots struct {
+		Count     *int      `yaml:"count"`
+		Strategy  *string   `yaml:"strategy"`
+		BalanceA  *string   `yaml:"balance_a"`
+		BalanceB  *string   `yaml:"balance_b"`
+		Overrides []fileBot `yaml:"overrides"`
+	} `yaml:"bots"`
+
+	Pool struct {
+		SymbolA     *string `yaml:"symbol_a"`
+		SymbolB     *string `yaml:"symbol_b"`
+		ReserveA    *string `yaml:"reserve_a"`
+		ReserveB    *string `yaml:"reserve_b"`
+		Router      *string `yaml:"router"`
+		Pair        *string `yaml:"pair"`
+		TokenA      *string `yaml:"token_a"`
+		SlippageBps *int64  `yaml:"slippage_bps"`
+	} `yaml:"pool"`
+
+	Pools []PoolConfig `yaml:"pools"`
+
+	Cadence struct {
+		SwapInterval    *time.Duration `yaml:"swap_interval"`
+		TxInterval      *time.Duration `yaml:"tx_interval"`
+		CandleIntervals *[]string      `yaml:"candle_intervals"`
+	} `yaml:"cadence"`
+
+	Limits struct {
+		MaxBots              *int           `yaml:"max_bots"`
+		ShutdownTimeout      *time.Duration `yaml:"shutdown_timeout"`
+		ShutdownWaitReceipts *bool          `yaml:"shutdown_wait_receipts"`
+	} `yaml:"limits"`
+
+	Observability struct {
+		MetricsAddr  *string `yaml:"metrics_addr"`
+		AdminAddr    *string `yaml:"admin_addr"`
+		LogLevel     *string `yaml:"log_level"`
+		LogFormat    *string `yaml:"log_format"`
+		LogSwapEvery *int    `yaml:"log_swap_every"`
+		ReportDir    *string `yaml:"report_dir"`
+		TxDBPath     *string `yaml:"tx_db_path"`
+	} `yaml:"observability"`
+}
+
+type fileWallet struct {
+	Address    *string `yaml:"address"`
+	PrivateKey *string `yaml:"private_key"`
+}
+
+type fileBot struct {
+	ID       int        `yaml:"id"`
+	Strategy string     `yaml:"strategy"`
+	Wallet   fileWallet `yaml:"wallet"`
+}
+
+// applyFile overrides the config with the fields set in a YAML file
+// Only a file that cannot be read is returned as an error. Malformed YAML,
+// unknown keys and values of the wrong type are recorded in p, and the keys
+// that did decode are still applied
+func (c *Config) applyFile(path string, p *problems) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	c.File = path
+
+	var f file
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			// syntax error: nothing was decoded
+			p.add("%s: %s", path, strings.TrimPrefix(err.Error(), "yaml: "))
+			return nil
+		}
+		for _, msg := range typeErr.Errors {
+			p.add("%s: %s", path, msg)
+		}
+	}
+
+	set(&c.RPCURLs, f.RPC.Endpoints)
+	set(&c.ExpectedChainID, f.RPC.ChainID)
+	set(&c.WalletAddress, f.Wallet.Address)
+	set(&c.PrivateKey, f.Wallet.PrivateKey)
+
+	set(&c.TokenAddress, f.Tokens.Address)
+	set(&c.TokensFile, f.Tokens.File)
+	c.Tokens = append(c.Tokens, f.Tokens.List...)
+	set(&c.TokenTransferAmount, f.Tokens.TransferAmount)
+	set(&c.TokenTransfers, f.Tokens.Transfers)
+
+	set(&c.BotCount, f.Bots.Count)
+	set(&c.BotStrategy, f.Bots.Strategy)
+	set(&c.BotBalanceA, f.Bots.BalanceA)
+	set(&c.BotBalanceB, f.Bots.BalanceB)
+	for _, bot := range f.Bots.Overrides {
+		override := BotConfig{ID: bot.ID, Strategy: bot.Strategy}
+		set(&override.WalletAddress, bot.Wallet.Address)
+		set(&override.PrivateKey, bot.Wallet.PrivateKey)
+		c.Bots = append(c.Bots, override)
+	}
+
+	c.Pools = append(c.Pools, f.Pools...)
+	set(&c.PoolSymbolA, f.Pool.SymbolA)
+	set(&c.PoolSymbolB, f.Pool.SymbolB)
+	set(&c.PoolReserveA, f.Pool.ReserveA)
+	set(&c.PoolReserveB, f.Pool.ReserveB)
+	set(&c.PoolRouterAddress, f.Pool.Router)
+	set(&c.PoolPairAddress, f.Pool.Pair)
+	set(&c.PoolTokenA, f.Pool.TokenA)
+	set(&c.PoolSlippageBps, f.Pool.SlippageBps)
+
+	set(&c.SwapInterval, f.Cadence.SwapInterval)
+	set(&c.TxInterval, f.Cadence.TxInterval)
+	if f.Cadence.CandleIntervals != nil {
+		intervals, err := parseIntervals(strings.Join(*f.Cadence.CandleIntervals, ","))
+		if err != nil {
+			p.add("cadence.candle_intervals: %v", err)
+		} else {
+			c.CandleIntervals = intervals
+		}
+	}
+
+	set(&c.MaxBots, f.Limits.MaxBots)
+	set(&c.ShutdownTimeout, f.Limits.ShutdownTimeout)
+	set(&c.ShutdownWaitReceipts, f.Limits.ShutdownWaitReceipts)
+
+	set(&c.MetricsAddr, f.Observability.MetricsAddr)
+	set(&c.AdminAddr, f.Observability.AdminAddr)
+	set(&c.LogLevel, f.Observability.LogLevel)
+	set(&c.LogFormat, f.Observability.LogFormat)
+	set(&c.LogSwapEvery, f.Observability.LogSwapEvery)
+	set(&c.ReportDir, f.Observability.ReportDir)
+	set(&c.TxDBPath, f.Observability.TxDBPath)
+	return nil
+}
+
+// set copies a file value over the default when the file sets it
+func set[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/internal/logging"
+)
+
+// Validate checks the whole configuration and returns a *ValidationError
+// listing every problem, or nil. Problems name the config file key and, in
+// parentheses, the environment variable that sets it
+func (c *Config) Validate() error {
+	var p problems
+
+	if len(c.RPCURLs) == 0 {
+		p.add("rpc.endpoints (NEXUS_RPC_URL): at least one endpoint is required")
+	}
+	seenURLs := make(map[string]bool)
+	for i, rpcURL := range c.RPCURLs {
+		if u, err := url.Parse(rpcURL); err != nil || u.Host == "" ||
+			(u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss") {
+			p.add("rpc.endpoints[%d] (NEXUS_RPC_URL): %q is not an http(s) or ws(s) URL", i, rpcURL)
+		} else if seenURLs[rpcURL] {
+			p.add("rpc.endpoints[%d] (NEXUS_RPC_URL): %q is listed twice", i, rpcURL)
+		}
+		seenURLs[rpcURL] = true
+	}
+	if c.ExpectedChainID <= 0 {
+		p.add("rpc.chain_id (NEXUS_CHAIN_ID): must be positive, got %d", c.ExpectedChainID)
+	}
+
+	checkWallet(&p, "wallet", "WALLET_ADDRESS", "NEXUS_PRIVATE_KEY", c.WalletAddress, c.PrivateKey)
+
+	if c.BotCount < 0 {
+		p.add("bots.count (BOT_COUNT): must not be negative, got %d", c.BotCount)
+	}
+	if c.MaxBots < 0 {
+		p.add("limits.max_bots (MAX_BOTS): must not be negative, got %d", c.MaxBots)
+	} else if c.MaxBots > 0 && c.BotCount > c.MaxBots {
+		p.add("bots.count (BOT_COUNT): %d bots is more than limits.max_bots (%d)", c.BotCount, c.MaxBots)
+	}
+	if !slices.Contains(Strategies, c.BotStrategy) {
+		p.add("bots.strategy (BOT_STRATEGY): unknown strategy %q, one of %s", c.BotStrategy, strings.Join(Strategies, ", "))
+	}
+	checkAmount(&p, "bots.balance_a (BOT_BALANCE_A)", c.BotBalanceA)
+	checkAmount(&p, "bots.balance_b (BOT_BALANCE_B)", c.BotBalanceB)
+	c.validateBots(&p)
+
+	checkAddress(&p, "tokens.address (TOKEN_ADDRESS)", c.TokenAddress)
+	if err := validateTokenAmount(c.TokenTransferAmount); err != nil {
+		p.add("tokens.transfer_amount (TOKEN_TRANSFER_AMOUNT): %v", err)
+	}
+	for i, transfer := range c.TokenTransfers {
+		if len(strings.Fields(transfer)) != 2 {
+			p.add("tokens.transfers[%d] (TOKEN_TRANSFERS): %q must be an amount and a token symbol", i, transfer)
+		} else if err := validateTokenAmount(transfer); err != nil {
+			p.add("tokens.transfers[%d] (TOKEN_TRANSFERS): %v", i, err)
+		}
+	}
+	for i, entry := range c.Tokens {
+		name := fmt.Sprintf("tokens.list[%d]", i)
+		if entry.Address == "" && (entry.Symbol == "" || entry.Decimals == nil) {
+			p.add("%s: needs an address, or both symbol and decimals", name)
+		}
+		checkAddress(&p, name+".address", entry.Address)
+		if entry.Decimals != nil && *entry.Decimals > domain.MaxDecimals {
+			p.add("%s.decimals: %d is more than %d", name, *entry.Decimals, domain.MaxDecimals)
+		}
+	}
+
+	if len(c.Pools) == 0 {
+		checkPool(&p, "pool", "POOL_RESERVE_A", "POOL_RESERVE_B", c.SimulatedPools()[0])
+	} else {
+		c.validatePools(&p)
+	}
+	checkAddress(&p, "pool.router (POOL_ROUTER_ADDRESS)", c.PoolRouterAddress)
+	checkAddress(&p, "pool.pair (POOL_PAIR_ADDRESS)", c.PoolPairAddress)
+	checkAddress(&p, "pool.token_a (POOL_TOKEN_A)", c.PoolTokenA)
+	if (c.PoolRouterAddress == "") != (c.PoolPairAddress == "") {
+		p.add("pool.router, pool.pair (POOL_ROUTER_ADDRESS, POOL_PAIR_ADDRESS): must be set together")
+	}
+	if c.PoolPairAddress != "" && (c.PoolTokenA == "" || c.PrivateKey == "") {
+		p.add("pool.pair (POOL_PAIR_ADDRESS): an on-chain pool needs NEXUS_PRIVATE_KEY and POOL_TOKEN_A (or TOKEN_ADDRESS)")
+	}
+	if c.PoolSlippageBps < 0 || c.PoolSlippageBps >= 10000 {
+		p.add("pool.slippage_bps (POOL_SLIPPAGE_BPS): must be in [0, 10000), got %d", c.PoolSlippageBps)
+	}
+
+	if c.SwapInterval <= 0 {
+		p.add("cadence.swap_interval (SWAP_INTERVAL): must be positive, got %s", c.SwapInterval)
+	}
+	if c.TxInterval <= 0 {
+		p.add("cadence.tx_interval (TX_INTERVAL): must be positive, got %s", c.TxInterval)
+	}
+	for _, interval := range c.CandleIntervals {
+		if interval <= 0 {
+			p.add("cadence.candle_intervals (CANDLE_INTERVALS): must be positive, got %s", interval)
+		}
+	}
+	if c.ShutdownTimeout < 0 {
+		p.add("limits.shutdown_timeout (SHUTDOWN_TIMEOUT): must not be negative, got %s", c.ShutdownTimeout)
+	}
+
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		p.add("observability.log_level (LOG_LEVEL): %v", err)
+	} else if _, err := logging.New(io.Discard, c.LogFormat, c.LogLevel); err != nil {
+		p.add("observability.log_format (LOG_FORMAT): %v", err)
+	}
+	if c.LogSwapEvery < 0 {
+		p.add("observability.log_swap_every (LOG_SWAP_EVERY): must not be negative, got %d", c.LogSwapEvery)
+	}
+
+	return p.err()
+}
+
+// validatePools checks the pools list: each pool, that no pair is listed
+// twice, and that the bot balances fit every pool
+func (c *Config) validatePools(p *problems) {
+	if c.PoolPairAddress != "" {
+		p.add("pools, pool.pair (POOL_PAIR_ADDRESS): the pools list is simulated, it cannot be combined with an on-chain pair")
+	}
+	seen := make(map[string]int)
+	for i, pool := range c.Pools {
+		key := fmt.Sprintf("pools[%d]", i)
+		checkPool(p, key, "", "", pool)
+
+		// a pair listed the other way round is the same pool
+		symbols := []string{strings.ToUpper(pool.SymbolA), strings.ToUpper(pool.SymbolB)}
+		slices.Sort(symbols)
+		pair := strings.Join(symbols, "/")
+		if first, ok := seen[pair]; ok {
+			p.add("%s: %s/%s is already listed as pools[%d]", key, pool.SymbolA, pool.SymbolB, first)
+		} else {
+			seen[pair] = i
+		}
+
+		// balances in whole tokens must be in the tokens of every pool
+		for _, balance := range []struct{ name, value, symbol string }{
+			{"bots.balance_a (BOT_BALANCE_A)", c.BotBalanceA, pool.SymbolA},
+			{"bots.balance_b (BOT_BALANCE_B)", c.BotBalanceB, pool.SymbolB},
+		} {
+			if fields := strings.Fields(balance.value); len(fields) == 2 && !strings.EqualFold(fields[1], balance.symbol) {
+				p.add("%s: %q is not in %s, the token of %s; use raw units with pools of different tokens",
+					balance.name, balance.value, balance.symbol, key)
+			}
+		}
+	}
+}
+
+// checkPool checks the symbols and reserves of a simulated pool under key
+// envA and envB name the env vars of the reserves, if any
+func checkPool(p *problems, key, envA, envB string, pool PoolConfig) {
+	if pool.SymbolA == "" || pool.SymbolB == "" || strings.EqualFold(pool.SymbolA, pool.SymbolB) {
+		p.add("%[1]s.symbol_a, %[1]s.symbol_b: need two different symbols, got %[2]q and %[3]q", key, pool.SymbolA, pool.SymbolB)
+	}
+	nameA, nameB := key+".reserve_a", key+".reserve_b"
+	names := nameA + ", " + nameB
+	if envA != "" {
+		nameA += " (" + envA + ")"
+		nameB += " (" + envB + ")"
+		names += " (" + envA + ", " + envB + ")"
+	}
+	reserveA := checkAmount(p, nameA, pool.ReserveA)
+	reserveB := checkAmount(p, nameB, pool.ReserveB)
+	if reserveA != nil && reserveB != nil {
+		// reserves in whole tokens are checked once the decimals are known
+		if _, err := domain.NewPool(pool.SymbolA, pool.SymbolB, reserveA, reserveB); err != nil {
+			p.add("%s: %v", names, err)
+		}
+	}
+}
+
+// validateBots checks the per-bot overrides
+func (c *Config) validateBots(p *problems) {
+	seen := make(map[int]bool)
+	for i, bot := range c.Bots {
+		name := fmt.Sprintf("bots.overrides[%d]", i)
+		if bot.ID < 1 || bot.ID > c.BotCount {
+			p.add("%s.id: must be a bot ID from 1 to %d, got %d", name, c.BotCount, bot.ID)
+		} else if seen[bot.ID] {
+			p.add("%s.id: bot %d is overridden twice", name, bot.ID)
+		}
+		seen[bot.ID] = true
+
+		if bot.Strategy != "" {
+			if !slices.Contains(Strategies, bot.Strategy) {
+				p.add("%s.strategy: unknown strategy %q, one of %s", name, bot.Strategy, strings.Join(Strategies, ", "))
+			}
+		}
+		if bot.WalletAddress == "" && bot.PrivateKey == "" {
+			continue
+		}
+		if bot.WalletAddress == "" || bot.PrivateKey == "" {
+			p.add("%s.wallet: address and private_key must be set together", name)
+			continue
+		}
+		if c.WalletAddress == "" || c.PrivateKey == "" {
+			p.add("%s.wallet: bot wallets need the swarm wallet (WALLET_ADDRESS and NEXUS_PRIVATE_KEY)", name)
+		}
+		checkWallet(p, name+".wallet", "", "", bot.WalletAddress, bot.PrivateKey)
+	}
+}
+
+// checkWallet checks an address and a private key, and that the key signs
+// for the address when both are set
+func checkWallet(p *problems, name, addressEnv, keyEnv, address, privateKey string) {
+	addressName, keyName := name+".address", name+".private_key"
+	if addressEnv != "" {
+		addressName += " (" + addressEnv + ")"
+		keyName += " (" + keyEnv + ")"
+	}
+	checkAddress(p, addressName, address)
+	if privateKey == "" {
+		return
+	}
+	key, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		// never echo the key
+		p.add("%s: not a hex private key without 0x prefix", keyName)
+		return
+	}
+	if derived := crypto.PubkeyToAddress(key.PublicKey); common.IsHexAddress(address) && derived != common.HexToAddress(address) {
+		p.add("%s: the key signs for %s, not %s", keyName, derived.Hex(), address)
+	}
+}
+
+// checkAddress checks an optional hex address
+func checkAddress(p *problems, name, address string) {
+	if address != "" && !common.IsHexAddress(address) {
+		p.add("%s: %q is not a hex address", name, address)
+	}
+}
+
+// checkAmount checks a pool reserve or bot balance: raw units ("1000000"), or
+// whole tokens followed by the symbol ("1.5 ETH") at any precision, since the
+// decimals are only known once the token registry is resolved
+// Returns the raw units, nil for whole tokens or an invalid amount
+func checkAmount(p *problems, name, value string) *big.Int {
+	if fields := strings.Fields(value); len(fields) == 2 {
+		amount, err := domain.ParseTokenAmount(value, domain.Token{Symbol: fields[1], Decimals: domain.MaxDecimals})
+		if err != nil {
+			p.add("%s: %v", name, err)
+		} else if amount.Sign() < 0 {
+			p.add("%s: must not be negative", name)
+		}
+		return nil
+	}
+	raw, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		p.add("%s: invalid amount %q, use raw units or whole tokens with the symbol (\"1.5 ETH\")", name, value)
+		return nil
+	}
+	if raw.Sign() < 0 {
+		p.add("%s: must not be negative", name)
+		return nil
+	}
+	return raw
+}
+
+// validateTokenAmount checks a positive human amount such as "1.5 KEVZ"
+// The decimals are only known once the token is read from chain, so any
+// precision up to domain.MaxDecimals is accepted here
+func validateTokenAmount(value string) error {
+	token := domain.Token{Decimals: domain.MaxDecimals}
+	if fields := strings.Fields(value); len(fields) == 2 {
+		token.Symbol = fields[1]
+	}
+	amount, err := domain.ParseTokenAmount(value, token)
+	if err != nil {
+		return err
+	}
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("%q must be positive", value)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Package deploy deploys the compiled contracts shipped in contracts/ and
+// records their addresses in the .env file
+package deploy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNoBytecode is returned when a contract has no compiled .bin next to its ABI
+var ErrNoBytecode = errors.New("contract bytecode not found")
+
+// ErrNoCode is returned when a deployment is mined but left no code behind
+var ErrNoCode = errors.New("no code at deployed address")
+
+// Artifact is a compiled contract: its ABI and creation bytecode
+type Artifact struct {
+	Name     string
+	ABI      abi.ABI
+	Bytecode []byte
+}
+
+// LoadArtifact reads <dir>/<name>.abi and <dir>/<name>.bin
+// The .bin is the hex creation bytecode written by solc --bin (or Remix)
+func LoadArtifact(dir, name string) (*Artifact, error) {
+	abiPath := filepath.Join(dir, name+".abi")
+	abiJSON, err := os.ReadFile(abiPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ABI: %w", err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(string(abiJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ABI %s: %w", abiPath, err)
+	}
+
+	binPath := filepath.Join(dir, name+".bin")
+	binHex, err := os.ReadFile(binPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s is missing, compile %s.sol first (solc --bin --abi -o %s %s.sol)",
+			ErrNoBytecode, binPath, name, dir, filepath.Join(dir, name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bytecode: %w", err)
+	}
+	bytecode, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(binHex)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid bytecode in %s: %w", binPath, err)
+	}
+	if len(bytecode) == 0 {
+		return nil, fmt.Errorf("%w: %s is empty", ErrNoBytecode, binPath)
+	}
+
+	return &Artifact{Name: name, ABI: parsed, Bytecode: bytecode}, nil
+}
+
+// ParseArgs converts command line values to the constructor argument types
+// Supports address, bool, string and integers wider than 64 bits
+func (a *Artifact) ParseArgs(values []string) ([]interface{}, error) {
+	inputs := a.ABI.Constructor.Inputs
+	if len(values) != len(inputs) {
+		return nil, fmt.Errorf("%s constructor takes %d arguments, got %d", a.Name, len(inputs), len(values))
+	}
+
+	args := make([]interface{}, len(inputs))
+	for i, input := range inputs {
+		value := values[i]
+		switch input.Type.T {
+		case abi.AddressTy:
+			if !common.IsHexAddress(value) {
+				return nil, fmt.Errorf("argument %s: invalid address %q", input.Name, value)
+			}
+			args[i] = common.HexToAddress(value)
+		case abi.BoolTy:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("argument %s: invalid bool %q", input.Name, value)
+			}
+			args[i] = b
+		case abi.StringTy:
+			args[i] = value
+		case abi.UintTy, abi.IntTy:
+			n, ok := new(big.Int).SetString(value, 10)
+			if !ok {
+				return nil, fmt.Errorf("argument %s: invalid integer %q", input.Name, value)
+			}
+			if input.Type.Size <= 64 {
+				// abi packs these from fixed-size Go types, none of our contracts use them
+				return nil, fmt.Errorf("argument %s: unsupported type %s", input.Name, input.Type)
+			}
+			args[i] = n
+		default:
+			return nil, fmt.Errorf("argument %s: unsupported type %s", input.Name, input.Type)
+		}
+	}
+	return args, nil
+}
+
+// Result describes a mined deployment
+type Result struct {
+	Address common.Address
+	TxHash  common.Hash
+	Block   uint64
+	GasUsed uint64
+}
+
+// Backend is what a deployment needs from an RPC client
+// *ethclient.Client and the simulated backend client implement it
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+}
+
+// Deploy sends the creation transaction from key, waits for the receipt and
+// checks that code exists at the new address
+// The wait is bounded by ctx: on timeout the tx may still be mined later
+func Deploy(ctx context.Context, backend Backend, key *ecdsa.PrivateKey, chainID *big.Int, artifact *Artifact, args ...interface{}) (*Result, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transactor: %w", err)
+	}
+	opts.Context = ctx
+
+	address, tx, _, err := bind.DeployContract(opts, artifact.ABI, artifact.Bytecode, backend, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s deployment: %w", artifact.Name, err)
+	}
+
+	receipt, err := bind.WaitMined(ctx, backend, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for deployment tx %s: %w", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("deployment tx %s reverted", tx.Hash().Hex())
+	}
+
+	code, err := backend.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read code at %s: %w", address.Hex(), err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoCode, address.Hex())
+	}
+
+	return &Result{
+		Address: address,
+		TxHash:  tx.Hash(),
+		Block:   receipt.BlockNumber.Uint64(),
+		GasUsed: receipt.GasUsed,
+	}, nil
+}
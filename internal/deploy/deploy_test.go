@@ -0,0 +1,186 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+const (
+	// init code returning a 1 byte runtime (STOP)
+	stopBytecode = "6001600c60003960016000f300"
+	// init code returning no runtime code
+	emptyBytecode = "60006000f3"
+)
+
+func writeArtifact(t *testing.T, dir, name, abiJSON, bin string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+".abi"), []byte(abiJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if bin != "" {
+		if err := os.WriteFile(filepath.Join(dir, name+".bin"), []byte(bin), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadArtifact(t *testing.T) {
+	dir := t.TempDir()
+	writeArtifact(t, dir, "Stop", "[]", "0x"+stopBytecode+"\n")
+	writeArtifact(t, dir, "NoBin", "[]", "")
+	writeArtifact(t, dir, "BadBin", "[]", "zz")
+
+	artifact, err := LoadArtifact(dir, "Stop")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if artifact.Name != "Stop" || len(artifact.Bytecode) != 13 {
+		t.Errorf("unexpected artifact: %s, %d bytes", artifact.Name, len(artifact.Bytecode))
+	}
+
+	_, err = LoadArtifact(dir, "NoBin")
+	if !errors.Is(err, ErrNoBytecode) || !strings.Contains(err.Error(), "solc") {
+		t.Errorf("expected ErrNoBytecode with a compile hint, got %v", err)
+	}
+	if _, err := LoadArtifact(dir, "BadBin"); err == nil {
+		t.Error("expected error for invalid hex")
+	}
+	if _, err := LoadArtifact(dir, "Missing"); err == nil {
+		t.Error("expected error for a missing ABI")
+	}
+}
+
+func TestArtifact_ParseArgs(t *testing.T) {
+	dir := t.TempDir()
+	writeArtifact(t, dir, "Pair", `[{"inputs":[{"name":"token","type":"address"},{"name":"fee","type":"uint256"},{"name":"name","type":"string"}],"stateMutability":"nonpayable","type":"constructor"}]`, stopBytecode)
+	artifact, err := LoadArtifact(dir, "Pair")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	args, err := artifact.ParseArgs([]string{"0x00000000000000000000000000000000000000b0", "30", "pool"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args[0] != common.HexToAddress("0xb0") || args[1].(*big.Int).Int64() != 30 || args[2] != "pool" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	for _, values := range [][]string{
+		{"0xb0"},
+		{"not-an-address", "30", "pool"},
+		{"0x00000000000000000000000000000000000000b0", "thirty", "pool"},
+	} {
+		if _, err := artifact.ParseArgs(values); err == nil {
+			t.Errorf("expected error for %v", values)
+		}
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	sim := simulated.NewBackend(types.GenesisAlloc{from: {Balance: big.NewInt(1e18)}})
+	defer sim.Close()
+	client := sim.Client()
+
+	// mine blocks while the deployment waits for its receipt
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(10 * time.Millisecond):
+				sim.Commit()
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	chainID, _ := client.ChainID(ctx)
+
+	dir := t.TempDir()
+	writeArtifact(t, dir, "Stop", "[]", stopBytecode)
+	writeArtifact(t, dir, "Empty", "[]", emptyBytecode)
+
+	artifact, _ := LoadArtifact(dir, "Stop")
+	result, err := Deploy(ctx, client, key, chainID, artifact)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Address != crypto.CreateAddress(from, 0) || result.Block == 0 || result.GasUsed == 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	empty, _ := LoadArtifact(dir, "Empty")
+	if _, err := Deploy(ctx, client, key, chainID, empty); !errors.Is(err, ErrNoCode) {
+		t.Errorf("expected ErrNoCode for a contract without runtime code, got %v", err)
+	}
+}
+
+func TestSetEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	// missing file: created
+	if err := SetEnv(path, "TOKEN_ADDRESS", "0x01"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "TOKEN_ADDRESS=0x01\n" {
+		t.Errorf("unexpected file: %q", data)
+	}
+
+	// the active assignment is replaced, not the commented example above it,
+	// and later active ones are disabled
+	os.WriteFile(path, []byte("BOT_COUNT=3\n# TOKEN_ADDRESS=0xexample\nTOKEN_ADDRESS=0xold\nTOKEN_ADDRESS=0xolder\nLOG_LEVEL=info\n"), 0o600)
+	os.Chmod(path, 0o640)
+	if err := SetEnv(path, "TOKEN_ADDRESS", "0x02"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	want := "BOT_COUNT=3\n# TOKEN_ADDRESS=0xexample\nTOKEN_ADDRESS=0x02\n# TOKEN_ADDRESS=0xolder\nLOG_LEVEL=info\n"
+	if string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o640 {
+		t.Errorf("expected the file mode to be kept, got %v", info.Mode().Perm())
+	}
+
+	// no active assignment: the commented one is enabled
+	os.WriteFile(path, []byte("# TOKEN_ADDRESS=0xexample\nLOG_LEVEL=info\n"), 0o600)
+	if err := SetEnv(path, "TOKEN_ADDRESS", "0x03"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if want := "TOKEN_ADDRESS=0x03\nLOG_LEVEL=info\n"; string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+
+	// no temp file is left behind
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("expected only the .env file, got %d entries", len(entries))
+	}
+
+	// other keys with the same prefix are left alone
+	if err := SetEnv(path, "TOKEN", "x"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.HasSuffix(string(data), "LOG_LEVEL=info\nTOKEN=x\n") || !strings.Contains(string(data), "TOKEN_ADDRESS=0x03") {
+		t.Errorf("unexpected file: %q", data)
+	}
+}
@@ -0,0 +1,88 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetEnv sets key=value in a .env file, replacing the active assignment or,
+// when there is none, a commented out one, or appending one. Other lines are
+// kept as they are and the file is created if missing
+// The file is replaced through a rename, so a crash never leaves it truncated
+func SetEnv(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	assignment := key + "=" + value
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+
+	active, commented := -1, -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		isComment := strings.HasPrefix(trimmed, "#")
+		if !strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(trimmed, "#")), key+"=") {
+			continue
+		}
+		switch {
+		case !isComment && active < 0:
+			active = i
+		case !isComment:
+			// a later active assignment would win when loading, comment it out
+			lines[i] = "# " + line
+		case commented < 0:
+			commented = i
+		}
+	}
+	switch {
+	case active >= 0:
+		lines[active] = assignment
+	case commented >= 0:
+		lines[commented] = assignment
+	default:
+		lines = append(lines, assignment)
+	}
+
+	return writeFileAtomic(path, []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it over
+// path, keeping the mode of an existing file
+func writeFileAtomic(path string, data []byte) error {
+	mode := fs.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
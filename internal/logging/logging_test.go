@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "json", "info")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	logger.Info("tx sent", "bot_id", 2, "nonce", 7)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output is not JSON: %v (%s)", err, buf.String())
+	}
+	if record["msg"] != "tx sent" || record["bot_id"] != float64(2) || record["nonce"] != float64(7) {
+		t.Errorf("unexpected record: %v", record)
+	}
+}
+
+func TestNew_LevelFilters(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "text", "warn")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	logger.Info("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("info record should be filtered at warn level, got %q", buf.String())
+	}
+
+	logger.Warn("shown")
+	if buf.Len() == 0 {
+		t.Error("warn record should be written")
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "xml", "info"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if _, err := New(&bytes.Buffer{}, "text", "verbose"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	lvl, err := ParseLevel("DEBUG")
+	if err != nil || lvl != slog.LevelDebug {
+		t.Errorf("expected debug level, got %v (%v)", lvl, err)
+	}
+}
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/nexus-bot-swarm/domain"
+)
+
+// Backend is a go-ethereum contract backend, as used by the on-chain pool
+// adapter (uniswap.Backend)
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// InstrumentedBackend decorates a contract backend with RPC latency metrics
+// Every method hits the network, so every method is timed
+type InstrumentedBackend struct {
+	backend Backend
+	metrics *Metrics
+}
+
+// InstrumentBackend wraps backend so every RPC call is timed
+func InstrumentBackend(backend Backend, m *Metrics) *InstrumentedBackend {
+	return &InstrumentedBackend{backend: backend, metrics: m}
+}
+
+// ChainID implements Backend
+func (b *InstrumentedBackend) ChainID(ctx context.Context) (id *big.Int, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("ChainID", start, err) }()
+	return b.backend.ChainID(ctx)
+}
+
+// CodeAt implements bind.ContractCaller
+func (b *InstrumentedBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("CodeAt", start, err) }()
+	return b.backend.CodeAt(ctx, contract, blockNumber)
+}
+
+// CallContract implements bind.ContractCaller
+func (b *InstrumentedBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (out []byte, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("CallContract", start, err) }()
+	return b.backend.CallContract(ctx, call, blockNumber)
+}
+
+// HeaderByNumber implements bind.ContractTransactor
+func (b *InstrumentedBackend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("HeaderByNumber", start, err) }()
+	return b.backend.HeaderByNumber(ctx, number)
+}
+
+// PendingCodeAt implements bind.ContractTransactor
+func (b *InstrumentedBackend) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("PendingCodeAt", start, err) }()
+	return b.backend.PendingCodeAt(ctx, account)
+}
+
+// PendingNonceAt implements bind.ContractTransactor
+func (b *InstrumentedBackend) PendingNonceAt(ctx context.Context, account common.Address) (n uint64, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("PendingNonceAt", start, err) }()
+	return b.backend.PendingNonceAt(ctx, account)
+}
+
+// SuggestGasPrice implements bind.ContractTransactor
+func (b *InstrumentedBackend) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("SuggestGasPrice", start, err) }()
+	return b.backend.SuggestGasPrice(ctx)
+}
+
+// SuggestGasTipCap implements bind.ContractTransactor
+func (b *InstrumentedBackend) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("SuggestGasTipCap", start, err) }()
+	return b.backend.SuggestGasTipCap(ctx)
+}
+
+// EstimateGas implements bind.ContractTransactor
+func (b *InstrumentedBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("EstimateGas", start, err) }()
+	return b.backend.EstimateGas(ctx, call)
+}
+
+// SendTransaction implements bind.ContractTransactor
+func (b *InstrumentedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) (err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("SendTransaction", start, err) }()
+	return b.backend.SendTransaction(ctx, tx)
+}
+
+// FilterLogs implements bind.ContractFilterer
+func (b *InstrumentedBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("FilterLogs", start, err) }()
+	return b.backend.FilterLogs(ctx, query)
+}
+
+// SubscribeFilterLogs implements bind.ContractFilterer
+// Only the subscription call is timed, not the stream
+func (b *InstrumentedBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (sub ethereum.Subscription, err error) {
+	start := time.Now()
+	defer func() { b.metrics.ObserveRPC("SubscribeFilterLogs", start, err) }()
+	return b.backend.SubscribeFilterLogs(ctx, query, ch)
+}
+
+// TransactionReceipt implements bind.DeployBackend
+// A pending tx (ethereum.NotFound) is not counted as an RPC error
+func (b *InstrumentedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (r *types.Receipt, err error) {
+	start := time.Now()
+	defer func() {
+		if errors.Is(err, ethereum.NotFound) {
+			b.metrics.ObserveRPC("TransactionReceipt", start, nil)
+			return
+		}
+		b.metrics.ObserveRPC("TransactionReceipt", start, err)
+	}()
+	return b.backend.TransactionReceipt(ctx, txHash)
+}
+
+// TokenReader reads token metadata from chain (tokens.Reader)
+type TokenReader interface {
+	TokenInfo(ctx context.Context, tokenAddress string) (domain.Token, error)
+}
+
+type instrumentedTokenReader struct {
+	reader  TokenReader
+	metrics *Metrics
+}
+
+// InstrumentTokenReader wraps reader so every token metadata read is timed
+func InstrumentTokenReader(reader TokenReader, m *Metrics) TokenReader {
+	return &instrumentedTokenReader{reader: reader, metrics: m}
+}
+
+// TokenInfo implements TokenReader
+func (r *instrumentedTokenReader) TokenInfo(ctx context.Context, tokenAddress string) (token domain.Token, err error) {
+	start := time.Now()
+	defer func() { r.metrics.ObserveRPC("TokenInfo", start, err) }()
+	return r.reader.TokenInfo(ctx, tokenAddress)
+}
+
+var _ Backend = (*InstrumentedBackend)(nil)
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/nexus-bot-swarm/ports"
+)
+
+// InstrumentedClient decorates a BlockchainClient with RPC latency metrics
+// Methods that don't hit the network (ChainID, Close) are passed through
+type InstrumentedClient struct {
+	ports.BlockchainClient
+	metrics *Metrics
+}
+
+// InstrumentClient wraps client so every RPC call is timed
+func InstrumentClient(client ports.BlockchainClient, m *Metrics) *InstrumentedClient {
+	return &InstrumentedClient{
+		BlockchainClient: client,
+		metrics:          m,
+	}
+}
+
+// Connect implements ports.BlockchainClient
+func (c *InstrumentedClient) Connect(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveRPC("Connect", start, err) }()
+	return c.BlockchainClient.Connect(ctx)
+}
+
+// BlockNumber implements ports.BlockchainClient
+func (c *InstrumentedClient) BlockNumber(ctx context.Context) (n uint64, err error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveRPC("BlockNumber", start, err) }()
+	return c.BlockchainClient.BlockNumber(ctx)
+}
+
+// Balance implements ports.BlockchainClient
+func (c *InstrumentedClient) Balance(ctx context.Context, address string) (b *big.Int, err error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveRPC("Balance", start, err) }()
+	return c.BlockchainClient.Balance(ctx, address)
+}
+
+// SendETH implements ports.BlockchainClient
+func (c *InstrumentedClient) SendETH(ctx context.Context, privateKey string, to string, amount *big.Int) (hash string, err error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveRPC("SendETH", start, err) }()
+	return c.BlockchainClient.SendETH(ctx, privateKey, to, amount)
+}
+
+// SendETHWithNonce implements ports.BlockchainClient
+func (c *InstrumentedClient) SendETHWithNonce(ctx context.Context, privateKey string, to string, amount *big.Int, nonce uint64) (sent *ports.SentTx, err error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveRPC("SendETHWithNonce", start, err) }()
+	return c.BlockchainClient.SendETHWithNonce(ctx, privateKey, to, amount, nonce)
+}
+
+// GetNonce implements ports.BlockchainClient
+func (c *InstrumentedClient) GetNonce(ctx context.Context, address string) (n uint64, err error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveRPC("GetNonce", start, err) }()
+	return c.BlockchainClient.GetNonce(ctx, address)
+}
+
+// TokenBalance implements ports.BlockchainClient
+func (c *InstrumentedClient) TokenBalance(ctx context.Context, tokenAddress string, walletAddress string) (b *big.Int, err error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveRPC("TokenBalance", start, err) }()
+	return c.BlockchainClient.TokenBalance(ctx, tokenAddress, walletAddress)
+}
+
+// TransferToken implements ports.BlockchainClient
+func (c *InstrumentedClient) TransferToken(ctx context.Context, tokenAddress string, privateKey string, to string, amount *big.Int, nonce uint64) (sent *ports.SentTx, err error) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveRPC("TransferToken", start, err) }()
+	return c.BlockchainClient.TransferToken(ctx, tokenAddress, privateKey, to, amount, nonce)
+}
+
+// TransactionReceipt implements ports.BlockchainClient
+// A pending tx (ErrReceiptNotFound) is not counted as an RPC error
+func (c *InstrumentedClient) TransactionReceipt(ctx context.Context, txHash string) (r *ports.Receipt, err error) {
+	start := time.Now()
+	defer func() {
+		if err == ports.ErrReceiptNotFound {
+			c.metrics.ObserveRPC("TransactionReceipt", start, nil)
+			return
+		}
+		c.metrics.ObserveRPC("TransactionReceipt", start, err)
+	}()
+	return c.BlockchainClient.TransactionReceipt(ctx, txHash)
+}
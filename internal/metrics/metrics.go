@@ -0,0 +1,198 @@
+package metrics
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/ports"
+	"github.com/nexus-bot-swarm/swarm"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "swarm"
+
+// Metrics exposes swarm activity in Prometheus format
+// Implements swarm.Observer, so it can be registered directly on the swarm
+// Series are not labeled by bot: IDs are never reused, so a long run that
+// scales bots up and down would grow the series without bound
+type Metrics struct {
+	registry *prometheus.Registry
+
+	swaps         *prometheus.CounterVec
+	swapsRejected *prometheus.CounterVec
+	txSent        prometheus.Counter
+	txFailed      *prometheus.CounterVec
+	txConfirmed   *prometheus.CounterVec
+	txInclusion   prometheus.Histogram
+	nonceResyncs  prometheus.Counter
+	gasUsed       prometheus.Counter
+	gasSpent      prometheus.Counter
+	rpcLatency    *prometheus.HistogramVec
+	rpcErrors     *prometheus.CounterVec
+}
+
+// New creates the metrics on a dedicated registry (with Go runtime collectors)
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		swaps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "swaps_total",
+			Help:      "Simulated swaps executed on the pool.",
+		}, []string{"direction"}),
+		swapsRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "swaps_rejected_total",
+			Help:      "Simulated swaps rejected (e.g. insufficient balance).",
+		}, []string{"reason"}),
+		txSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tx_sent_total",
+			Help:      "Real transactions accepted by the RPC.",
+		}),
+		txFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tx_failed_total",
+			Help:      "Real transactions that failed to submit or reverted.",
+		}, []string{"reason"}),
+		txConfirmed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tx_confirmed_total",
+			Help:      "Real transactions mined, by receipt status.",
+		}, []string{"status"}),
+		txInclusion: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "tx_inclusion_seconds",
+			Help:      "Time from submission until the receipt was observed.",
+			Buckets:   []float64{1, 2, 4, 6, 10, 15, 20, 30, 60, 120},
+		}),
+		nonceResyncs: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "nonce_resyncs_total",
+			Help:      "Times the shared nonce manager was re-synced from the RPC.",
+		}),
+		gasUsed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "gas_used_total",
+			Help:      "Gas used by mined transactions.",
+		}),
+		gasSpent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "gas_spent_wei_total",
+			Help:      "Fees paid by mined transactions, in wei.",
+		}),
+		rpcLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "RPC call latency by client method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		rpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rpc_errors_total",
+			Help:      "RPC calls that returned an error, by client method.",
+		}, []string{"method"}),
+	}
+
+	m.registry.MustRegister(
+		m.swaps, m.swapsRejected,
+		m.txSent, m.txFailed, m.txConfirmed, m.txInclusion,
+		m.nonceResyncs, m.gasUsed, m.gasSpent,
+		m.rpcLatency, m.rpcErrors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the /metrics endpoint
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterPool exposes reserves and price of a pool as gauges
+// Values are read on scrape, from a pool snapshot
+func (m *Metrics) RegisterPool(pool domain.AMM) {
+	tokenA, tokenB := pool.Tokens()
+	labels := prometheus.Labels{"pair": tokenA + "/" + tokenB}
+
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "pool_reserve_a",
+			Help:        "Pool reserve of TokenA.",
+			ConstLabels: labels,
+		}, func() float64 {
+			return toFloat(pool.Snapshot().ReserveA)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "pool_reserve_b",
+			Help:        "Pool reserve of TokenB.",
+			ConstLabels: labels,
+		}, func() float64 {
+			return toFloat(pool.Snapshot().ReserveB)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "pool_price_a_in_b",
+			Help:        "Spot price of TokenA in TokenB.",
+			ConstLabels: labels,
+		}, pool.PriceAInB),
+	)
+}
+
+// ObserveRPC records the latency and outcome of one RPC call
+func (m *Metrics) ObserveRPC(method string, start time.Time, err error) {
+	m.rpcLatency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.rpcErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// SwapExecuted implements swarm.Observer
+func (m *Metrics) SwapExecuted(_ int, order *swarm.Order, _ *big.Int) {
+	m.swaps.WithLabelValues(order.Direction.String()).Inc()
+}
+
+// SwapRejected implements swarm.Observer
+func (m *Metrics) SwapRejected(_ int, _ *swarm.Order, err error) {
+	m.swapsRejected.WithLabelValues(swarm.ErrorClass(err)).Inc()
+}
+
+// TxSent implements swarm.Observer
+func (m *Metrics) TxSent(swarm.TxInfo) {
+	m.txSent.Inc()
+}
+
+// TxFailed implements swarm.Observer
+func (m *Metrics) TxFailed(_ swarm.TxInfo, err error) {
+	m.txFailed.WithLabelValues(swarm.ErrorClass(err)).Inc()
+}
+
+// TxConfirmed implements swarm.Observer
+func (m *Metrics) TxConfirmed(tx swarm.TxInfo, receipt *ports.Receipt) {
+	status := "success"
+	if receipt.Status == 0 {
+		status = "reverted"
+	}
+	m.txConfirmed.WithLabelValues(status).Inc()
+	m.txInclusion.Observe(time.Since(tx.SentAt).Seconds())
+	m.gasUsed.Add(float64(receipt.GasUsed))
+	m.gasSpent.Add(toFloat(receipt.GasCost()))
+}
+
+// NonceResynced implements swarm.Observer
+func (m *Metrics) NonceResynced(int, uint64, uint64) {
+	m.nonceResyncs.Inc()
+}
+
+func toFloat(x *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(x).Float64()
+	return f
+}
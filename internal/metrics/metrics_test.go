@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/ports"
+	"github.com/nexus-bot-swarm/swarm"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_Observer(t *testing.T) {
+	m := New()
+
+	order := &swarm.Order{Direction: swarm.AToB, AmountIn: big.NewInt(10)}
+	m.SwapExecuted(1, order, big.NewInt(19))
+	m.SwapExecuted(1, order, big.NewInt(19))
+	m.SwapRejected(2, order, swarm.ErrInsufficientBalance)
+
+	tx := swarm.TxInfo{BotID: 1, Nonce: 7, Hash: "0xabc", SentAt: time.Now()}
+	m.TxSent(tx)
+	m.TxFailed(tx, errors.New("nonce too low"))
+	m.TxConfirmed(tx, &ports.Receipt{Status: 1, GasUsed: 21000, EffectiveGasPrice: big.NewInt(2)})
+	m.NonceResynced(1, 5, 9)
+
+	if got := testutil.ToFloat64(m.swaps.WithLabelValues("A->B")); got != 2 {
+		t.Errorf("expected 2 swaps, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.swapsRejected.WithLabelValues("insufficient_balance")); got != 1 {
+		t.Errorf("expected 1 rejected swap, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.txFailed.WithLabelValues("nonce_too_low")); got != 1 {
+		t.Errorf("expected 1 failed tx, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.txConfirmed.WithLabelValues("success")); got != 1 {
+		t.Errorf("expected 1 confirmed tx, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.txSent); got != 1 {
+		t.Errorf("expected 1 sent tx, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.gasSpent); got != 42000 {
+		t.Errorf("expected 42000 wei spent, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.nonceResyncs); got != 1 {
+		t.Errorf("expected 1 nonce resync, got %f", got)
+	}
+}
+
+func TestMetrics_Handler_ExposesPool(t *testing.T) {
+	m := New()
+	m.RegisterPool(domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000)))
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`swarm_pool_reserve_a{pair="ETH/USDC"} 1000`,
+		`swarm_pool_price_a_in_b{pair="ETH/USDC"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in /metrics output", want)
+		}
+	}
+}
+
+// stubClient only implements what the instrumentation test calls
+type stubClient struct {
+	ports.BlockchainClient
+	err error
+}
+
+func (s *stubClient) GetNonce(ctx context.Context, address string) (uint64, error) {
+	return 3, s.err
+}
+
+func (s *stubClient) TransactionReceipt(ctx context.Context, txHash string) (*ports.Receipt, error) {
+	return nil, ports.ErrReceiptNotFound
+}
+
+func TestInstrumentClient(t *testing.T) {
+	m := New()
+	stub := &stubClient{}
+	client := InstrumentClient(stub, m)
+
+	nonce, err := client.GetNonce(context.Background(), "0x0")
+	if err != nil || nonce != 3 {
+		t.Fatalf("expected passthrough result 3, got %d (%v)", nonce, err)
+	}
+
+	stub.err = errors.New("boom")
+	client.GetNonce(context.Background(), "0x0")
+
+	// pending receipts are not RPC errors
+	client.TransactionReceipt(context.Background(), "0xabc")
+
+	if got := testutil.CollectAndCount(m.rpcLatency); got != 2 {
+		t.Errorf("expected latency series for 2 methods, got %d", got)
+	}
+	if got := testutil.ToFloat64(m.rpcErrors.WithLabelValues("GetNonce")); got != 1 {
+		t.Errorf("expected 1 GetNonce error, got %f", got)
+	}
+	if got := testutil.ToFloat64(m.rpcErrors.WithLabelValues("TransactionReceipt")); got != 0 {
+		t.Errorf("expected no TransactionReceipt errors, got %f", got)
+	}
+}
+
+// stubBackend answers contract calls and receipts, other methods panic
+type stubBackend struct {
+	Backend
+	err error
+}
+
+func (b *stubBackend) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	return []byte{1}, b.err
+}
+
+func (b *stubBackend) TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func (b *stubBackend) TokenInfo(context.Context, string) (domain.Token, error) {
+	return domain.Token{Symbol: "KEVZ", Decimals: 18}, b.err
+}
+
+func TestInstrumentBackend(t *testing.T) {
+	m := New()
+	stub := &stubBackend{}
+	backend := InstrumentBackend(stub, m)
+	reader := InstrumentTokenReader(stub, m)
+
+	if out, err := backend.CallContract(context.Background(), ethereum.CallMsg{}, nil); err != nil || len(out) != 1 {
+		t.Fatalf("expected passthrough result, got %v (%v)", out, err)
+	}
+	if token, err := reader.TokenInfo(context.Background(), "0x0"); err != nil || token.Symbol != "KEVZ" {
+		t.Fatalf("expected passthrough token, got %+v (%v)", token, err)
+	}
+
+	stub.err = errors.New("boom")
+	backend.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	reader.TokenInfo(context.Background(), "0x0")
+
+	// pending receipts are not RPC errors
+	backend.TransactionReceipt(context.Background(), common.Hash{})
+
+	if got := testutil.CollectAndCount(m.rpcLatency); got != 3 {
+		t.Errorf("expected latency series for 3 methods, got %d", got)
+	}
+	for method, want := range map[string]float64{"CallContract": 1, "TokenInfo": 1, "TransactionReceipt": 0} {
+		if got := testutil.ToFloat64(m.rpcErrors.WithLabelValues(method)); got != want {
+			t.Errorf("expected %v %s errors, got %f", want, method, got)
+		}
+	}
+}
@@ -37,6 +37,19 @@ func (m *Manager) Reset(nonce uint64) {
 	m.current = nonce
 }
 
+// Release gives back a nonce whose transaction was never broadcast
+// It only succeeds if no nonce was handed out since, otherwise the caller
+// has left a gap and must resync from the chain
+func (m *Manager) Release(nonce uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current != nonce+1 {
+		return false
+	}
+	m.current = nonce
+	return true
+}
+
 // Current returns the current nonce value without incrementing
 func (m *Manager) Current() uint64 {
 	m.mu.Lock()
@@ -76,3 +76,19 @@ func TestManager_Reset(t *testing.T) {
 	}
 }
 
+
+func TestManager_Release(t *testing.T) {
+	manager := NewManager(10)
+
+	n := manager.GetNonce() // 10
+	if !manager.Release(n) || manager.Current() != 10 {
+		t.Errorf("expected nonce 10 released, current %d", manager.Current())
+	}
+
+	// a nonce handed out since: releasing would reuse it
+	n = manager.GetNonce() // 10
+	manager.GetNonce()     // 11
+	if manager.Release(n) || manager.Current() != 12 {
+		t.Errorf("expected release refused, current %d", manager.Current())
+	}
+}
@@ -0,0 +1,49 @@
+package nonce
+
+import (
+	"fmt"
+
+	"github.com/nexus-bot-swarm/ports"
+)
+
+// Recovery is the nonce state rebuilt from the tx store after a restart
+type Recovery struct {
+	// Start is the next nonce to hand out
+	Start uint64
+
+	// InFlight are txs left pending by the previous run that the node already
+	// counted (nonce below its pending nonce): mined or still in the mempool
+	InFlight []ports.StoredTx
+
+	// Dropped are txs left pending that the node never saw or evicted
+	// They are marked dropped in the store and their nonces are reused
+	Dropped []ports.StoredTx
+}
+
+// Recover rebuilds the nonce state of address after a crash or restart
+// rpcNonce is the pending nonce reported by the node, which is authoritative:
+// trusting a higher stored nonce would leave a gap that blocks every later tx
+func Recover(store ports.TxStore, address string, rpcNonce uint64) (*Recovery, error) {
+	pending, err := store.Txs(ports.TxFilter{From: address, Status: ports.TxPending})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending txs: %w", err)
+	}
+
+	rec := &Recovery{Start: rpcNonce}
+	for _, tx := range pending {
+		if tx.Nonce < rpcNonce {
+			rec.InFlight = append(rec.InFlight, tx)
+			continue
+		}
+
+		err := store.UpdateTx(tx.Hash, func(stored *ports.StoredTx) {
+			stored.Status = ports.TxDropped
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark tx %s dropped: %w", tx.Hash, err)
+		}
+		tx.Status = ports.TxDropped
+		rec.Dropped = append(rec.Dropped, tx)
+	}
+	return rec, nil
+}
@@ -0,0 +1,54 @@
+package nonce
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nexus-bot-swarm/internal/adapters/bolt"
+	"github.com/nexus-bot-swarm/ports"
+)
+
+func TestRecover(t *testing.T) {
+	store, err := bolt.Open(filepath.Join(t.TempDir(), "txs.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	// previous run: nonce 5 mined, 6 still in the mempool, 7 and 8 lost in the crash
+	for _, tx := range []ports.StoredTx{
+		{Nonce: 5, Hash: "0x5", From: "0xw", Status: ports.TxConfirmed},
+		{Nonce: 6, Hash: "0x6", From: "0xw", Status: ports.TxPending},
+		{Nonce: 7, Hash: "0x7", From: "0xw", Status: ports.TxPending},
+		{Nonce: 8, Hash: "0x8", From: "0xw", Status: ports.TxPending},
+		{Nonce: 9, Hash: "0x9", From: "0xother", Status: ports.TxPending},
+	} {
+		if err := store.SaveTx(&tx); err != nil {
+			t.Fatalf("failed to save: %v", err)
+		}
+	}
+
+	rec, err := Recover(store, "0xw", 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rec.Start != 7 {
+		t.Errorf("expected start nonce 7, got %d", rec.Start)
+	}
+	if len(rec.InFlight) != 1 || rec.InFlight[0].Nonce != 6 {
+		t.Errorf("expected nonce 6 in flight, got %+v", rec.InFlight)
+	}
+	if len(rec.Dropped) != 2 {
+		t.Errorf("expected 2 dropped txs, got %+v", rec.Dropped)
+	}
+
+	dropped, _ := store.Txs(ports.TxFilter{Status: ports.TxDropped})
+	if len(dropped) != 2 {
+		t.Errorf("dropped txs should be marked in the store, got %d", len(dropped))
+	}
+	other, _ := store.Txs(ports.TxFilter{From: "0xother", Status: ports.TxPending})
+	if len(other) != 1 {
+		t.Error("txs of other wallets must not be touched")
+	}
+}
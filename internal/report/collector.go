@@ -0,0 +1,309 @@
+package report
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/ports"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// DefaultMaxTxRecords is how many of the most recent tx records a report lists
+// The tx counts always cover the whole run
+const DefaultMaxTxRecords = 1000
+
+// Collector accumulates swarm activity for the run report
+// Implements swarm.Observer, so it can be registered directly on the swarm
+type Collector struct {
+	pool  domain.AMM
+	start time.Time
+
+	mu           sync.Mutex
+	bots         map[int]*botStats
+	volume       map[swarm.Direction]*DirectionVolume
+	price        priceTracker
+	maxTxs       int
+	txs          []TxRecord     // most recent records, trimmed to maxTxs in batches
+	txDropped    int            // records trimmed from the front of txs
+	txPending    map[string]int // hash -> record number, until the receipt arrives
+	txCounts     map[ports.TxStatus]int
+	gasUsed      uint64
+	gasSpent     *big.Int
+	nonceResyncs int
+	errors       map[string]int
+}
+
+type botStats struct {
+	swaps    int
+	rejected int
+}
+
+// priceTracker keeps running price statistics without storing every sample
+type priceTracker struct {
+	start, end, min, max, sum float64
+	samples                   int
+}
+
+func (p *priceTracker) add(price float64) {
+	if p.samples == 0 {
+		p.start, p.min, p.max = price, price, price
+	}
+	p.end = price
+	p.min = math.Min(p.min, price)
+	p.max = math.Max(p.max, price)
+	p.sum += price
+	p.samples++
+}
+
+// NewCollector starts collecting; the pool price is sampled after every swap
+func NewCollector(pool domain.AMM) *Collector {
+	c := &Collector{
+		pool:      pool,
+		start:     time.Now(),
+		bots:      make(map[int]*botStats),
+		volume:    make(map[swarm.Direction]*DirectionVolume),
+		maxTxs:    DefaultMaxTxRecords,
+		txPending: make(map[string]int),
+		txCounts:  make(map[ports.TxStatus]int),
+		gasSpent:  new(big.Int),
+		errors:    make(map[string]int),
+	}
+	c.price.add(pool.PriceAInB())
+	return c
+}
+
+// SetMaxTxRecords changes how many tx records the report lists
+// Must be called before the collector is registered on the swarm
+func (c *Collector) SetMaxTxRecords(n int) {
+	c.maxTxs = n
+}
+
+func (c *Collector) SwapExecuted(botID int, order *swarm.Order, amountOut *big.Int) {
+	price := c.pool.PriceAInB()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.bot(botID).swaps++
+	v, ok := c.volume[order.Direction]
+	if !ok {
+		v = &DirectionVolume{Direction: order.Direction.String(), AmountIn: new(big.Int), AmountOut: new(big.Int)}
+		c.volume[order.Direction] = v
+	}
+	v.Swaps++
+	v.AmountIn.Add(v.AmountIn, order.AmountIn)
+	v.AmountOut.Add(v.AmountOut, amountOut)
+	c.price.add(price)
+}
+
+func (c *Collector) SwapRejected(botID int, _ *swarm.Order, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bot(botID).rejected++
+	c.errors[swarm.ErrorClass(err)]++
+}
+
+func (c *Collector) TxSent(tx swarm.TxInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.txPending[tx.Hash] = c.addTx(newTxRecord(tx, ports.TxPending))
+}
+
+func (c *Collector) TxFailed(tx swarm.TxInfo, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[swarm.ErrorClass(err)]++
+
+	// mined but reverted: update the sent record, the receipt follows
+	if n, ok := c.txPending[tx.Hash]; ok && tx.Hash != "" {
+		c.setTxStatus(n, ports.TxPending, ports.TxReverted)
+		if r := c.txRecord(n); r != nil {
+			r.Error = err.Error()
+		}
+		return
+	}
+	record := newTxRecord(tx, ports.TxFailed)
+	record.Error = err.Error()
+	c.addTx(record)
+}
+
+func (c *Collector) TxConfirmed(tx swarm.TxInfo, receipt *ports.Receipt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gasUsed += receipt.GasUsed
+	c.gasSpent.Add(c.gasSpent, receipt.GasCost())
+
+	n, ok := c.txPending[tx.Hash]
+	if !ok {
+		return
+	}
+	delete(c.txPending, tx.Hash)
+	if receipt.Status == 1 {
+		c.setTxStatus(n, ports.TxPending, ports.TxConfirmed)
+	}
+	if r := c.txRecord(n); r != nil {
+		r.Block = receipt.BlockNumber
+		r.GasUsed = receipt.GasUsed
+	}
+}
+
+// addTx counts a record and appends it to the tail, returning its record number
+// Callers must hold c.mu
+func (c *Collector) addTx(record TxRecord) int {
+	c.txCounts[record.Status]++
+	n := c.txDropped + len(c.txs)
+	c.txs = append(c.txs, record)
+
+	// trim in batches so appending stays cheap; Report lists only the last maxTxs
+	if len(c.txs) > 2*c.maxTxs {
+		drop := len(c.txs) - c.maxTxs
+		c.txs = append([]TxRecord(nil), c.txs[drop:]...)
+		c.txDropped += drop
+	}
+	return n
+}
+
+// txRecord returns record number n, or nil once it was trimmed
+// Callers must hold c.mu
+func (c *Collector) txRecord(n int) *TxRecord {
+	i := n - c.txDropped
+	if i < 0 || i >= len(c.txs) {
+		return nil
+	}
+	return &c.txs[i]
+}
+
+// setTxStatus moves record number n from one status to another
+// The counts are updated even if the record itself was trimmed
+// Callers must hold c.mu
+func (c *Collector) setTxStatus(n int, from, to ports.TxStatus) {
+	c.txCounts[from]--
+	c.txCounts[to]++
+	if r := c.txRecord(n); r != nil {
+		r.Status = to
+	}
+}
+
+func (c *Collector) NonceResynced(int, uint64, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nonceResyncs++
+}
+
+// bot returns the stats of a bot, creating them on first use
+// Callers must hold c.mu
+func (c *Collector) bot(id int) *botStats {
+	b, ok := c.bots[id]
+	if !ok {
+		b = &botStats{}
+		c.bots[id] = b
+	}
+	return b
+}
+
+// Report builds the run report
+// bots are the final bot statuses; bots removed during the run are
+// still listed with their swap counts
+func (c *Collector) Report(bots []swarm.BotStatus) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	end := time.Now()
+	r := &Report{
+		Start:           c.start,
+		End:             end,
+		DurationSeconds: end.Sub(c.start).Round(time.Millisecond).Seconds(),
+		BotCount:        len(bots),
+		Price: PriceStats{
+			Start:   c.price.start,
+			End:     c.price.end,
+			Min:     c.price.min,
+			Max:     c.price.max,
+			Mean:    c.price.sum / float64(c.price.samples),
+			Samples: c.price.samples,
+		},
+		NonceResyncs: c.nonceResyncs,
+		Errors:       make(map[string]int, len(c.errors)),
+	}
+	if c.price.start != 0 {
+		r.Price.ChangePercent = (c.price.end - c.price.start) / c.price.start * 100
+	}
+	for class, n := range c.errors {
+		r.Errors[class] = n
+	}
+
+	// per-bot summary: final statuses plus bots that were removed
+	seen := make(map[int]bool, len(bots))
+	for _, status := range bots {
+		seen[status.ID] = true
+		summary := BotSummary{
+			ID:       status.ID,
+			Strategy: status.Strategy,
+			PnL:      status.Portfolio.PnL,
+		}
+		if stats, ok := c.bots[status.ID]; ok {
+			summary.Swaps, summary.Rejected = stats.swaps, stats.rejected
+		}
+		r.Bots = append(r.Bots, summary)
+	}
+	for id, stats := range c.bots {
+		if !seen[id] {
+			r.Bots = append(r.Bots, BotSummary{ID: id, Swaps: stats.swaps, Rejected: stats.rejected, Removed: true})
+		}
+	}
+	sort.Slice(r.Bots, func(i, j int) bool { return r.Bots[i].ID < r.Bots[j].ID })
+
+	for _, d := range []swarm.Direction{swarm.AToB, swarm.BToA} {
+		if v, ok := c.volume[d]; ok {
+			r.Volume = append(r.Volume, DirectionVolume{
+				Direction: v.Direction,
+				Swaps:     v.Swaps,
+				AmountIn:  new(big.Int).Set(v.AmountIn),
+				AmountOut: new(big.Int).Set(v.AmountOut),
+			})
+		}
+	}
+
+	records := c.txs
+	if len(records) > c.maxTxs {
+		records = records[len(records)-c.maxTxs:]
+	}
+	pending, confirmed, reverted := c.txCounts[ports.TxPending], c.txCounts[ports.TxConfirmed], c.txCounts[ports.TxReverted]
+	r.Txs = TxSummary{
+		Sent:           pending + confirmed + reverted,
+		Confirmed:      confirmed,
+		Reverted:       reverted,
+		Pending:        pending,
+		Failed:         c.txCounts[ports.TxFailed],
+		GasUsed:        c.gasUsed,
+		GasSpent:       new(big.Int).Set(c.gasSpent),
+		Records:        append([]TxRecord(nil), records...),
+		RecordsDropped: c.txDropped + len(c.txs) - len(records),
+	}
+	return r
+}
+
+func newTxRecord(tx swarm.TxInfo, status ports.TxStatus) TxRecord {
+	token := tx.Token
+	if token == "" {
+		token = "NEX"
+	}
+	amount := ""
+	if tx.Amount != nil {
+		amount = tx.Amount.String()
+	}
+	return TxRecord{
+		BotID:  tx.BotID,
+		Nonce:  tx.Nonce,
+		Hash:   tx.Hash,
+		Token:  token,
+		Amount: amount,
+		SentAt: tx.SentAt,
+		Status: status,
+	}
+}
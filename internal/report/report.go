@@ -0,0 +1,198 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/nexus-bot-swarm/ports"
+)
+
+// Report summarizes a swarm run
+type Report struct {
+	Start           time.Time         `json:"start"`
+	End             time.Time         `json:"end"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	BotCount        int               `json:"bot_count"`
+	Bots            []BotSummary      `json:"bots"`
+	Volume          []DirectionVolume `json:"volume"`
+	Price           PriceStats        `json:"price"`
+	Txs             TxSummary         `json:"txs"`
+	NonceResyncs    int               `json:"nonce_resyncs"`
+	Errors          map[string]int    `json:"errors_by_class"`
+}
+
+// BotSummary is the activity of one bot, PnL in TokenB at the final price
+type BotSummary struct {
+	ID       int     `json:"id"`
+	Strategy string  `json:"strategy,omitempty"`
+	Swaps    int     `json:"swaps"`
+	Rejected int     `json:"rejected"`
+	PnL      float64 `json:"pnl"`
+	Removed  bool    `json:"removed,omitempty"` // removed before the end of the run
+}
+
+// DirectionVolume is the simulated volume swapped in one direction
+type DirectionVolume struct {
+	Direction string   `json:"direction"`
+	Swaps     int      `json:"swaps"`
+	AmountIn  *big.Int `json:"amount_in"`
+	AmountOut *big.Int `json:"amount_out"`
+}
+
+// PriceStats describes the pool price (A in B) over the run, sampled after every swap
+type PriceStats struct {
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	Min           float64 `json:"min"`
+	Max           float64 `json:"max"`
+	Mean          float64 `json:"mean"`
+	ChangePercent float64 `json:"change_percent"`
+	Samples       int     `json:"samples"`
+}
+
+// TxSummary counts real transactions; Sent includes confirmed, reverted and pending
+// The counts cover the whole run, Records only the most recent txs
+type TxSummary struct {
+	Sent           int        `json:"sent"`
+	Confirmed      int        `json:"confirmed"`
+	Reverted       int        `json:"reverted"`
+	Pending        int        `json:"pending"`
+	Failed         int        `json:"failed"` // rejected by the RPC, never sent
+	GasUsed        uint64     `json:"gas_used"`
+	GasSpent       *big.Int   `json:"gas_spent_wei"`
+	Records        []TxRecord `json:"records"`
+	RecordsDropped int        `json:"records_dropped"` // older records left out of Records
+}
+
+// TxRecord is one real transaction attempt
+type TxRecord struct {
+	BotID   int            `json:"bot_id"`
+	Nonce   uint64         `json:"nonce"`
+	Hash    string         `json:"hash,omitempty"`
+	Token   string         `json:"token"`
+	Amount  string         `json:"amount"`
+	SentAt  time.Time      `json:"sent_at"`
+	Status  ports.TxStatus `json:"status"`
+	Block   uint64         `json:"block,omitempty"`
+	GasUsed uint64         `json:"gas_used,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes the report as a human readable Markdown document
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	fmt.Fprintf(w, "# Swarm run report\n\n")
+	fmt.Fprintf(w, "- Start: %s\n", r.Start.Format(time.RFC3339))
+	fmt.Fprintf(w, "- End: %s\n", r.End.Format(time.RFC3339))
+	fmt.Fprintf(w, "- Duration: %s\n", time.Duration(r.DurationSeconds*float64(time.Second)).Round(time.Second))
+	fmt.Fprintf(w, "- Bots: %d\n\n", r.BotCount)
+
+	fmt.Fprintf(w, "## Price (A in B)\n\n")
+	fmt.Fprintf(w, "| Start | End | Change | Min | Max | Mean |\n|---|---|---|---|---|---|\n")
+	fmt.Fprintf(w, "| %.6f | %.6f | %+.2f%% | %.6f | %.6f | %.6f |\n\n",
+		r.Price.Start, r.Price.End, r.Price.ChangePercent, r.Price.Min, r.Price.Max, r.Price.Mean)
+
+	fmt.Fprintf(w, "## Swaps\n\n")
+	fmt.Fprintf(w, "| Bot | Strategy | Swaps | Rejected | P&L (B) |\n|---|---|---|---|---|\n")
+	for _, b := range r.Bots {
+		strategy := b.Strategy
+		if b.Removed {
+			strategy = "(removed)"
+		}
+		fmt.Fprintf(w, "| %d | %s | %d | %d | %.2f |\n", b.ID, strategy, b.Swaps, b.Rejected, b.PnL)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "| Direction | Swaps | Amount in | Amount out |\n|---|---|---|---|\n")
+	for _, v := range r.Volume {
+		fmt.Fprintf(w, "| %s | %d | %s | %s |\n", v.Direction, v.Swaps, v.AmountIn, v.AmountOut)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "## Transactions\n\n")
+	fmt.Fprintf(w, "- Sent: %d (confirmed %d, reverted %d, pending %d)\n", r.Txs.Sent, r.Txs.Confirmed, r.Txs.Reverted, r.Txs.Pending)
+	fmt.Fprintf(w, "- Failed submissions: %d\n", r.Txs.Failed)
+	fmt.Fprintf(w, "- Gas used: %d\n", r.Txs.GasUsed)
+	fmt.Fprintf(w, "- Gas spent: %s wei\n", r.Txs.GasSpent)
+	fmt.Fprintf(w, "- Nonce resyncs: %d\n\n", r.NonceResyncs)
+
+	if r.Txs.RecordsDropped > 0 {
+		fmt.Fprintf(w, "Only the last %d txs are listed, %d older ones are left out.\n\n", len(r.Txs.Records), r.Txs.RecordsDropped)
+	}
+	if len(r.Txs.Records) > 0 {
+		fmt.Fprintf(w, "| Bot | Nonce | Status | Hash | Block | Error |\n|---|---|---|---|---|---|\n")
+		for _, tx := range r.Txs.Records {
+			block := ""
+			if tx.Block > 0 {
+				block = fmt.Sprint(tx.Block)
+			}
+			fmt.Fprintf(w, "| %d | %d | %s | %s | %s | %s |\n", tx.BotID, tx.Nonce, tx.Status, tx.Hash, block, tx.Error)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "## Errors\n\n")
+	if len(r.Errors) == 0 {
+		_, err := fmt.Fprintf(w, "None\n")
+		return err
+	}
+	classes := make([]string, 0, len(r.Errors))
+	for class := range r.Errors {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	fmt.Fprintf(w, "| Class | Count |\n|---|---|\n")
+	for _, class := range classes {
+		fmt.Fprintf(w, "| %s | %d |\n", class, r.Errors[class])
+	}
+	return nil
+}
+
+// WriteFiles writes run-<start>.json and run-<start>.md into dir
+// Returns the paths of the written files
+func (r *Report) WriteFiles(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create report dir: %w", err)
+	}
+
+	base := filepath.Join(dir, "run-"+r.Start.Format("20060102-150405"))
+	var paths []string
+	for _, f := range []struct {
+		ext   string
+		write func(io.Writer) error
+	}{
+		{".json", r.WriteJSON},
+		{".md", r.WriteMarkdown},
+	} {
+		path := base + f.ext
+		if err := writeFile(path, f.write); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,142 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/ports"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// newTestReport feeds a collector with a small run: 2 bots, one of them removed
+func newTestReport(t *testing.T) *Report {
+	t.Helper()
+
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	c := NewCollector(pool)
+
+	sell := &swarm.Order{Direction: swarm.AToB, AmountIn: big.NewInt(100)}
+	out, _ := swarm.Execute(pool, sell)
+	c.SwapExecuted(1, sell, out)
+	buy := &swarm.Order{Direction: swarm.BToA, AmountIn: big.NewInt(50)}
+	out, _ = swarm.Execute(pool, buy)
+	c.SwapExecuted(2, buy, out)
+	c.SwapRejected(1, sell, swarm.ErrInsufficientBalance)
+
+	sent := swarm.TxInfo{BotID: 1, Nonce: 3, Hash: "0xaaa", Amount: big.NewInt(1), SentAt: time.Now()}
+	c.TxSent(sent)
+	c.TxConfirmed(sent, &ports.Receipt{Status: 1, BlockNumber: 42, GasUsed: 21000, EffectiveGasPrice: big.NewInt(2)})
+
+	reverted := swarm.TxInfo{BotID: 1, Nonce: 4, Hash: "0xbbb", Amount: big.NewInt(1)}
+	c.TxSent(reverted)
+	c.TxFailed(reverted, swarm.ErrTxReverted)
+	c.TxConfirmed(reverted, &ports.Receipt{Status: 0, BlockNumber: 43, GasUsed: 30000, EffectiveGasPrice: big.NewInt(2)})
+
+	c.TxSent(swarm.TxInfo{BotID: 1, Nonce: 5, Hash: "0xccc", Amount: big.NewInt(1)})
+	c.TxFailed(swarm.TxInfo{BotID: 1, Nonce: 6, Amount: big.NewInt(1)}, errors.New("nonce too low"))
+	c.NonceResynced(1, 6, 7)
+
+	return c.Report([]swarm.BotStatus{{ID: 1, Strategy: "random"}})
+}
+
+func TestCollector_Report(t *testing.T) {
+	r := newTestReport(t)
+
+	if r.BotCount != 1 || len(r.Bots) != 2 {
+		t.Fatalf("expected 1 live bot and 2 summaries, got %d and %+v", r.BotCount, r.Bots)
+	}
+	if r.Bots[0].Swaps != 1 || r.Bots[0].Rejected != 1 || r.Bots[0].Strategy != "random" {
+		t.Errorf("unexpected bot 1 summary: %+v", r.Bots[0])
+	}
+	if !r.Bots[1].Removed || r.Bots[1].Swaps != 1 {
+		t.Errorf("bot 2 should be listed as removed: %+v", r.Bots[1])
+	}
+
+	if len(r.Volume) != 2 || r.Volume[0].Direction != "A->B" || r.Volume[0].AmountIn.Int64() != 100 {
+		t.Errorf("unexpected volume: %+v", r.Volume)
+	}
+	if r.Price.Samples != 3 || r.Price.Min > r.Price.End || r.Price.Start != 2 {
+		t.Errorf("unexpected price stats: %+v", r.Price)
+	}
+
+	txs := r.Txs
+	if txs.Sent != 3 || txs.Confirmed != 1 || txs.Reverted != 1 || txs.Pending != 1 || txs.Failed != 1 {
+		t.Errorf("unexpected tx counts: %+v", txs)
+	}
+	if txs.GasUsed != 51000 || txs.GasSpent.Int64() != 102000 {
+		t.Errorf("unexpected gas: used %d, spent %s", txs.GasUsed, txs.GasSpent)
+	}
+	if txs.Records[0].Block != 42 || txs.Records[1].Status != ports.TxReverted {
+		t.Errorf("unexpected records: %+v", txs.Records)
+	}
+
+	if r.NonceResyncs != 1 {
+		t.Errorf("expected 1 nonce resync, got %d", r.NonceResyncs)
+	}
+	if r.Errors["reverted"] != 1 || r.Errors["nonce_too_low"] != 1 || r.Errors["insufficient_balance"] != 1 {
+		t.Errorf("unexpected errors: %v", r.Errors)
+	}
+}
+
+func TestCollector_MaxTxRecords(t *testing.T) {
+	c := NewCollector(domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000)))
+	c.SetMaxTxRecords(2)
+
+	first := swarm.TxInfo{BotID: 1, Nonce: 0, Hash: "0x0", Amount: big.NewInt(1)}
+	c.TxSent(first)
+	for nonce := uint64(1); nonce < 10; nonce++ {
+		c.TxSent(swarm.TxInfo{BotID: 1, Nonce: nonce, Hash: fmt.Sprintf("0x%d", nonce), Amount: big.NewInt(1)})
+	}
+	// the receipt of a trimmed record still moves it out of pending
+	c.TxConfirmed(first, &ports.Receipt{Status: 1, BlockNumber: 1, GasUsed: 21000, EffectiveGasPrice: big.NewInt(1)})
+
+	txs := c.Report(nil).Txs
+	if txs.Sent != 10 || txs.Confirmed != 1 || txs.Pending != 9 {
+		t.Errorf("counts should cover every tx: %+v", txs)
+	}
+	if len(txs.Records) != 2 || txs.Records[0].Nonce != 8 || txs.Records[1].Nonce != 9 || txs.RecordsDropped != 8 {
+		t.Errorf("expected the last 2 records and 8 dropped, got %+v and %d", txs.Records, txs.RecordsDropped)
+	}
+}
+
+func TestReport_WriteFiles(t *testing.T) {
+	r := newTestReport(t)
+
+	paths, err := r.WriteFiles(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 2 || !strings.HasSuffix(paths[0], ".json") || !strings.HasSuffix(paths[1], ".md") {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("failed to read json: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if decoded.Txs.Records[0].Hash != "0xaaa" || decoded.Txs.GasSpent.Int64() != 102000 {
+		t.Errorf("json round trip lost data: %+v", decoded.Txs)
+	}
+
+	var md bytes.Buffer
+	if err := r.WriteMarkdown(&md); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"# Swarm run report", "| 1 | 3 | confirmed | 0xaaa | 42 |", "| nonce_too_low | 1 |", "(removed)"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q", want)
+		}
+	}
+}
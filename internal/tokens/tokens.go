@@ -0,0 +1,126 @@
+// Package tokens builds the token registry from a tokens file and the
+// metadata of the token contracts
+package tokens
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/nexus-bot-swarm/domain"
+)
+
+// Reader reads the metadata of a deployed ERC20 token
+// Implemented by nexus.Client
+type Reader interface {
+	TokenInfo(ctx context.Context, address string) (domain.Token, error)
+}
+
+// Entry is a token listed in a tokens file
+// An entry with an address is checked against the contract, which fills in
+// the fields left out: a symbol or name that differs from the contract's
+// renames the token locally, different decimals are an error. An entry
+// without an address (or read offline) must give the symbol and decimals
+type Entry struct {
+	Symbol   string `json:"symbol,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Decimals *uint8 `json:"decimals,omitempty"`
+}
+
+// LoadFile reads a JSON array of entries, e.g.
+//
+//	[{"address": "0x5FbDB2315678afecb367f032d93F642f64180aa3"},
+//	 {"symbol": "USDC", "decimals": 6, "address": "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512"}]
+func LoadFile(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokens file: %w", err)
+	}
+	var entries []Entry
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("invalid tokens file %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Resolve builds a registry from entries, then adds the tokens at addresses
+// that no entry lists (TOKEN_ADDRESS, pool tokens). Missing metadata is read
+// through reader, which may be nil when every entry is complete
+func Resolve(ctx context.Context, reader Reader, entries []Entry, addresses ...string) (*domain.TokenRegistry, error) {
+	registry, err := domain.NewTokenRegistry()
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		token, err := resolveEntry(ctx, reader, entry)
+		if err != nil {
+			return nil, fmt.Errorf("token %d (%s): %w", i+1, entry.label(), err)
+		}
+		if err := registry.Register(token); err != nil {
+			return nil, fmt.Errorf("token %d (%s): %w", i+1, entry.label(), err)
+		}
+	}
+
+	for _, address := range addresses {
+		if address == "" {
+			continue
+		}
+		if _, err := registry.ByAddress(address); err == nil {
+			continue
+		}
+		if reader == nil {
+			return nil, fmt.Errorf("token %s: not in the tokens file and no chain to read it from", address)
+		}
+		token, err := reader.TokenInfo(ctx, address)
+		if err != nil {
+			return nil, fmt.Errorf("token %s: %w", address, err)
+		}
+		if err := registry.Register(token); err != nil {
+			return nil, fmt.Errorf("token %s: %w", address, err)
+		}
+	}
+	return registry, nil
+}
+
+// resolveEntry completes and checks an entry with the contract metadata
+func resolveEntry(ctx context.Context, reader Reader, entry Entry) (domain.Token, error) {
+	if entry.Address == "" || reader == nil {
+		if entry.Symbol == "" || entry.Decimals == nil {
+			if entry.Address == "" {
+				return domain.Token{}, fmt.Errorf("needs an address, or both symbol and decimals")
+			}
+			return domain.Token{}, fmt.Errorf("metadata missing and no chain to read it from")
+		}
+		return domain.Token{Symbol: entry.Symbol, Decimals: *entry.Decimals, Name: entry.Name, Address: entry.Address}, nil
+	}
+
+	token, err := reader.TokenInfo(ctx, entry.Address)
+	if err != nil {
+		return domain.Token{}, err
+	}
+	if entry.Decimals != nil && *entry.Decimals != token.Decimals {
+		return domain.Token{}, fmt.Errorf("tokens file says %d decimals, the contract %d", *entry.Decimals, token.Decimals)
+	}
+	if entry.Symbol != "" {
+		token.Symbol = entry.Symbol
+	}
+	if entry.Name != "" {
+		token.Name = entry.Name
+	}
+	return token, nil
+}
+
+func (e Entry) label() string {
+	if e.Symbol != "" {
+		return e.Symbol
+	}
+	if e.Address != "" {
+		return e.Address
+	}
+	return "empty"
+}
@@ -0,0 +1,133 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nexus-bot-swarm/domain"
+)
+
+const (
+	kevzAddress = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	usdcAddress = "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512"
+)
+
+// fakeReader serves token metadata from a map and counts the calls
+type fakeReader struct {
+	tokens map[string]domain.Token
+	calls  int
+}
+
+func newFakeReader() *fakeReader {
+	return &fakeReader{tokens: map[string]domain.Token{
+		strings.ToLower(kevzAddress): {Symbol: "KEVZ", Decimals: 18, Name: "Kevz Token", Address: kevzAddress},
+		strings.ToLower(usdcAddress): {Symbol: "USDC", Decimals: 6, Name: "USD Coin", Address: usdcAddress},
+	}}
+}
+
+func (f *fakeReader) TokenInfo(_ context.Context, address string) (domain.Token, error) {
+	f.calls++
+	token, ok := f.tokens[strings.ToLower(address)]
+	if !ok {
+		return domain.Token{}, errors.New("execution reverted")
+	}
+	return token, nil
+}
+
+func decimals(d uint8) *uint8 {
+	return &d
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	entries, err := LoadFile(writeFile(t, `[
+		{"address": "`+kevzAddress+`"},
+		{"symbol": "ETH", "name": "Ether", "decimals": 18}
+	]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Address != kevzAddress || entries[0].Decimals != nil ||
+		entries[1].Symbol != "ETH" || entries[1].Decimals == nil || *entries[1].Decimals != 18 {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	for _, content := range []string{`{"symbol": "ETH"}`, `[{"symbol": "ETH", "decimal": 18}]`, `[{"decimals": 300}]`} {
+		if _, err := LoadFile(writeFile(t, content)); err == nil {
+			t.Errorf("%s: expected error", content)
+		}
+	}
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected ErrNotExist, got %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	reader := newFakeReader()
+	registry, err := Resolve(context.Background(), reader, []Entry{
+		{Address: kevzAddress},
+		{Symbol: "USDC.e", Decimals: decimals(6), Address: strings.ToLower(usdcAddress)},
+		{Symbol: "ETH", Decimals: decimals(18)},
+	}, kevzAddress, "", usdcAddress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kevz, err := registry.Lookup("KEVZ")
+	if err != nil || kevz.Name != "Kevz Token" || kevz.Decimals != 18 {
+		t.Errorf("expected KEVZ read from chain, got %+v (%v)", kevz, err)
+	}
+	usdc, err := registry.Lookup("USDC.e")
+	if err != nil || usdc.Name != "USD Coin" || usdc.Address != usdcAddress {
+		t.Errorf("expected the renamed USDC with the contract name, got %+v (%v)", usdc, err)
+	}
+	if eth, err := registry.Lookup("ETH"); err != nil || eth.Address != "" {
+		t.Errorf("expected ETH from the file only, got %+v (%v)", eth, err)
+	}
+	// the extra addresses were already listed
+	if reader.calls != 2 {
+		t.Errorf("expected 2 chain reads, got %d", reader.calls)
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	ctx := context.Background()
+	for name, tt := range map[string]struct {
+		reader    Reader
+		entries   []Entry
+		addresses []string
+	}{
+		"decimals differ":     {newFakeReader(), []Entry{{Address: usdcAddress, Decimals: decimals(18)}}, nil},
+		"no address":          {newFakeReader(), []Entry{{Symbol: "ETH"}}, nil},
+		"offline incomplete":  {nil, []Entry{{Address: kevzAddress, Symbol: "KEVZ"}}, nil},
+		"offline address":     {nil, nil, []string{kevzAddress}},
+		"not a token":         {newFakeReader(), []Entry{{Address: "0x0000000000000000000000000000000000000001"}}, nil},
+		"duplicate symbol":    {newFakeReader(), []Entry{{Address: kevzAddress}, {Symbol: "KEVZ", Decimals: decimals(6)}}, nil},
+		"symbol of the chain": {newFakeReader(), []Entry{{Symbol: "USDC", Decimals: decimals(6)}}, []string{usdcAddress}},
+	} {
+		if _, err := Resolve(ctx, tt.reader, tt.entries, tt.addresses...); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// offline, complete entries are enough
+	registry, err := Resolve(ctx, nil, []Entry{{Symbol: "KEVZ", Decimals: decimals(18), Address: kevzAddress}}, kevzAddress)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := registry.ByAddress(kevzAddress); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
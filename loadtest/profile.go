@@ -0,0 +1,212 @@
+package loadtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profile describes the target transactions per second over time
+type Profile interface {
+	// Name identifies the profile in reports
+	Name() string
+
+	// Duration is the total length of the run
+	Duration() time.Duration
+
+	// TargetTPS returns the desired tx rate at the given offset from the start
+	TargetTPS(elapsed time.Duration) float64
+}
+
+// Ramp increases (or decreases) the rate linearly from From to To
+type Ramp struct {
+	From, To float64
+	Length   time.Duration
+}
+
+func (r Ramp) Name() string            { return "ramp" }
+func (r Ramp) Duration() time.Duration { return r.Length }
+
+func (r Ramp) TargetTPS(elapsed time.Duration) float64 {
+	if r.Length <= 0 || elapsed >= r.Length {
+		return r.To
+	}
+	progress := float64(elapsed) / float64(r.Length)
+	return r.From + (r.To-r.From)*progress
+}
+
+// Step starts at Start and adds Increment every Every, Steps times
+type Step struct {
+	Start     float64
+	Increment float64
+	Every     time.Duration
+	Steps     int
+}
+
+func (s Step) Name() string            { return "step" }
+func (s Step) Duration() time.Duration { return time.Duration(s.Steps) * s.Every }
+
+func (s Step) TargetTPS(elapsed time.Duration) float64 {
+	if s.Every <= 0 || s.Steps <= 0 {
+		return s.Start
+	}
+	step := min(int(elapsed/s.Every), s.Steps-1)
+	return s.Start + float64(step)*s.Increment
+}
+
+// Spike holds Base, jumping to Peak between At and At+For
+type Spike struct {
+	Base, Peak float64
+	At, For    time.Duration
+	Length     time.Duration
+}
+
+func (s Spike) Name() string            { return "spike" }
+func (s Spike) Duration() time.Duration { return s.Length }
+
+func (s Spike) TargetTPS(elapsed time.Duration) float64 {
+	if elapsed >= s.At && elapsed < s.At+s.For {
+		return s.Peak
+	}
+	return s.Base
+}
+
+// Soak holds a constant rate for a long time
+type Soak struct {
+	TPS    float64
+	Length time.Duration
+}
+
+func (s Soak) Name() string                    { return "soak" }
+func (s Soak) Duration() time.Duration         { return s.Length }
+func (s Soak) TargetTPS(time.Duration) float64 { return s.TPS }
+
+// ParseProfile builds a profile from a "kind:key=value,..." spec
+//
+//	ramp:from=0.1,to=2,duration=10m
+//	step:start=0.5,increment=0.5,every=2m,steps=5
+//	spike:base=0.5,peak=5,at=2m,for=30s,duration=5m
+//	soak:tps=1,duration=1h
+func ParseProfile(spec string) (Profile, error) {
+	kind, rest, _ := strings.Cut(spec, ":")
+	params, err := parseParams(rest)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", spec, err)
+	}
+
+	var p Profile
+	switch kind {
+	case "ramp":
+		p = Ramp{
+			From:   params.float("from"),
+			To:     params.float("to"),
+			Length: params.duration("duration"),
+		}
+	case "step":
+		p = Step{
+			Start:     params.float("start"),
+			Increment: params.float("increment"),
+			Every:     params.duration("every"),
+			Steps:     params.int("steps"),
+		}
+	case "spike":
+		p = Spike{
+			Base:   params.float("base"),
+			Peak:   params.float("peak"),
+			At:     params.duration("at"),
+			For:    params.duration("for"),
+			Length: params.duration("duration"),
+		}
+	case "soak":
+		p = Soak{
+			TPS:    params.float("tps"),
+			Length: params.duration("duration"),
+		}
+	default:
+		return nil, fmt.Errorf("unknown profile kind: %q (use ramp, step, spike or soak)", kind)
+	}
+
+	if err := params.err(); err != nil {
+		return nil, fmt.Errorf("profile %q: %w", spec, err)
+	}
+	if p.Duration() <= 0 {
+		return nil, fmt.Errorf("profile %q: duration must be positive", spec)
+	}
+	return p, nil
+}
+
+// params holds key=value pairs, recording the first missing or invalid key
+type params struct {
+	values map[string]string
+	first  error
+}
+
+func parseParams(s string) (*params, error) {
+	p := &params{values: make(map[string]string)}
+	if s == "" {
+		return p, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		p.values[key] = value
+	}
+	return p, nil
+}
+
+func (p *params) get(key string) (string, bool) {
+	v, ok := p.values[key]
+	if !ok && p.first == nil {
+		p.first = fmt.Errorf("missing %s", key)
+	}
+	return v, ok
+}
+
+func (p *params) fail(key string, err error) {
+	if p.first == nil {
+		p.first = fmt.Errorf("invalid %s: %w", key, err)
+	}
+}
+
+func (p *params) float(key string) float64 {
+	v, ok := p.get(key)
+	if !ok {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		p.fail(key, fmt.Errorf("%q is not a non-negative number", v))
+	}
+	return f
+}
+
+func (p *params) int(key string) int {
+	v, ok := p.get(key)
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		p.fail(key, fmt.Errorf("%q is not a positive integer", v))
+	}
+	return n
+}
+
+func (p *params) duration(key string) time.Duration {
+	v, ok := p.get(key)
+	if !ok {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return d
+}
+
+func (p *params) err() error {
+	return p.first
+}
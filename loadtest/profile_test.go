@@ -0,0 +1,76 @@
+package loadtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProfiles_TargetTPS(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		elapsed time.Duration
+		want    float64
+	}{
+		{"ramp start", Ramp{From: 1, To: 11, Length: 10 * time.Minute}, 0, 1},
+		{"ramp middle", Ramp{From: 1, To: 11, Length: 10 * time.Minute}, 5 * time.Minute, 6},
+		{"ramp after end", Ramp{From: 1, To: 11, Length: 10 * time.Minute}, time.Hour, 11},
+		{"step first", Step{Start: 1, Increment: 2, Every: time.Minute, Steps: 3}, 30 * time.Second, 1},
+		{"step second", Step{Start: 1, Increment: 2, Every: time.Minute, Steps: 3}, 90 * time.Second, 3},
+		{"step capped", Step{Start: 1, Increment: 2, Every: time.Minute, Steps: 3}, time.Hour, 5},
+		{"spike before", Spike{Base: 1, Peak: 10, At: time.Minute, For: 30 * time.Second, Length: 5 * time.Minute}, 59 * time.Second, 1},
+		{"spike during", Spike{Base: 1, Peak: 10, At: time.Minute, For: 30 * time.Second, Length: 5 * time.Minute}, 70 * time.Second, 10},
+		{"spike after", Spike{Base: 1, Peak: 10, At: time.Minute, For: 30 * time.Second, Length: 5 * time.Minute}, 90 * time.Second, 1},
+		{"soak", Soak{TPS: 2, Length: time.Hour}, 30 * time.Minute, 2},
+	}
+
+	for _, tt := range tests {
+		if got := tt.profile.TargetTPS(tt.elapsed); got != tt.want {
+			t.Errorf("%s: expected %v tps, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestParseProfile(t *testing.T) {
+	p, err := ParseProfile("step:start=0.5,increment=0.5,every=2m,steps=5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	step, ok := p.(Step)
+	if !ok {
+		t.Fatalf("expected Step, got %T", p)
+	}
+	if step.Start != 0.5 || step.Every != 2*time.Minute || step.Steps != 5 {
+		t.Errorf("unexpected step profile: %+v", step)
+	}
+	if p.Duration() != 10*time.Minute {
+		t.Errorf("expected 10m duration, got %s", p.Duration())
+	}
+
+	for _, spec := range []string{
+		"ramp:from=1,to=2,duration=10m",
+		"spike:base=0.5,peak=5,at=2m,for=30s,duration=5m",
+		"soak:tps=1,duration=1h",
+	} {
+		if _, err := ParseProfile(spec); err != nil {
+			t.Errorf("%s: unexpected error: %v", spec, err)
+		}
+	}
+}
+
+func TestParseProfile_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"burst:tps=1",
+		"soak:tps=1",
+		"soak:tps=-1,duration=1m",
+		"soak:tps=abc,duration=1m",
+		"soak:tps=1,duration=0s",
+		"ramp:from=1;to=2",
+		"step:start=1,increment=1,every=1m,steps=0",
+	} {
+		if _, err := ParseProfile(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
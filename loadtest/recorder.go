@@ -0,0 +1,117 @@
+package loadtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nexus-bot-swarm/ports"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// Recorder collects transaction outcomes during a load test
+// Register it on the swarm (it implements swarm.Observer)
+type Recorder struct {
+	swarm.NopObserver
+
+	mu           sync.Mutex
+	sent         int
+	submitFailed int
+	reverted     int
+	confirmed    int
+	latencies    []time.Duration
+	errors       map[string]int
+
+	// counters since the last window() call
+	windowSent   int
+	windowFailed int
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{errors: make(map[string]int)}
+}
+
+func (r *Recorder) TxSent(swarm.TxInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent++
+	r.windowSent++
+}
+
+func (r *Recorder) TxFailed(tx swarm.TxInfo, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// no hash: rejected by the RPC, otherwise mined and reverted
+	if tx.Hash == "" {
+		r.submitFailed++
+		r.windowFailed++
+	} else {
+		r.reverted++
+	}
+	r.errors[swarm.ErrorClass(err)]++
+}
+
+func (r *Recorder) TxConfirmed(tx swarm.TxInfo, _ *ports.Receipt) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.confirmed++
+	r.latencies = append(r.latencies, time.Since(tx.SentAt))
+}
+
+// Pending returns the number of sent txs without a receipt yet
+func (r *Recorder) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sent - r.confirmed
+}
+
+// window returns the sent and failed submissions since the previous call
+func (r *Recorder) window() (sent, failed int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sent, failed = r.windowSent, r.windowFailed
+	r.windowSent, r.windowFailed = 0, 0
+	return sent, failed
+}
+
+// fill copies the totals into the report
+func (r *Recorder) fill(report *Report) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report.Sent = r.sent
+	report.SubmitFailed = r.submitFailed
+	report.Reverted = r.reverted
+	report.Confirmed = r.confirmed
+	report.Pending = r.sent - r.confirmed
+	if attempts := r.sent + r.submitFailed; attempts > 0 {
+		report.ErrorRate = float64(r.submitFailed+r.reverted) / float64(attempts)
+	}
+
+	report.Errors = make(map[string]int, len(r.errors))
+	for class, n := range r.errors {
+		report.Errors[class] = n
+	}
+
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	report.Latency = LatencyStats{
+		P50: percentile(sorted, 50).Seconds(),
+		P90: percentile(sorted, 90).Seconds(),
+		P99: percentile(sorted, 99).Seconds(),
+	}
+	if len(sorted) > 0 {
+		report.Latency.Max = sorted[len(sorted)-1].Seconds()
+	}
+}
+
+// percentile uses the nearest-rank method on sorted values
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
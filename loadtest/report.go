@@ -0,0 +1,87 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Report is the outcome of a load test run
+type Report struct {
+	Profile      string         `json:"profile"`
+	Duration     float64        `json:"duration_seconds"`
+	MaxBots      int            `json:"max_bots"`
+	TargetTPS    float64        `json:"target_tps"`   // average over the samples
+	AchievedTPS  float64        `json:"achieved_tps"` // txs accepted by the RPC per second
+	Sent         int            `json:"sent"`
+	SubmitFailed int            `json:"submit_failed"`
+	Reverted     int            `json:"reverted"`
+	Confirmed    int            `json:"confirmed"`
+	Pending      int            `json:"pending"` // sent without a receipt when the run ended
+	ErrorRate    float64        `json:"error_rate"`
+	Latency      LatencyStats   `json:"inclusion_latency_seconds"`
+	Errors       map[string]int `json:"errors_by_class"`
+	Samples      []Sample       `json:"samples"`
+}
+
+// LatencyStats are inclusion latency percentiles in seconds
+// Measured from submission until the receipt was observed, so they include
+// the receipt polling interval
+type LatencyStats struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// Sample is the achieved rate over one runner tick
+type Sample struct {
+	Elapsed     float64 `json:"elapsed_seconds"`
+	TargetTPS   float64 `json:"target_tps"`
+	AchievedTPS float64 `json:"achieved_tps"`
+	Sent        int     `json:"sent"`
+	Failed      int     `json:"failed"`
+	Bots        int     `json:"bots"`
+	TxInterval  string  `json:"tx_interval"`
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report as a human readable summary and sample table
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Load test: %s profile, %.0fs, up to %d bots\n", r.Profile, r.Duration, r.MaxBots)
+	fmt.Fprintf(w, "TPS: target %.3f, achieved %.3f\n", r.TargetTPS, r.AchievedTPS)
+	fmt.Fprintf(w, "Txs: %d sent, %d confirmed, %d pending, %d submit failed, %d reverted (error rate %.2f%%)\n",
+		r.Sent, r.Confirmed, r.Pending, r.SubmitFailed, r.Reverted, r.ErrorRate*100)
+	fmt.Fprintf(w, "Inclusion latency: p50 %.1fs, p90 %.1fs, p99 %.1fs, max %.1fs\n",
+		r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.Max)
+
+	if len(r.Errors) > 0 {
+		classes := make([]string, 0, len(r.Errors))
+		for class := range r.Errors {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		fmt.Fprint(w, "Errors:")
+		for _, class := range classes {
+			fmt.Fprintf(w, " %s=%d", class, r.Errors[class])
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ELAPSED\tTARGET TPS\tACHIEVED TPS\tSENT\tFAILED\tBOTS\tTX INTERVAL")
+	for _, s := range r.Samples {
+		fmt.Fprintf(tw, "%.0fs\t%.3f\t%.3f\t%d\t%d\t%d\t%s\n",
+			s.Elapsed, s.TargetTPS, s.AchievedTPS, s.Sent, s.Failed, s.Bots, s.TxInterval)
+	}
+	return tw.Flush()
+}
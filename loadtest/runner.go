@@ -0,0 +1,214 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+// Runner cadence and limits
+const (
+	// DefaultTick is how often the target rate is re-evaluated and sampled
+	DefaultTick = 5 * time.Second
+
+	// DefaultDrain is how long to wait for pending receipts after the profile ends
+	DefaultDrain = 30 * time.Second
+
+	// MinTxInterval is the fastest per-bot cadence the runner will set
+	MinTxInterval = 100 * time.Millisecond
+
+	// MaxTxInterval is the slowest per-bot cadence, used when the target is ~0 TPS
+	MaxTxInterval = time.Hour
+)
+
+// Target is the swarm being driven (implemented by *swarm.Swarm)
+type Target interface {
+	Scale(n int) (int, error)
+	SetTxInterval(d time.Duration) error
+}
+
+// Runner drives a swarm through a load profile by adjusting bot count and cadence
+// Bots are added when the rate rises but never removed during a run, so the
+// receipts of in-flight txs keep being tracked: lower rates slow bots down instead
+type Runner struct {
+	target   Target
+	profile  Profile
+	recorder *Recorder
+	maxBots  int
+	tick     time.Duration
+	drain    time.Duration
+	logger   *slog.Logger
+
+	bots     int
+	interval time.Duration
+}
+
+// NewRunner creates a runner; recorder must be registered as the swarm observer
+// maxBots caps the swarm size, above it bots send faster than swarm.DefaultTxInterval
+func NewRunner(target Target, profile Profile, recorder *Recorder, maxBots int) *Runner {
+	return &Runner{
+		target:   target,
+		profile:  profile,
+		recorder: recorder,
+		maxBots:  max(maxBots, 1),
+		tick:     DefaultTick,
+		drain:    DefaultDrain,
+		logger:   slog.Default(),
+	}
+}
+
+// SetTiming changes the sampling tick and the receipt drain timeout
+func (r *Runner) SetTiming(tick, drain time.Duration) {
+	r.tick = tick
+	r.drain = drain
+}
+
+// SetLogger sets the logger for progress records
+func (r *Runner) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
+
+// Plan returns the bot count and per-bot cadence to reach tps
+// Bots are sized for swarm.DefaultTxInterval, never fewer than current
+func Plan(tps float64, current, maxBots int) (int, time.Duration) {
+	needed := int(math.Ceil(tps * swarm.DefaultTxInterval.Seconds()))
+	bots := min(max(current, needed, 1), maxBots)
+
+	if tps <= 0 {
+		return bots, MaxTxInterval
+	}
+	interval := time.Duration(float64(bots) / tps * float64(time.Second))
+	return bots, min(max(interval, MinTxInterval), MaxTxInterval)
+}
+
+// Run executes the profile, then waits for pending receipts and returns the report
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	report := &Report{
+		Profile: r.profile.Name(),
+		MaxBots: r.maxBots,
+	}
+
+	start := time.Now()
+	r.recorder.window() // discard anything recorded before the run
+
+	ticker := time.NewTicker(r.tick)
+	defer ticker.Stop()
+
+	var last time.Time
+	var lastTarget float64
+	for {
+		now := time.Now()
+		elapsed := now.Sub(start)
+
+		if !last.IsZero() {
+			report.Samples = append(report.Samples, r.sample(elapsed, now.Sub(last), lastTarget))
+		}
+		if elapsed >= r.profile.Duration() {
+			break
+		}
+
+		lastTarget = r.profile.TargetTPS(elapsed)
+		last = now
+		if err := r.apply(lastTarget); err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	duration := time.Since(start)
+	report.Duration = duration.Round(time.Millisecond).Seconds()
+
+	// stop sending and give in-flight txs a chance to be mined
+	if err := r.target.SetTxInterval(MaxTxInterval); err != nil {
+		return nil, fmt.Errorf("failed to idle bots: %w", err)
+	}
+	r.waitPending(ctx)
+
+	r.recorder.fill(report)
+	report.AchievedTPS = float64(report.Sent) / duration.Seconds()
+	report.TargetTPS = averageTarget(report.Samples)
+	return report, nil
+}
+
+// apply scales the swarm and sets its cadence for the target rate
+func (r *Runner) apply(tps float64) error {
+	bots, interval := Plan(tps, r.bots, r.maxBots)
+
+	if bots != r.bots {
+		n, err := r.target.Scale(bots)
+		if err != nil {
+			return fmt.Errorf("failed to scale to %d bots: %w", bots, err)
+		}
+		r.bots = n
+	}
+
+	// small changes are skipped: every change reschedules each bot
+	if r.interval == 0 || math.Abs(float64(interval-r.interval)) > 0.05*float64(r.interval) {
+		if err := r.target.SetTxInterval(interval); err != nil {
+			return fmt.Errorf("failed to set tx interval %s: %w", interval, err)
+		}
+		r.interval = interval
+	}
+	return nil
+}
+
+// sample records the window that just ended
+func (r *Runner) sample(elapsed, window time.Duration, target float64) Sample {
+	sent, failed := r.recorder.window()
+	s := Sample{
+		Elapsed:     elapsed.Round(time.Millisecond).Seconds(),
+		TargetTPS:   target,
+		AchievedTPS: float64(sent) / window.Seconds(),
+		Sent:        sent,
+		Failed:      failed,
+		Bots:        r.bots,
+		TxInterval:  r.interval.String(),
+	}
+	r.logger.Info("load test sample",
+		"elapsed", elapsed.Round(time.Second),
+		"target_tps", s.TargetTPS,
+		"achieved_tps", s.AchievedTPS,
+		"failed", failed,
+		"bots", s.Bots,
+		"tx_interval", r.interval)
+	return s
+}
+
+// waitPending polls the recorder until every sent tx has a receipt or drain expires
+func (r *Runner) waitPending(ctx context.Context) {
+	if r.drain <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.drain)
+	defer cancel()
+
+	ticker := time.NewTicker(min(r.tick, time.Second))
+	defer ticker.Stop()
+
+	for r.recorder.Pending() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func averageTarget(samples []Sample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, s := range samples {
+		sum += s.TargetTPS
+	}
+	return sum / float64(len(samples))
+}
@@ -0,0 +1,129 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nexus-bot-swarm/ports"
+	"github.com/nexus-bot-swarm/swarm"
+)
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		tps          float64
+		current, max int
+		wantBots     int
+		wantInterval time.Duration
+	}{
+		// 1 tps with 10s bots: 10 bots at 10s
+		{1, 0, 50, 10, 10 * time.Second},
+		// capped bots send faster
+		{10, 0, 50, 50, 5 * time.Second},
+		// lower rate keeps the bots and slows them down
+		{0.5, 10, 50, 10, 20 * time.Second},
+		// idle
+		{0, 3, 50, 3, MaxTxInterval},
+		// never below the fastest cadence
+		{1000, 0, 2, 2, MinTxInterval},
+	}
+
+	for _, tt := range tests {
+		bots, interval := Plan(tt.tps, tt.current, tt.max)
+		if bots != tt.wantBots || interval != tt.wantInterval {
+			t.Errorf("Plan(%v, %d, %d) = %d, %s; want %d, %s",
+				tt.tps, tt.current, tt.max, bots, interval, tt.wantBots, tt.wantInterval)
+		}
+	}
+}
+
+// fakeTarget records the scaling calls of the runner
+type fakeTarget struct {
+	mu        sync.Mutex
+	bots      []int
+	intervals []time.Duration
+}
+
+func (f *fakeTarget) Scale(n int) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bots = append(f.bots, n)
+	return n, nil
+}
+
+func (f *fakeTarget) SetTxInterval(d time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.intervals = append(f.intervals, d)
+	return nil
+}
+
+func TestRunner_Run(t *testing.T) {
+	target := &fakeTarget{}
+	recorder := NewRecorder()
+	profile := Step{Start: 1, Increment: 1, Every: 100 * time.Millisecond, Steps: 2}
+
+	runner := NewRunner(target, profile, recorder, 100)
+	runner.SetTiming(50*time.Millisecond, time.Second)
+	runner.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// simulate bot activity while the profile runs
+	go func() {
+		for i := 0; i < 4; i++ {
+			tx := swarm.TxInfo{Hash: "0x1", SentAt: time.Now().Add(-2 * time.Second)}
+			recorder.TxSent(tx)
+			recorder.TxConfirmed(tx, &ports.Receipt{Status: 1})
+			time.Sleep(20 * time.Millisecond)
+		}
+		recorder.TxFailed(swarm.TxInfo{}, errors.New("429 Too Many Requests"))
+	}()
+
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(target.bots) != 2 || target.bots[0] != 10 || target.bots[1] != 20 {
+		t.Errorf("expected scaling to 10 then 20 bots, got %v", target.bots)
+	}
+	if last := target.intervals[len(target.intervals)-1]; last != MaxTxInterval {
+		t.Errorf("bots should be idled after the run, got interval %s", last)
+	}
+
+	if report.Sent != 4 || report.Confirmed != 4 || report.SubmitFailed != 1 || report.Pending != 0 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	if report.ErrorRate != 0.2 {
+		t.Errorf("expected error rate 0.2, got %v", report.ErrorRate)
+	}
+	if report.Errors["rate_limited"] != 1 {
+		t.Errorf("expected 1 rate_limited error, got %v", report.Errors)
+	}
+	if report.Latency.P50 < 2 {
+		t.Errorf("expected p50 latency >= 2s, got %v", report.Latency.P50)
+	}
+	if len(report.Samples) < 3 {
+		t.Errorf("expected a sample per tick, got %d", len(report.Samples))
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Second)
+	}
+
+	if got := percentile(sorted, 50); got != 50*time.Second {
+		t.Errorf("p50: expected 50s, got %s", got)
+	}
+	if got := percentile(sorted, 99); got != 99*time.Second {
+		t.Errorf("p99: expected 99s, got %s", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("empty: expected 0, got %s", got)
+	}
+}
@@ -2,9 +2,36 @@ package ports
 
 import (
 	"context"
+	"errors"
 	"math/big"
 )
 
+// ErrReceiptNotFound is returned by TransactionReceipt while a tx is still pending
+var ErrReceiptNotFound = errors.New("receipt not found")
+
+// Receipt is the outcome of a mined transaction
+type Receipt struct {
+	TxHash            string
+	Status            uint64 // 1 = success, 0 = reverted
+	BlockNumber       uint64
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
+}
+
+// GasCost returns the fee paid for the transaction in wei
+func (r *Receipt) GasCost() *big.Int {
+	if r.EffectiveGasPrice == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(r.GasUsed), r.EffectiveGasPrice)
+}
+
+// SentTx is a transaction accepted by the RPC node
+type SentTx struct {
+	Hash     string
+	GasPrice *big.Int // gas price the tx was signed with
+}
+
 // BlockchainClient defines the interface for interacting with any EVM blockchain
 // This is the PORT in hexagonal architecture - implementations are adapters
 type BlockchainClient interface {
@@ -24,7 +51,7 @@ type BlockchainClient interface {
 	SendETH(ctx context.Context, privateKey string, to string, amount *big.Int) (string, error)
 
 	// SendETHWithNonce sends native currency with a specific nonce (for concurrent use)
-	SendETHWithNonce(ctx context.Context, privateKey string, to string, amount *big.Int, nonce uint64) (string, error)
+	SendETHWithNonce(ctx context.Context, privateKey string, to string, amount *big.Int, nonce uint64) (*SentTx, error)
 
 	// GetNonce returns the current pending nonce for an address
 	GetNonce(ctx context.Context, address string) (uint64, error)
@@ -33,7 +60,11 @@ type BlockchainClient interface {
 	TokenBalance(ctx context.Context, tokenAddress string, walletAddress string) (*big.Int, error)
 
 	// TransferToken sends ERC20 tokens to an address
-	TransferToken(ctx context.Context, tokenAddress string, privateKey string, to string, amount *big.Int, nonce uint64) (string, error)
+	TransferToken(ctx context.Context, tokenAddress string, privateKey string, to string, amount *big.Int, nonce uint64) (*SentTx, error)
+
+	// TransactionReceipt returns the receipt of a mined transaction
+	// Returns ErrReceiptNotFound if the tx is not mined yet
+	TransactionReceipt(ctx context.Context, txHash string) (*Receipt, error)
 
 	// Close gracefully closes the connection
 	Close()
@@ -0,0 +1,67 @@
+package ports
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+// ErrTxNotFound is returned when a stored transaction does not exist
+var ErrTxNotFound = errors.New("transaction not found")
+
+// TxStatus is the lifecycle state of a stored transaction
+type TxStatus string
+
+const (
+	TxPending   TxStatus = "pending"   // accepted by the RPC, no receipt yet
+	TxConfirmed TxStatus = "confirmed" // mined with status 1
+	TxReverted  TxStatus = "reverted"  // mined with status 0
+	TxFailed    TxStatus = "failed"    // rejected by the RPC, never sent
+	TxDropped   TxStatus = "dropped"   // pending before a restart, no longer known to the node
+)
+
+// StoredTx is a transaction submission persisted by a TxStore
+type StoredTx struct {
+	ID          uint64    `json:"id"` // assigned by the store, increasing
+	BotID       int       `json:"bot_id"`
+	Nonce       uint64    `json:"nonce"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Token       string    `json:"token,omitempty"` // empty for native NEX
+	Amount      *big.Int  `json:"amount"`
+	Hash        string    `json:"hash,omitempty"`
+	Status      TxStatus  `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	SentAt      time.Time `json:"sent_at"`
+	MinedAt     time.Time `json:"mined_at,omitempty"` // when the receipt was observed
+	BlockNumber uint64    `json:"block,omitempty"`
+	GasUsed     uint64    `json:"gas_used,omitempty"`
+	GasPrice    *big.Int  `json:"gas_price,omitempty"` // gas price the tx was signed with
+	// EffectiveGasPrice is the price paid, from the receipt
+	EffectiveGasPrice *big.Int `json:"effective_gas_price,omitempty"`
+}
+
+// TxFilter selects stored transactions, zero values match everything
+type TxFilter struct {
+	BotID  int
+	From   string // case-insensitive
+	Status TxStatus
+	Limit  int // most recent N
+}
+
+// TxStore persists transaction history across runs
+// This is a PORT in hexagonal architecture - implementations are adapters
+type TxStore interface {
+	// SaveTx stores a new submission and sets its ID
+	SaveTx(tx *StoredTx) error
+
+	// UpdateTx applies fn to the stored tx with the given hash
+	// Returns ErrTxNotFound if no tx has that hash
+	UpdateTx(hash string, fn func(tx *StoredTx)) error
+
+	// Txs returns matching transactions ordered by ID
+	Txs(filter TxFilter) ([]StoredTx, error)
+
+	// Close releases the underlying storage
+	Close() error
+}
@@ -2,10 +2,12 @@ package swarm
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"math/big"
-	"math/rand"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nexus-bot-swarm/domain"
@@ -16,35 +18,199 @@ import (
 // Bot represents an individual trading bot in the swarm
 type Bot struct {
 	ID            int
-	pool          *domain.Pool
+	pool          domain.AMM
 	client        ports.BlockchainClient
 	privateKey    string
 	walletAddress string
 	nonceManager  *nonce.Manager
-	tokenAddress  string // ERC20 token contract address
+	tokenAddress  string             // ERC20 token contract address
+	swapInterval  time.Duration      // delay between simulated swaps
+	transfer      domain.TokenAmount // amount of each ERC20 transfer
+	portfolio     *Portfolio
+	observer      Observer
+	logger        *slog.Logger
+	swapLogEvery  int // log every Nth simulated swap (0 = never)
+	swapCount     int
+	receipts      *receiptTracker // shared with the swarm
+
+	// runtime controls, changed by the swarm while the bot runs
+	mu         sync.Mutex
+	strategy   Strategy
+	txInterval time.Duration
+	intervalCh chan struct{} // signals Run to reset its tx ticker
+	paused     atomic.Bool
 }
 
+// DefaultSwapLogEvery is the default swap log sampling: 1 record every 10 swaps
+const DefaultSwapLogEvery = 10
+
+// Bot cadence defaults
+const (
+	// simulated swap every 500ms
+	DefaultSwapInterval = 500 * time.Millisecond
+	// real TX every 10 seconds to avoid rate limiting
+	DefaultTxInterval = 10 * time.Second
+)
+
+// receipt polling for sent transactions
+const (
+	receiptPollInterval = 2 * time.Second
+	receiptTimeout      = 2 * time.Minute
+)
+
+// sendTimeout bounds a submission, which is not aborted by a shutdown:
+// cancelling mid-send would leave us unsure whether the tx was broadcast
+const sendTimeout = 30 * time.Second
+
+// Default starting balances for a bot portfolio (overridable with Swarm.FundBots)
+var (
+	DefaultBalanceA = big.NewInt(1000000)
+	DefaultBalanceB = big.NewInt(2000000)
+)
+
+// DefaultTransferAmount is what ERC20 transfers send unless the swarm sets
+// another amount: 1 token of 18 decimals (1 KEVZ)
+var DefaultTransferAmount = domain.Token{Symbol: "KEVZ", Decimals: 18}.Amount(big.NewInt(1000000000000000000))
+
 // NewBot creates a new bot with the given ID and pool reference
-func NewBot(id int, pool *domain.Pool) *Bot {
+func NewBot(id int, pool domain.AMM) *Bot {
 	return &Bot{
-		ID:   id,
-		pool: pool,
+		ID:           id,
+		pool:         pool,
+		strategy:     NewRandomStrategy(100, nil),
+		portfolio:    NewPortfolio(DefaultBalanceA, DefaultBalanceB, pool.PriceAInB()),
+		observer:     NopObserver{},
+		logger:       slog.Default().With("bot_id", id),
+		swapLogEvery: DefaultSwapLogEvery,
+		txInterval:   DefaultTxInterval,
+		intervalCh:   make(chan struct{}, 1),
+		receipts:     newReceiptTracker(),
+		transfer:     DefaultTransferAmount,
+		swapInterval: DefaultSwapInterval,
 	}
 }
 
 // NewBotWithClient creates a bot that can send real transactions
-func NewBotWithClient(id int, pool *domain.Pool, client ports.BlockchainClient, privateKey, walletAddress, tokenAddress string, nonceManager *nonce.Manager) *Bot {
-	return &Bot{
-		ID:            id,
-		pool:          pool,
-		client:        client,
-		privateKey:    privateKey,
-		walletAddress: walletAddress,
-		nonceManager:  nonceManager,
-		tokenAddress:  tokenAddress,
+func NewBotWithClient(id int, pool domain.AMM, client ports.BlockchainClient, privateKey, walletAddress, tokenAddress string, nonceManager *nonce.Manager) *Bot {
+	b := NewBot(id, pool)
+	b.client = client
+	b.privateKey = privateKey
+	b.walletAddress = walletAddress
+	b.nonceManager = nonceManager
+	b.tokenAddress = tokenAddress
+	return b
+}
+
+// Strategy returns the bot's current strategy
+func (b *Bot) Strategy() Strategy {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.strategy
+}
+
+// SetStrategy replaces the strategy, safe to call while the bot runs
+func (b *Bot) SetStrategy(s Strategy) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.strategy = s
+}
+
+// TxInterval returns the delay between real transactions
+func (b *Bot) TxInterval() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.txInterval
+}
+
+// SetTxInterval changes the delay between real transactions
+// Safe to call while the bot runs: the next tx is rescheduled one new
+// interval after the previous one (immediately if that is already past)
+func (b *Bot) SetTxInterval(d time.Duration) {
+	b.mu.Lock()
+	b.txInterval = d
+	b.mu.Unlock()
+
+	// non-blocking: one pending signal is enough
+	select {
+	case b.intervalCh <- struct{}{}:
+	default:
 	}
 }
 
+// Pause stops the bot from swapping and sending txs until Resume
+func (b *Bot) Pause() {
+	b.paused.Store(true)
+}
+
+// Resume lets a paused bot trade again
+func (b *Bot) Resume() {
+	b.paused.Store(false)
+}
+
+// Paused reports whether the bot is paused
+func (b *Bot) Paused() bool {
+	return b.paused.Load()
+}
+
+// SetLogger sets the structured logger (bot_id is added to every record)
+// swapLogEvery samples simulated swap records deterministically: 1 every N swaps
+// Must be called before Run
+func (b *Bot) SetLogger(logger *slog.Logger, swapLogEvery int) {
+	b.logger = logger.With("bot_id", b.ID)
+	b.swapLogEvery = swapLogEvery
+}
+
+// SetSwapInterval sets the delay between simulated swaps
+// Must be called before Run
+func (b *Bot) SetSwapInterval(d time.Duration) {
+	b.swapInterval = d
+}
+
+// SetWallet makes the bot send its real transactions from another wallet
+// nonceManager must be shared by every sender of that wallet
+// Must be called before Run
+func (b *Bot) SetWallet(privateKey, walletAddress string, nonceManager *nonce.Manager) {
+	b.privateKey = privateKey
+	b.walletAddress = walletAddress
+	b.nonceManager = nonceManager
+}
+
+// WalletAddress returns the address the bot sends real transactions from
+func (b *Bot) WalletAddress() string {
+	return b.walletAddress
+}
+
+// SetTransferAmount sets the amount sent by each ERC20 transfer
+// A token with an address also becomes the token transferred, otherwise the
+// bot keeps the token it was created with
+// Must be called before Run
+func (b *Bot) SetTransferAmount(amount domain.TokenAmount) {
+	b.transfer = amount
+	if amount.Token.Address != "" {
+		b.tokenAddress = amount.Token.Address
+	}
+}
+
+// SetObserver registers the observer notified of swaps and transactions
+// Must be called before Run
+func (b *Bot) SetObserver(o Observer) {
+	if o == nil {
+		o = NopObserver{}
+	}
+	b.observer = o
+}
+
+// Portfolio returns the bot's token holdings and P&L tracker
+func (b *Bot) Portfolio() *Portfolio {
+	return b.portfolio
+}
+
+// Fund replaces the bot's portfolio with fresh balances at the current pool price
+// Call before Run - the portfolio is not swapped while the bot trades
+func (b *Bot) Fund(balanceA, balanceB *big.Int) {
+	b.portfolio = NewPortfolio(balanceA, balanceB, b.pool.PriceAInB())
+}
+
 // CanSendRealTX returns true if bot is configured for real transactions
 func (b *Bot) CanSendRealTX() bool {
 	return b.client != nil && b.privateKey != "" && b.walletAddress != "" && b.nonceManager != nil
@@ -62,94 +228,140 @@ func (b *Bot) Run(ctx context.Context, errCh chan<- error) {
 	defer close(errCh)
 
 	// simulated swap ticker (fast)
-	swapTicker := time.NewTicker(500 * time.Millisecond)
+	swapTicker := time.NewTicker(b.swapInterval)
 	defer swapTicker.Stop()
 
-	// real TX ticker (slow, every 10 seconds to avoid rate limiting)
-	var realTxTicker *time.Ticker
+	// real TX timer (slow, to avoid rate limiting)
+	// a timer instead of a ticker so cadence changes keep the time already waited
+	var realTxTimer *time.Timer
+	var lastTx time.Time
 	if b.CanSendRealTX() {
-		realTxTicker = time.NewTicker(10 * time.Second)
-		defer realTxTicker.Stop()
-		log.Printf("[Bot %d] Started (real TX enabled with nonce manager)", b.ID)
+		realTxTimer = time.NewTimer(b.TxInterval())
+		defer realTxTimer.Stop()
+		lastTx = time.Now()
+		b.logger.Info("bot started", "mode", "real_tx", "strategy", b.Strategy().Name(), "tx_interval", b.TxInterval())
 	} else {
-		log.Printf("[Bot %d] Started (simulation only)", b.ID)
+		b.logger.Info("bot started", "mode", "simulation", "strategy", b.Strategy().Name())
 	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("[Bot %d] Shutting down", b.ID)
+			b.logger.Info("bot stopping")
 			errCh <- ctx.Err()
 			return
 
+		case <-b.intervalCh:
+			if realTxTimer != nil {
+				// next tx is due one new interval after the previous one
+				if !realTxTimer.Stop() {
+					select {
+					case <-realTxTimer.C:
+					default:
+					}
+				}
+				realTxTimer.Reset(max(b.TxInterval()-time.Since(lastTx), 0))
+			}
+
 		case <-swapTicker.C:
-			b.performSwap()
+			if !b.Paused() {
+				b.performSwap(ctx)
+			}
 
 		case <-func() <-chan time.Time {
-			if realTxTicker != nil {
-				return realTxTicker.C
+			if realTxTimer != nil {
+				return realTxTimer.C
 			}
 			return nil
 		}():
-			b.performRealTX(ctx)
+			lastTx = time.Now()
+			realTxTimer.Reset(b.TxInterval())
+			if !b.Paused() {
+				b.performRealTX(ctx)
+			}
 		}
 	}
 }
 
-// performSwap executes a random swap on the simulated pool
-func (b *Bot) performSwap() {
-	amount := big.NewInt(int64(rand.Intn(100) + 1))
+// performSwap asks the strategy for an order and executes it on the pool
+// ctx cancels a swap waiting on an on-chain pool when the bot stops
+func (b *Bot) performSwap(ctx context.Context) {
+	order := b.Strategy().Next(b.pool)
+	if order == nil {
+		return
+	}
+	order.BotID = b.ID
 
-	if rand.Intn(2) == 0 {
-		out, err := b.pool.SwapAForB(amount)
-		if err != nil {
-			return
-		}
-		// less verbose logging
-		if rand.Intn(10) == 0 {
-			log.Printf("[Bot %d] Simulated: %s A -> %s B", b.ID, amount.String(), out.String())
-		}
-	} else {
-		out, err := b.pool.SwapBForA(amount)
-		if err != nil {
-			return
-		}
-		if rand.Intn(10) == 0 {
-			log.Printf("[Bot %d] Simulated: %s B -> %s A", b.ID, amount.String(), out.String())
-		}
+	// debits/credits the bot's balances, rejects unaffordable orders
+	out, err := b.portfolio.SwapContext(ctx, b.pool, order)
+	if cancelled(ctx, err) {
+		b.logger.Debug("swap abandoned on stop", "direction", order.Direction.String(), "error", err)
+		return
+	}
+	if err != nil {
+		b.observer.SwapRejected(b.ID, order, err)
+		b.logger.Debug("swap rejected",
+			"direction", order.Direction.String(),
+			"amount_in", order.AmountIn.String(),
+			"error", err,
+			"error_class", ErrorClass(err))
+		return
+	}
+	b.observer.SwapExecuted(b.ID, order, out)
+
+	// deterministic sampling: 1 record every swapLogEvery swaps
+	b.swapCount++
+	if b.swapLogEvery > 0 && b.swapCount%b.swapLogEvery == 0 {
+		b.logger.Info("simulated swap",
+			"direction", order.Direction.String(),
+			"amount_in", order.AmountIn.String(),
+			"amount_out", out.String(),
+			"swaps", b.swapCount)
 	}
 }
 
 // performRealTX sends a real transaction on the blockchain
+// The send outlives ctx (bounded by sendTimeout), so a stop waits for it
 func (b *Bot) performRealTX(ctx context.Context) {
 	if !b.CanSendRealTX() {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+	defer cancel()
+
 	// get nonce from manager (atomic, no collisions)
 	txNonce := b.nonceManager.GetNonce()
 
-	var txHash string
-	var err error
+	tx := TxInfo{
+		BotID: b.ID,
+		Nonce: txNonce,
+		From:  b.walletAddress,
+		To:    b.walletAddress,
+	}
 
+	var (
+		sent *ports.SentTx
+		err  error
+	)
 	if b.CanTransferTokens() {
-		// Transfer ERC20 tokens (1 token = 1e18 wei of token)
-		amount := big.NewInt(1000000000000000000) // 1 KEVZ token
-
-		log.Printf("[Bot %d] 🪙 Transferring 1 KEVZ (nonce %d)...", b.ID, txNonce)
+		// Transfer ERC20 tokens, 1 KEVZ unless configured otherwise
+		tx.Token = b.tokenAddress
+		tx.Amount = b.transfer.Raw()
 
-		txHash, err = b.client.TransferToken(ctx, b.tokenAddress, b.privateKey, b.walletAddress, amount, txNonce)
+		tx.SentAt = time.Now()
+		sent, err = b.client.TransferToken(ctx, b.tokenAddress, b.privateKey, b.walletAddress, tx.Amount, txNonce)
 	} else {
 		// Fallback: send 1 wei NEX to self
-		amount := big.NewInt(1)
+		tx.Amount = big.NewInt(1)
 
-		log.Printf("[Bot %d] 📤 Sending 1 wei NEX (nonce %d)...", b.ID, txNonce)
-
-		txHash, err = b.client.SendETHWithNonce(ctx, b.privateKey, b.walletAddress, amount, txNonce)
+		tx.SentAt = time.Now()
+		sent, err = b.client.SendETHWithNonce(ctx, b.privateKey, b.walletAddress, tx.Amount, txNonce)
 	}
 
 	if err != nil {
-		log.Printf("[Bot %d] ❌ TX failed (nonce %d): %v", b.ID, txNonce, err)
+		b.logger.Warn("tx failed", txAttrs(tx, "error", err, "error_class", ErrorClass(err))...)
+		b.observer.TxFailed(tx, err)
 
 		// if nonce too low, sync with RPC
 		if isNonceTooLowError(err) {
@@ -157,8 +369,69 @@ func (b *Bot) performRealTX(ctx context.Context) {
 		}
 		return
 	}
+	tx.Hash, tx.GasPrice = sent.Hash, sent.GasPrice
+
+	b.logger.Info("tx sent", txAttrs(tx)...)
+	b.observer.TxSent(tx)
+
+	b.receipts.track(func(ctx context.Context) {
+		waitReceipt(ctx, b.client, b.observer, b.logger, tx)
+	})
+}
+
+// waitReceipt polls the RPC until the tx is mined, then reports the outcome
+// Gives up silently on timeout or shutdown - the tx may still be mined later
+func waitReceipt(ctx context.Context, client ports.BlockchainClient, observer Observer, logger *slog.Logger, tx TxInfo) {
+	ctx, cancel := context.WithTimeout(ctx, receiptTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash)
+		if errors.Is(err, ports.ErrReceiptNotFound) {
+			continue
+		}
+		if err != nil {
+			// transient RPC error, keep polling until timeout
+			continue
+		}
+
+		if receipt.Status == 0 {
+			logger.Warn("tx reverted", txAttrs(tx, "block", receipt.BlockNumber, "error_class", ErrorClass(ErrTxReverted))...)
+			observer.TxFailed(tx, ErrTxReverted)
+		} else {
+			logger.Info("tx confirmed", txAttrs(tx, "block", receipt.BlockNumber, "gas_used", receipt.GasUsed)...)
+		}
+		observer.TxConfirmed(tx, receipt)
+		return
+	}
+}
+
+// txAttrs returns the structured log fields of a transaction plus extra pairs
+func txAttrs(tx TxInfo, extra ...any) []any {
+	token := tx.Token
+	if token == "" {
+		token = "NEX"
+	}
+	attrs := []any{"nonce", tx.Nonce, "token", token, "amount", tx.Amount.String()}
+	if tx.Hash != "" {
+		attrs = append(attrs, "tx_hash", tx.Hash)
+	}
+	return append(attrs, extra...)
+}
 
-	log.Printf("[Bot %d] ✅ TX sent (nonce %d): %s", b.ID, txNonce, txHash)
+// cancelled reports whether err comes from ctx being done, e.g. a swap
+// abandoned when the swarm stops
+func cancelled(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err())
 }
 
 // isNonceTooLowError checks if the error is a nonce too low error
@@ -174,13 +447,14 @@ func isNonceTooLowError(err error) bool {
 func (b *Bot) syncNonce(ctx context.Context) {
 	newNonce, err := b.client.GetNonce(ctx, b.walletAddress)
 	if err != nil {
-		log.Printf("[Bot %d] ⚠️ Failed to sync nonce: %v", b.ID, err)
+		b.logger.Warn("nonce sync failed", "error", err, "error_class", ErrorClass(err))
 		return
 	}
 
 	currentNonce := b.nonceManager.Current()
 	if newNonce > currentNonce {
 		b.nonceManager.Reset(newNonce)
-		log.Printf("[Bot %d] 🔄 Nonce synced: %d → %d", b.ID, currentNonce, newNonce)
+		b.observer.NonceResynced(b.ID, currentNonce, newNonce)
+		b.logger.Info("nonce synced", "nonce_from", currentNonce, "nonce_to", newNonce)
 	}
 }
@@ -1,16 +1,48 @@
 package swarm
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"log/slog"
 	"math/big"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/internal/nonce"
+	"github.com/nexus-bot-swarm/ports"
 )
 
+// fakeClient accepts every native and token transfer and mines it immediately
+// Methods not needed by the bot loop panic through the nil embedded interface
+type fakeClient struct {
+	ports.BlockchainClient
+	sent        atomic.Int64
+	tokenAmount atomic.Pointer[big.Int] // last token transfer
+	token       atomic.Value            // address of the last token transfer
+}
+
+func (c *fakeClient) SendETHWithNonce(_ context.Context, _, _ string, _ *big.Int, n uint64) (*ports.SentTx, error) {
+	c.sent.Add(1)
+	return &ports.SentTx{Hash: fmt.Sprintf("0x%064x", n), GasPrice: big.NewInt(1)}, nil
+}
+
+func (c *fakeClient) TransferToken(_ context.Context, token, _, _ string, amount *big.Int, n uint64) (*ports.SentTx, error) {
+	c.sent.Add(1)
+	c.tokenAmount.Store(amount)
+	c.token.Store(token)
+	return &ports.SentTx{Hash: fmt.Sprintf("0x%064x", n), GasPrice: big.NewInt(1)}, nil
+}
+
+func (c *fakeClient) TransactionReceipt(_ context.Context, hash string) (*ports.Receipt, error) {
+	return &ports.Receipt{TxHash: hash, Status: 1, BlockNumber: 1, GasUsed: 21000}, nil
+}
+
 func TestNewBot(t *testing.T) {
-	pool := domain.NewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
 	bot := NewBot(1, pool)
 
 	if bot.ID != 1 {
@@ -22,7 +54,7 @@ func TestNewBot(t *testing.T) {
 }
 
 func TestBot_Run_Shutdown(t *testing.T) {
-	pool := domain.NewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
 	bot := NewBot(1, pool)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -48,7 +80,7 @@ func TestBot_Run_Shutdown(t *testing.T) {
 }
 
 func TestBot_Run_PerformsSwaps(t *testing.T) {
-	pool := domain.NewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
 	bot := NewBot(1, pool)
 
 	initialReserveA := new(big.Int).Set(pool.ReserveA)
@@ -70,3 +102,145 @@ func TestBot_Run_PerformsSwaps(t *testing.T) {
 	}
 }
 
+func TestBot_SwapLogSampling(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	bot := NewBot(7, pool)
+
+	var buf bytes.Buffer
+	bot.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)), 5)
+
+	for i := 0; i < 20; i++ {
+		bot.performSwap(context.Background())
+	}
+
+	// 20 swaps sampled 1 every 5 -> exactly 4 records
+	records := strings.Count(buf.String(), `"msg":"simulated swap"`)
+	if records != 4 {
+		t.Errorf("expected 4 sampled swap records, got %d", records)
+	}
+	if !strings.Contains(buf.String(), `"bot_id":7`) {
+		t.Error("expected bot_id field in swap records")
+	}
+}
+
+func TestBot_Pause(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	bot := NewBot(1, pool)
+	bot.Pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go bot.Run(ctx, errCh)
+
+	// longer than one swap tick
+	time.Sleep(700 * time.Millisecond)
+	cancel()
+	<-errCh
+
+	if trades := bot.Portfolio().Snapshot(pool.PriceAInB()).Trades; trades != 0 {
+		t.Errorf("paused bot should not trade, got %d trades", trades)
+	}
+}
+
+func TestBot_SetTxInterval_Reschedules(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	client := &fakeClient{}
+	bot := NewBotWithClient(1, pool, client, "key", "0xwallet", "", nonce.NewManager(0))
+	bot.SetTxInterval(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go bot.Run(ctx, errCh)
+
+	// the hour-long wait must be cut short by the new cadence
+	time.Sleep(50 * time.Millisecond)
+	bot.SetTxInterval(100 * time.Millisecond)
+	time.Sleep(450 * time.Millisecond)
+	cancel()
+	<-errCh
+
+	if sent := client.sent.Load(); sent < 2 {
+		t.Errorf("expected at least 2 txs after the cadence change, got %d", sent)
+	}
+}
+
+func TestSwarm_SetTransferAmount(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	client := &fakeClient{}
+	s := NewSwarmWithClient(1, pool, client, "key", "0xwallet", "0x00000000000000000000000000000000000000b0", 0)
+
+	usdc := domain.Token{Symbol: "USDC", Decimals: 6}
+	if err := s.SetTransferAmount(usdc.Amount(big.NewInt(0))); err == nil {
+		t.Error("expected error for a zero transfer amount")
+	}
+	amount, _ := domain.ParseTokenAmount("2.5 USDC", usdc)
+	if err := s.SetTransferAmount(amount); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s.Bots()[0].performRealTX(context.Background())
+	if got := client.tokenAmount.Load(); got == nil || got.Int64() != 2500000 {
+		t.Errorf("expected a 2500000 unit transfer, got %v", got)
+	}
+}
+
+func TestSwarm_SetTransferAmounts(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	client := &fakeClient{}
+	s := NewSwarmWithClient(2, pool, client, "key", "0xwallet", "", 0)
+	if s.Bots()[0].CanTransferTokens() {
+		t.Fatal("expected no token transfers without a token address")
+	}
+
+	kevz := domain.Token{Symbol: "KEVZ", Decimals: 18, Address: "0x00000000000000000000000000000000000000a0"}
+	usdc := domain.Token{Symbol: "USDC", Decimals: 6, Address: "0x00000000000000000000000000000000000000b0"}
+	if err := s.SetTransferAmounts(); err == nil {
+		t.Error("expected error without amounts")
+	}
+	if err := s.SetTransferAmounts(kevz.Amount(big.NewInt(1)), usdc.Amount(big.NewInt(-1))); err == nil {
+		t.Error("expected error for a negative amount")
+	}
+	if err := s.SetTransferAmounts(kevz.Amount(big.NewInt(7)), usdc.Amount(big.NewInt(9))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.AddBot(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// bots 1 and 3 transfer KEVZ, bot 2 USDC
+	for i, want := range []domain.TokenAmount{kevz.Amount(big.NewInt(7)), usdc.Amount(big.NewInt(9)), kevz.Amount(big.NewInt(7))} {
+		bot := s.Bots()[i]
+		if !bot.CanTransferTokens() {
+			t.Fatalf("bot %d: expected token transfers", bot.ID)
+		}
+		bot.performRealTX(context.Background())
+		if got := client.token.Load(); got != want.Token.Address || client.tokenAmount.Load().Cmp(want.Raw()) != 0 {
+			t.Errorf("bot %d: expected %s, sent %s of %v", bot.ID, want, client.tokenAmount.Load(), got)
+		}
+	}
+}
+
+func TestBot_TradesOnAnyAMM(t *testing.T) {
+	concentrated, _ := domain.NewConcentratedPool("ETH", "USDC", 2, 10)
+	concentrated.Mint(domain.PositionKey{Owner: 1, Lower: 0, Upper: 13860}, big.NewInt(10000000000))
+	stable, _ := domain.NewStableSwapPool([]string{"ETH", "USDC"}, []*big.Int{big.NewInt(1000000), big.NewInt(1000000)}, 100)
+
+	for name, pool := range map[string]domain.AMM{
+		"constant product": domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000)),
+		"concentrated":     concentrated,
+		"stableswap":       stable,
+	} {
+		bot := NewBot(1, pool)
+		before := pool.Snapshot()
+		for i := 0; i < 10; i++ {
+			bot.performSwap(context.Background())
+		}
+
+		if trades := bot.Portfolio().Snapshot(pool.PriceAInB()).Trades; trades == 0 {
+			t.Errorf("%s: expected the bot to trade", name)
+		}
+		if pool.Snapshot().ReserveA.Cmp(before.ReserveA) == 0 {
+			t.Errorf("%s: expected reserves to change after swaps", name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package swarm
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/nexus-bot-swarm/ports"
+)
+
+// HistoryRecorder persists every real transaction to a TxStore
+// Register it as an observer; store errors are logged, never returned to the bots
+type HistoryRecorder struct {
+	NopObserver
+	store  ports.TxStore
+	logger *slog.Logger
+}
+
+// NewHistoryRecorder creates a recorder writing to store
+func NewHistoryRecorder(store ports.TxStore, logger *slog.Logger) *HistoryRecorder {
+	return &HistoryRecorder{store: store, logger: logger}
+}
+
+func (h *HistoryRecorder) TxSent(tx TxInfo) {
+	h.save(tx, ports.TxPending, nil)
+}
+
+func (h *HistoryRecorder) TxFailed(tx TxInfo, err error) {
+	// no hash: the RPC rejected it, otherwise it was mined and reverted
+	if tx.Hash == "" {
+		h.save(tx, ports.TxFailed, err)
+		return
+	}
+	h.update(tx.Hash, func(stored *ports.StoredTx) {
+		stored.Status = ports.TxReverted
+		stored.Error = err.Error()
+	})
+}
+
+func (h *HistoryRecorder) TxConfirmed(tx TxInfo, receipt *ports.Receipt) {
+	h.update(tx.Hash, func(stored *ports.StoredTx) {
+		stored.Status = ports.TxConfirmed
+		if receipt.Status == 0 {
+			stored.Status = ports.TxReverted
+		}
+		stored.MinedAt = time.Now()
+		stored.BlockNumber = receipt.BlockNumber
+		stored.GasUsed = receipt.GasUsed
+		stored.EffectiveGasPrice = receipt.EffectiveGasPrice
+	})
+}
+
+func (h *HistoryRecorder) save(tx TxInfo, status ports.TxStatus, err error) {
+	stored := &ports.StoredTx{
+		BotID:    tx.BotID,
+		Nonce:    tx.Nonce,
+		From:     tx.From,
+		To:       tx.To,
+		Token:    tx.Token,
+		Amount:   tx.Amount,
+		Hash:     tx.Hash,
+		Status:   status,
+		SentAt:   tx.SentAt,
+		GasPrice: tx.GasPrice,
+	}
+	if err != nil {
+		stored.Error = err.Error()
+	}
+	if err := h.store.SaveTx(stored); err != nil {
+		h.logger.Warn("failed to store tx", txAttrs(tx, "bot_id", tx.BotID, "error", err)...)
+	}
+}
+
+func (h *HistoryRecorder) update(hash string, fn func(*ports.StoredTx)) {
+	if err := h.store.UpdateTx(hash, fn); err != nil {
+		h.logger.Warn("failed to update stored tx", "tx_hash", hash, "error", err)
+	}
+}
+
+// StoredTxInfo converts a stored tx back into the TxInfo reported to observers
+func StoredTxInfo(tx ports.StoredTx) TxInfo {
+	return TxInfo{
+		BotID:    tx.BotID,
+		Nonce:    tx.Nonce,
+		From:     tx.From,
+		To:       tx.To,
+		Token:    tx.Token,
+		Amount:   tx.Amount,
+		Hash:     tx.Hash,
+		GasPrice: tx.GasPrice,
+		SentAt:   tx.SentAt,
+	}
+}
@@ -0,0 +1,58 @@
+package swarm
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nexus-bot-swarm/internal/adapters/bolt"
+	"github.com/nexus-bot-swarm/ports"
+)
+
+func TestHistoryRecorder(t *testing.T) {
+	store, err := bolt.Open(filepath.Join(t.TempDir(), "txs.db"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	h := NewHistoryRecorder(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ok := TxInfo{BotID: 1, Nonce: 1, From: "0xw", To: "0xw", Amount: big.NewInt(1), Hash: "0x1", GasPrice: big.NewInt(2), SentAt: time.Now()}
+	h.TxSent(ok)
+	h.TxConfirmed(ok, &ports.Receipt{Status: 1, BlockNumber: 10, GasUsed: 21000, EffectiveGasPrice: big.NewInt(3)})
+
+	reverted := TxInfo{BotID: 2, Nonce: 2, Amount: big.NewInt(1), Hash: "0x2"}
+	h.TxSent(reverted)
+	h.TxFailed(reverted, ErrTxReverted)
+	h.TxConfirmed(reverted, &ports.Receipt{Status: 0, BlockNumber: 11})
+
+	h.TxFailed(TxInfo{BotID: 1, Nonce: 3, Amount: big.NewInt(1)}, errors.New("nonce too low"))
+
+	txs, err := store.Txs(ports.TxFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(txs) != 3 {
+		t.Fatalf("expected 3 stored txs, got %d", len(txs))
+	}
+
+	if txs[0].Status != ports.TxConfirmed || txs[0].BlockNumber != 10 || txs[0].GasPrice.Int64() != 2 || txs[0].EffectiveGasPrice.Int64() != 3 || txs[0].MinedAt.IsZero() {
+		t.Errorf("unexpected confirmed tx: %+v", txs[0])
+	}
+	if txs[1].Status != ports.TxReverted || txs[1].Error != ErrTxReverted.Error() {
+		t.Errorf("unexpected reverted tx: %+v", txs[1])
+	}
+	if txs[2].Status != ports.TxFailed || txs[2].Hash != "" || txs[2].Error != "nonce too low" {
+		t.Errorf("unexpected failed tx: %+v", txs[2])
+	}
+
+	// a stored tx converts back to the same TxInfo
+	if info := StoredTxInfo(txs[0]); info.Hash != ok.Hash || info.Nonce != ok.Nonce || info.BotID != ok.BotID || info.GasPrice.Int64() != 2 {
+		t.Errorf("unexpected tx info: %+v", info)
+	}
+}
@@ -0,0 +1,143 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/nexus-bot-swarm/ports"
+)
+
+// TxInfo describes a transaction submitted by a bot
+type TxInfo struct {
+	BotID    int
+	Nonce    uint64
+	From     string
+	To       string
+	Token    string // ERC20 contract address, empty for native NEX
+	Amount   *big.Int
+	Hash     string   // empty if the submission failed
+	GasPrice *big.Int // gas price the tx was signed with, nil if the submission failed
+	SentAt   time.Time
+}
+
+// Observer receives notifications about bot activity
+// Used by metrics, reports and recorders - implementations must be thread-safe
+// and must not block, since they are called from the bot goroutines
+type Observer interface {
+	// SwapExecuted is called after every successful simulated swap
+	SwapExecuted(botID int, order *Order, amountOut *big.Int)
+
+	// SwapRejected is called when a swap fails (e.g. insufficient balance)
+	SwapRejected(botID int, order *Order, err error)
+
+	// TxSent is called when a real transaction was accepted by the RPC
+	TxSent(tx TxInfo)
+
+	// TxFailed is called when a submission fails or a receipt reports a revert
+	TxFailed(tx TxInfo, err error)
+
+	// TxConfirmed is called when the receipt of a sent transaction is found
+	// Reverted txs are mined too: they get TxFailed(ErrTxReverted) then TxConfirmed
+	TxConfirmed(tx TxInfo, receipt *ports.Receipt)
+
+	// NonceResynced is called when the nonce manager is re-synced from the RPC
+	NonceResynced(botID int, from, to uint64)
+}
+
+// NopObserver ignores all events
+// Embed it to implement only the callbacks you care about
+type NopObserver struct{}
+
+func (NopObserver) SwapExecuted(int, *Order, *big.Int) {}
+func (NopObserver) SwapRejected(int, *Order, error)    {}
+func (NopObserver) TxSent(TxInfo)                      {}
+func (NopObserver) TxFailed(TxInfo, error)             {}
+func (NopObserver) TxConfirmed(TxInfo, *ports.Receipt) {}
+func (NopObserver) NonceResynced(int, uint64, uint64)  {}
+
+// MultiObserver fans events out to several observers
+type MultiObserver []Observer
+
+func (m MultiObserver) SwapExecuted(botID int, order *Order, amountOut *big.Int) {
+	for _, o := range m {
+		o.SwapExecuted(botID, order, amountOut)
+	}
+}
+
+func (m MultiObserver) SwapRejected(botID int, order *Order, err error) {
+	for _, o := range m {
+		o.SwapRejected(botID, order, err)
+	}
+}
+
+func (m MultiObserver) TxSent(tx TxInfo) {
+	for _, o := range m {
+		o.TxSent(tx)
+	}
+}
+
+func (m MultiObserver) TxFailed(tx TxInfo, err error) {
+	for _, o := range m {
+		o.TxFailed(tx, err)
+	}
+}
+
+func (m MultiObserver) TxConfirmed(tx TxInfo, receipt *ports.Receipt) {
+	for _, o := range m {
+		o.TxConfirmed(tx, receipt)
+	}
+}
+
+func (m MultiObserver) NonceResynced(botID int, from, to uint64) {
+	for _, o := range m {
+		o.NonceResynced(botID, from, to)
+	}
+}
+
+// ErrTxReverted is reported through TxFailed when a mined tx has status 0
+var ErrTxReverted = errors.New("transaction reverted")
+
+// ErrorClass buckets an error into a small, stable set of labels
+// Used as a metrics label and log field, so keep the set bounded
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	if errors.Is(err, ErrInsufficientBalance) {
+		return "insufficient_balance"
+	}
+	if errors.Is(err, ErrTxReverted) {
+		return "reverted"
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case isNonceTooLowError(err):
+		return "nonce_too_low"
+	case strings.Contains(msg, "reverted"):
+		return "reverted"
+	case strings.Contains(msg, "insufficient funds"):
+		return "insufficient_funds"
+	case strings.Contains(msg, "underpriced"):
+		return "underpriced"
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "deadline"):
+		return "timeout"
+	case strings.Contains(msg, "429"), strings.Contains(msg, "rate limit"), strings.Contains(msg, "too many requests"):
+		return "rate_limited"
+	case strings.Contains(msg, "connection"), strings.Contains(msg, "eof"), strings.Contains(msg, "no such host"):
+		return "network"
+	case strings.Contains(msg, "invalid"):
+		return "invalid_input"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,56 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{context.Canceled, "canceled"},
+		{fmt.Errorf("send: %w", context.DeadlineExceeded), "timeout"},
+		{ErrInsufficientBalance, "insufficient_balance"},
+		{errors.New("failed to send tx: nonce too low"), "nonce_too_low"},
+		{errors.New("already known"), "nonce_too_low"},
+		{errors.New("insufficient funds for gas * price + value"), "insufficient_funds"},
+		{errors.New("replacement transaction underpriced"), "underpriced"},
+		{errors.New("transaction reverted: swapExactTokensForTokens tx 0x01"), "reverted"},
+		{errors.New("429 Too Many Requests"), "rate_limited"},
+		{errors.New("something odd"), "other"},
+	}
+
+	for _, tt := range tests {
+		if got := ErrorClass(tt.err); got != tt.want {
+			t.Errorf("ErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+// countingObserver counts swaps, ignores everything else
+type countingObserver struct {
+	NopObserver
+	swaps int
+}
+
+func (c *countingObserver) SwapExecuted(int, *Order, *big.Int) {
+	c.swaps++
+}
+
+func TestMultiObserver(t *testing.T) {
+	a, b := &countingObserver{}, &countingObserver{}
+	m := MultiObserver{a, b}
+
+	m.SwapExecuted(1, &Order{Direction: AToB, AmountIn: big.NewInt(1)}, big.NewInt(1))
+	m.TxSent(TxInfo{})
+
+	if a.swaps != 1 || b.swaps != 1 {
+		t.Errorf("expected both observers to see 1 swap, got %d and %d", a.swaps, b.swaps)
+	}
+}
@@ -0,0 +1,176 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/nexus-bot-swarm/domain"
+)
+
+// ErrInsufficientBalance is returned when a bot tries to swap more than it holds
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// Portfolio tracks a bot's TokenA/TokenB holdings and its P&L
+// P&L is measured in TokenB. Realized P&L uses average cost:
+// buying A adds the B spent to the cost basis, selling A realizes
+// the difference between the B received and the average cost of the A sold
+// Thread-safe: the bot trades while the swarm reads snapshots. The lock is
+// not held while the pool executes a swap: its input is debited up front and
+// counted in flight until the swap settles
+type Portfolio struct {
+	mu           sync.Mutex
+	balanceA     *big.Int
+	balanceB     *big.Int
+	inFlightA    *big.Int // inputs of swaps being executed
+	inFlightB    *big.Int
+	costBasis    float64 // B paid for the A currently held
+	realized     float64
+	initialValue float64
+	trades       int
+	rejected     int
+}
+
+// PortfolioSnapshot is a point-in-time copy of a portfolio
+type PortfolioSnapshot struct {
+	BalanceA   *big.Int `json:"balance_a"` // without the inputs of swaps in flight
+	BalanceB   *big.Int `json:"balance_b"`
+	Trades     int      `json:"trades"`
+	Rejected   int      `json:"rejected"`
+	Realized   float64  `json:"realized_pnl"`   // realized P&L in TokenB
+	Unrealized float64  `json:"unrealized_pnl"` // unrealized P&L of the A held, in TokenB
+	PnL        float64  `json:"pnl"`            // mark-to-market P&L vs the initial value, in TokenB
+}
+
+// NewPortfolio creates a portfolio funded with the given balances
+// priceAInB values the initial A holdings (it becomes their cost basis)
+func NewPortfolio(balanceA, balanceB *big.Int, priceAInB float64) *Portfolio {
+	p := &Portfolio{
+		balanceA:  new(big.Int).Set(balanceA),
+		balanceB:  new(big.Int).Set(balanceB),
+		inFlightA: new(big.Int),
+		inFlightB: new(big.Int),
+	}
+	p.costBasis = toFloat(p.balanceA) * priceAInB
+	p.initialValue = p.value(priceAInB)
+	return p
+}
+
+// Swap executes an order on the pool against the portfolio balances
+// Returns ErrInsufficientBalance without touching the pool if the bot
+// cannot pay for the order
+func (p *Portfolio) Swap(pool domain.AMM, order *Order) (*big.Int, error) {
+	return p.SwapContext(context.Background(), pool, order)
+}
+
+// SwapContext is Swap bounded by ctx on pools that wait on I/O
+// The input is reserved under the lock, which is released while the pool
+// executes the order, then the swap is settled. A failed swap gives the
+// input back; one cut short by ctx is not counted as rejected
+func (p *Portfolio) SwapContext(ctx context.Context, pool domain.AMM, order *Order) (*big.Int, error) {
+	if err := p.reserve(order); err != nil {
+		return nil, err
+	}
+	out, err := ExecuteContext(ctx, pool, order)
+	p.settle(order, out, err, cancelled(ctx, err))
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// reserve debits the input of an order and counts it in flight
+func (p *Portfolio) reserve(order *Order) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	spend, inFlight := p.balanceA, p.inFlightA
+	if order.Direction == BToA {
+		spend, inFlight = p.balanceB, p.inFlightB
+	}
+	if spend.Cmp(order.AmountIn) < 0 {
+		p.rejected++
+		return ErrInsufficientBalance
+	}
+	spend.Sub(spend, order.AmountIn)
+	inFlight.Add(inFlight, order.AmountIn)
+	return nil
+}
+
+// settle ends a swap started by reserve: a failed swap gives its input
+// back, a successful one credits the output and updates the P&L
+func (p *Portfolio) settle(order *Order, out *big.Int, err error, cancelled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	spend, receive, inFlight := p.balanceA, p.balanceB, p.inFlightA
+	if order.Direction == BToA {
+		spend, receive, inFlight = p.balanceB, p.balanceA, p.inFlightB
+	}
+	inFlight.Sub(inFlight, order.AmountIn)
+	if err != nil {
+		spend.Add(spend, order.AmountIn)
+		if !cancelled {
+			p.rejected++
+		}
+		return
+	}
+
+	if order.Direction == AToB {
+		// selling A: realize against average cost of the A held before the swap
+		soldCost := p.costBasis * toFloat(order.AmountIn) / toFloat(new(big.Int).Add(p.heldA(), order.AmountIn))
+		p.realized += toFloat(out) - soldCost
+		p.costBasis -= soldCost
+	} else {
+		// buying A: B spent becomes cost basis
+		p.costBasis += toFloat(order.AmountIn)
+	}
+
+	receive.Add(receive, out)
+	p.trades++
+}
+
+// heldA returns the A owned, including inputs of swaps in flight
+// Callers must hold p.mu
+func (p *Portfolio) heldA() *big.Int {
+	return new(big.Int).Add(p.balanceA, p.inFlightA)
+}
+
+// heldB returns the B owned, including inputs of swaps in flight
+// Callers must hold p.mu
+func (p *Portfolio) heldB() *big.Int {
+	return new(big.Int).Add(p.balanceB, p.inFlightB)
+}
+
+// Snapshot returns balances and P&L marked at the given price of A in B
+func (p *Portfolio) Snapshot(priceAInB float64) PortfolioSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PortfolioSnapshot{
+		BalanceA:   new(big.Int).Set(p.balanceA),
+		BalanceB:   new(big.Int).Set(p.balanceB),
+		Trades:     p.trades,
+		Rejected:   p.rejected,
+		Realized:   p.realized,
+		Unrealized: toFloat(p.heldA())*priceAInB - p.costBasis,
+		PnL:        p.value(priceAInB) - p.initialValue,
+	}
+}
+
+// Value returns the portfolio value in TokenB at the given price
+func (p *Portfolio) Value(priceAInB float64) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.value(priceAInB)
+}
+
+func (p *Portfolio) value(priceAInB float64) float64 {
+	return toFloat(p.heldB()) + toFloat(p.heldA())*priceAInB
+}
+
+func toFloat(x *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(x).Float64()
+	return f
+}
@@ -0,0 +1,145 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/nexus-bot-swarm/domain"
+)
+
+func TestPortfolio_Swap_UpdatesBalances(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	p := NewPortfolio(big.NewInt(500), big.NewInt(500), pool.PriceAInB())
+
+	// 100 A -> 181 B (see TestPool_SwapAForB)
+	out, err := p.Swap(pool, &Order{Direction: AToB, AmountIn: big.NewInt(100)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Int64() != 181 {
+		t.Errorf("expected 181 out, got %s", out.String())
+	}
+
+	snap := p.Snapshot(pool.PriceAInB())
+	if snap.BalanceA.Int64() != 400 {
+		t.Errorf("expected BalanceA=400, got %s", snap.BalanceA.String())
+	}
+	if snap.BalanceB.Int64() != 681 {
+		t.Errorf("expected BalanceB=681, got %s", snap.BalanceB.String())
+	}
+	if snap.Trades != 1 {
+		t.Errorf("expected 1 trade, got %d", snap.Trades)
+	}
+
+	// sold 100 A at cost 2 each (200 B) for 181 B: realized -19
+	if math.Abs(snap.Realized-(-19)) > 1e-9 {
+		t.Errorf("expected realized -19, got %f", snap.Realized)
+	}
+}
+
+func TestPortfolio_Swap_InsufficientBalance(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	p := NewPortfolio(big.NewInt(10), big.NewInt(0), pool.PriceAInB())
+
+	_, err := p.Swap(pool, &Order{Direction: AToB, AmountIn: big.NewInt(11)})
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+
+	// pool must be untouched
+	if pool.ReserveA.Int64() != 1000 {
+		t.Errorf("pool should not change on rejected swap, ReserveA=%s", pool.ReserveA.String())
+	}
+	if snap := p.Snapshot(pool.PriceAInB()); snap.Rejected != 1 {
+		t.Errorf("expected 1 rejected swap, got %d", snap.Rejected)
+	}
+}
+
+func TestPortfolio_PnL_Decomposition(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(100000), big.NewInt(200000))
+	p := NewPortfolio(big.NewInt(1000), big.NewInt(2000), pool.PriceAInB())
+
+	p.Swap(pool, &Order{Direction: BToA, AmountIn: big.NewInt(500)})
+	p.Swap(pool, &Order{Direction: AToB, AmountIn: big.NewInt(300)})
+	p.Swap(pool, &Order{Direction: BToA, AmountIn: big.NewInt(100)})
+
+	// mark-to-market P&L = realized + unrealized
+	snap := p.Snapshot(pool.PriceAInB())
+	if math.Abs(snap.PnL-(snap.Realized+snap.Unrealized)) > 1e-6 {
+		t.Errorf("pnl %f != realized %f + unrealized %f", snap.PnL, snap.Realized, snap.Unrealized)
+	}
+}
+
+// slowPool is a pool whose swaps wait, like an on-chain pair, until
+// released or cancelled
+type slowPool struct {
+	*domain.Pool
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *slowPool) SwapContext(ctx context.Context, trader int, direction Direction, amountIn *big.Int) (*big.Int, error) {
+	p.started <- struct{}{}
+	select {
+	case <-p.release:
+		return p.Swap(trader, direction, amountIn)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestPortfolio_SwapContext_InFlight(t *testing.T) {
+	pool := &slowPool{
+		Pool:    domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000)),
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	p := NewPortfolio(big.NewInt(500), big.NewInt(500), pool.PriceAInB())
+	initial := p.Value(2)
+
+	done := make(chan error)
+	go func() {
+		_, err := p.SwapContext(context.Background(), pool, &Order{Direction: AToB, AmountIn: big.NewInt(300)})
+		done <- err
+	}()
+	<-pool.started
+
+	// the portfolio is readable while the swap waits, with the input reserved
+	snap := p.Snapshot(2)
+	if snap.BalanceA.Int64() != 200 || p.Value(2) != initial {
+		t.Errorf("expected 300 A in flight and an unchanged value, got %+v", snap)
+	}
+	if _, err := p.Swap(pool.Pool, &Order{Direction: AToB, AmountIn: big.NewInt(300)}); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("the reserved input must not be spent twice, got %v", err)
+	}
+
+	close(pool.release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snap := p.Snapshot(pool.PriceAInB()); snap.BalanceA.Int64() != 200 || snap.Trades != 1 {
+		t.Errorf("unexpected settled portfolio: %+v", snap)
+	}
+}
+
+func TestPortfolio_SwapContext_Cancelled(t *testing.T) {
+	pool := &slowPool{
+		Pool:    domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000)),
+		started: make(chan struct{}, 1),
+	}
+	p := NewPortfolio(big.NewInt(500), big.NewInt(500), pool.PriceAInB())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.SwapContext(ctx, pool, &Order{Direction: BToA, AmountIn: big.NewInt(100)}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation, got %v", err)
+	}
+
+	// the input is given back, and a swap cut short is not a rejection
+	if snap := p.Snapshot(2); snap.BalanceB.Int64() != 500 || snap.Rejected != 0 || snap.Trades != 0 {
+		t.Errorf("unexpected portfolio after a cancelled swap: %+v", snap)
+	}
+}
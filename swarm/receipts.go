@@ -0,0 +1,58 @@
+package swarm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// receiptTracker runs receipt polls detached from the bot loops, so a
+// shutdown can stop the bots and still wait for the receipts of sent txs
+type receiptTracker struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	pending atomic.Int64
+}
+
+func newReceiptTracker() *receiptTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &receiptTracker{ctx: ctx, cancel: cancel}
+}
+
+// track runs fn in its own goroutine with the tracker context
+func (t *receiptTracker) track(fn func(ctx context.Context)) {
+	t.wg.Add(1)
+	t.pending.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer t.pending.Add(-1)
+		fn(t.ctx)
+	}()
+}
+
+// wait blocks until every poll is done or ctx expires
+func (t *receiptTracker) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stop abandons the polls still running
+func (t *receiptTracker) stop() {
+	t.cancel()
+}
+
+// count returns the number of polls still running
+func (t *receiptTracker) count() int {
+	return int(t.pending.Load())
+}
@@ -0,0 +1,234 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"github.com/nexus-bot-swarm/candles"
+	"github.com/nexus-bot-swarm/domain"
+)
+
+// Direction tells which side of the pool a swap goes through
+type Direction = domain.Direction
+
+const (
+	// AToB sells TokenA for TokenB
+	AToB = domain.AToB
+	// BToA sells TokenB for TokenA
+	BToA = domain.BToA
+)
+
+// Order is a single swap a strategy wants a bot to execute
+type Order struct {
+	Direction Direction
+	AmountIn  *big.Int
+	BotID     int // set by the executing bot, reported in pool swap events
+}
+
+// Strategy decides what a bot does on every simulated tick
+// Implementations only read the pool - the bot executes the returned order
+type Strategy interface {
+	// Name identifies the strategy in logs and reports
+	Name() string
+
+	// Next returns the order for this tick, or nil to skip it
+	Next(pool domain.AMM) *Order
+}
+
+// HistoryAware is implemented by strategies that read past prices
+// The swarm hands them its candle series when they are attached to a bot
+type HistoryAware interface {
+	SetHistory(series *candles.Series)
+}
+
+// Execute applies an order to the pool and returns the amount received
+func Execute(pool domain.AMM, order *Order) (*big.Int, error) {
+	return pool.Swap(order.BotID, order.Direction, order.AmountIn)
+}
+
+// ExecuteContext is Execute bounded by ctx on pools that wait on I/O
+// (domain.ContextSwapper); other pools swap at once and ignore ctx
+func ExecuteContext(ctx context.Context, pool domain.AMM, order *Order) (*big.Int, error) {
+	if swapper, ok := pool.(domain.ContextSwapper); ok {
+		return swapper.SwapContext(ctx, order.BotID, order.Direction, order.AmountIn)
+	}
+	return Execute(pool, order)
+}
+
+// NewStrategy builds a strategy by name with default parameters
+// rng may be nil to use the global random source
+func NewStrategy(name string, rng *rand.Rand) (Strategy, error) {
+	switch name {
+	case "", "random":
+		return NewRandomStrategy(100, rng), nil
+	case "mean-reversion":
+		return NewMeanReversionStrategy(0, 0.01, 100), nil
+	case "momentum":
+		return NewMomentumStrategy(5, 20, 100), nil
+	default:
+		return nil, fmt.Errorf("unknown strategy: %s", name)
+	}
+}
+
+// RandomStrategy swaps a random amount in a random direction every tick
+// This is the original bot behaviour: pure noise traffic on the pool
+type RandomStrategy struct {
+	maxAmount int64
+	rng       *rand.Rand
+}
+
+// NewRandomStrategy creates a random strategy trading 1..maxAmount units
+// Pass a seeded rng for reproducible runs (backtests), nil for the global source
+func NewRandomStrategy(maxAmount int64, rng *rand.Rand) *RandomStrategy {
+	return &RandomStrategy{
+		maxAmount: maxAmount,
+		rng:       rng,
+	}
+}
+
+// Name returns the strategy name
+func (s *RandomStrategy) Name() string {
+	return "random"
+}
+
+// Next returns a random order
+func (s *RandomStrategy) Next(pool domain.AMM) *Order {
+	amount := big.NewInt(s.int63n(s.maxAmount) + 1)
+
+	direction := AToB
+	if s.int63n(2) == 1 {
+		direction = BToA
+	}
+	return &Order{Direction: direction, AmountIn: amount}
+}
+
+func (s *RandomStrategy) int63n(n int64) int64 {
+	if s.rng != nil {
+		return s.rng.Int63n(n)
+	}
+	return rand.Int63n(n)
+}
+
+// MeanReversionStrategy trades against deviations from a reference price
+// If TokenA got expensive it sells A, if it got cheap it buys A back
+// With a price history the reference follows a moving average of the closes
+type MeanReversionStrategy struct {
+	reference float64
+	threshold float64
+	amount    int64
+	history   *candles.Series
+	window    int
+}
+
+// NewMeanReversionStrategy creates a mean reversion strategy
+// reference is the fair price of A in B (0 = use the first price seen)
+// threshold is the relative deviation that triggers a trade (0.01 = 1%)
+func NewMeanReversionStrategy(reference, threshold float64, amount int64) *MeanReversionStrategy {
+	return &MeanReversionStrategy{
+		reference: reference,
+		threshold: threshold,
+		amount:    amount,
+	}
+}
+
+// Name returns the strategy name
+func (s *MeanReversionStrategy) Name() string {
+	return "mean-reversion"
+}
+
+// SetHistory makes the reference the average of the last 20 closes
+func (s *MeanReversionStrategy) SetHistory(series *candles.Series) {
+	s.history = series
+	s.window = 20
+}
+
+// Next returns an order pushing the price back to the reference, or nil
+func (s *MeanReversionStrategy) Next(pool domain.AMM) *Order {
+	price := pool.PriceAInB()
+	if s.history != nil {
+		if avg, ok := s.history.SMA(s.window); ok {
+			s.reference = avg
+		}
+	}
+	if s.reference == 0 {
+		s.reference = price
+		return nil
+	}
+
+	deviation := (price - s.reference) / s.reference
+	switch {
+	case deviation > s.threshold:
+		// A is expensive: sell A
+		return &Order{Direction: AToB, AmountIn: big.NewInt(s.amount)}
+	case deviation < -s.threshold:
+		// A is cheap: buy A with B (amount scaled to B units)
+		amountB := int64(float64(s.amount) * price)
+		if amountB <= 0 {
+			amountB = 1
+		}
+		return &Order{Direction: BToA, AmountIn: big.NewInt(amountB)}
+	default:
+		return nil
+	}
+}
+
+// MomentumStrategy follows the trend of a moving average crossover
+// It buys A while the short average is above the long one and sells A
+// while it is below. Without a price history it never trades
+type MomentumStrategy struct {
+	short   int
+	long    int
+	amount  int64
+	history *candles.Series
+}
+
+// NewMomentumStrategy creates a momentum strategy comparing the averages
+// of the last short and long candle closes
+func NewMomentumStrategy(short, long int, amount int64) *MomentumStrategy {
+	return &MomentumStrategy{
+		short:  short,
+		long:   long,
+		amount: amount,
+	}
+}
+
+// Name returns the strategy name
+func (s *MomentumStrategy) Name() string {
+	return "momentum"
+}
+
+// SetHistory sets the candle series the averages are computed on
+func (s *MomentumStrategy) SetHistory(series *candles.Series) {
+	s.history = series
+}
+
+// Next returns an order following the trend, or nil without a clear one
+func (s *MomentumStrategy) Next(pool domain.AMM) *Order {
+	if s.history == nil {
+		return nil
+	}
+	short, ok := s.history.SMA(s.short)
+	if !ok {
+		return nil
+	}
+	long, ok := s.history.SMA(s.long)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case short > long:
+		// A is trending up: buy A with B (amount scaled to B units)
+		amountB := int64(float64(s.amount) * pool.PriceAInB())
+		if amountB <= 0 {
+			amountB = 1
+		}
+		return &Order{Direction: BToA, AmountIn: big.NewInt(amountB)}
+	case short < long:
+		return &Order{Direction: AToB, AmountIn: big.NewInt(s.amount)}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package swarm
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/nexus-bot-swarm/candles"
+	"github.com/nexus-bot-swarm/domain"
+)
+
+func TestRandomStrategy_Deterministic(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+
+	s1 := NewRandomStrategy(100, rand.New(rand.NewSource(42)))
+	s2 := NewRandomStrategy(100, rand.New(rand.NewSource(42)))
+
+	for i := 0; i < 10; i++ {
+		o1, o2 := s1.Next(pool), s2.Next(pool)
+		if o1.Direction != o2.Direction || o1.AmountIn.Cmp(o2.AmountIn) != 0 {
+			t.Fatalf("same seed produced different orders at step %d", i)
+		}
+		if o1.AmountIn.Int64() < 1 || o1.AmountIn.Int64() > 100 {
+			t.Errorf("amount out of range: %s", o1.AmountIn.String())
+		}
+	}
+}
+
+func TestMeanReversionStrategy(t *testing.T) {
+	s := NewMeanReversionStrategy(2.0, 0.05, 10)
+
+	// at the reference price: no trade
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	if order := s.Next(pool); order != nil {
+		t.Errorf("expected no order at reference price, got %v", order)
+	}
+
+	// A expensive (price 3): sell A
+	pool = domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(3000))
+	if order := s.Next(pool); order == nil || order.Direction != AToB {
+		t.Errorf("expected A->B order when A is expensive, got %v", order)
+	}
+
+	// A cheap (price 1): buy A
+	pool = domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(1000))
+	if order := s.Next(pool); order == nil || order.Direction != BToA {
+		t.Errorf("expected B->A order when A is cheap, got %v", order)
+	}
+}
+
+// priceHistory builds a 1s candle series closing at the given prices
+func priceHistory(prices ...float64) *candles.Series {
+	series := candles.NewSeries(time.Second, 0)
+	start := time.Now()
+	for i, p := range prices {
+		series.Observe(start.Add(time.Duration(i)*time.Second), p)
+	}
+	return series
+}
+
+func TestMomentumStrategy(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	s := NewMomentumStrategy(2, 4, 10)
+
+	// no history, or not enough of it: no trade
+	if order := s.Next(pool); order != nil {
+		t.Errorf("expected no order without history, got %v", order)
+	}
+	s.SetHistory(priceHistory(2, 2, 2))
+	if order := s.Next(pool); order != nil {
+		t.Errorf("expected no order with a short history, got %v", order)
+	}
+
+	// rising: buy A, scaled to B units
+	s.SetHistory(priceHistory(1, 1, 2, 2))
+	if order := s.Next(pool); order == nil || order.Direction != BToA || order.AmountIn.Int64() != 20 {
+		t.Errorf("expected B->A order of 20 in an uptrend, got %v", order)
+	}
+
+	// falling: sell A
+	s.SetHistory(priceHistory(3, 3, 2, 2))
+	if order := s.Next(pool); order == nil || order.Direction != AToB {
+		t.Errorf("expected A->B order in a downtrend, got %v", order)
+	}
+}
+
+func TestMeanReversionStrategy_MovingReference(t *testing.T) {
+	s := NewMeanReversionStrategy(2.0, 0.05, 10)
+	closes := make([]float64, 20)
+	for i := range closes {
+		closes[i] = 3
+	}
+	s.SetHistory(priceHistory(closes...))
+
+	// the fixed reference would sell A at price 3, the average says it is fair
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(3000))
+	if order := s.Next(pool); order != nil {
+		t.Errorf("expected no order at the moving average, got %v", order)
+	}
+}
+
+func TestNewStrategy_Unknown(t *testing.T) {
+	if _, err := NewStrategy("martingale", nil); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
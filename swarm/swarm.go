@@ -2,96 +2,850 @@ package swarm
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/nexus-bot-swarm/candles"
 	"github.com/nexus-bot-swarm/domain"
 	"github.com/nexus-bot-swarm/internal/nonce"
 	"github.com/nexus-bot-swarm/ports"
 )
 
-// Swarm coordinates multiple bots operating on a shared pool
+var (
+	// ErrBotNotFound is returned when a bot ID does not exist in the swarm
+	ErrBotNotFound = errors.New("bot not found")
+
+	// ErrSwarmStopped is returned when adding bots after the swarm context ended
+	ErrSwarmStopped = errors.New("swarm stopped")
+
+	// ErrTooManyBots is returned when adding bots past the swarm limit
+	ErrTooManyBots = errors.New("too many bots")
+
+	// ErrSwarmStarted is returned when Start is called more than once
+	ErrSwarmStarted = errors.New("swarm already started")
+)
+
+// Swarm coordinates multiple bots operating on a shared pool, or spread
+// over several pools (SetPools)
+// Bots can be added, removed, paused and reconfigured while it runs
 type Swarm struct {
+	mu           sync.Mutex
+	scaleMu      sync.Mutex // serializes Scale calls
 	bots         []*Bot
-	pool         *domain.Pool
+	running      map[int]*botRun
+	nextID       int
+	pool         domain.AMM    // the first pool
+	pools        []*tradedPool // bots take them in turn, see poolFor
 	nonceManager *nonce.Manager
+	logger       *slog.Logger
+
+	// settings applied to every bot, including bots added at runtime
+	client        ports.BlockchainClient
+	privateKey    string
+	walletAddress string
+	tokenAddress  string
+	observer      Observer
+	swapLogEvery  int
+	txInterval    time.Duration
+	swapInterval  time.Duration
+	strategy      string // default strategy name, empty = NewBot's
+	maxBots       int    // 0 = no limit
+	transfers     []domain.TokenAmount
+
+	// nonce managers by lowercase wallet address, the swarm wallet included
+	wallets map[string]*nonce.Manager
+
+	// receipt polls of every bot, outlive the bot loops on Stop
+	receipts *receiptTracker
+
+	// set by Start, a non-nil ctx means the swarm was started
+	ctx     context.Context
+	cancel  context.CancelFunc
+	errCh   chan error
+	done    chan struct{} // closed once every bot loop has exited
+	wg      sync.WaitGroup
+	stopped bool
+}
+
+// tradedPool is a pool of the swarm with the settings of the bots trading it
+type tradedPool struct {
+	amm      domain.AMM
+	balanceA *big.Int // starting balances of the bots
+	balanceB *big.Int
+	history  *candles.Series // handed to HistoryAware strategies
+}
+
+func newTradedPool(pool domain.AMM) *tradedPool {
+	return &tradedPool{amm: pool, balanceA: DefaultBalanceA, balanceB: DefaultBalanceB}
+}
+
+// botRun tracks a running bot goroutine
+type botRun struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// BotStatus is a point-in-time view of a bot
+type BotStatus struct {
+	ID         int               `json:"id"`
+	Pool       string            `json:"pool"` // pair traded, e.g. ETH/USDC
+	Strategy   string            `json:"strategy"`
+	Running    bool              `json:"running"`
+	Paused     bool              `json:"paused"`
+	RealTX     bool              `json:"real_tx"`
+	TxInterval string            `json:"tx_interval"`
+	Portfolio  PortfolioSnapshot `json:"portfolio"`
+}
+
+// Snapshot is the state of the swarm at a point in time
+type Snapshot struct {
+	Time       time.Time             `json:"time"`
+	Pool       domain.PoolSnapshot   `json:"pool"`  // the first pool
+	Pools      []domain.PoolSnapshot `json:"pools"` // every pool, the first one included
+	Bots       []BotStatus           `json:"bots"`
+	PendingTxs int                   `json:"pending_txs"` // sent txs whose receipt was not observed
 }
 
 // NewSwarm creates a swarm with the specified number of bots (simulation only)
-func NewSwarm(botCount int, pool *domain.Pool) *Swarm {
-	bots := make([]*Bot, botCount)
-	for i := 0; i < botCount; i++ {
-		bots[i] = NewBot(i+1, pool)
+func NewSwarm(botCount int, pool domain.AMM) *Swarm {
+	s := &Swarm{
+		receipts:     newReceiptTracker(),
+		done:         make(chan struct{}),
+		running:      make(map[int]*botRun),
+		pool:         pool,
+		pools:        []*tradedPool{newTradedPool(pool)},
+		logger:       slog.Default(),
+		observer:     NopObserver{},
+		swapLogEvery: DefaultSwapLogEvery,
+		txInterval:   DefaultTxInterval,
+		swapInterval: DefaultSwapInterval,
+		transfers:    []domain.TokenAmount{DefaultTransferAmount},
 	}
-	return &Swarm{
-		bots: bots,
-		pool: pool,
+	for i := 0; i < botCount; i++ {
+		s.bots = append(s.bots, s.newBot())
 	}
+	return s
 }
 
 // NewSwarmWithClient creates a swarm that can send real transactions
 // startNonce should be fetched from the RPC before calling this
 // tokenAddress is optional - if provided, bots will transfer ERC20 tokens instead of NEX
-func NewSwarmWithClient(botCount int, pool *domain.Pool, client ports.BlockchainClient, privateKey, walletAddress, tokenAddress string, startNonce uint64) *Swarm {
-	// create shared nonce manager
-	nm := nonce.NewManager(startNonce)
+func NewSwarmWithClient(botCount int, pool domain.AMM, client ports.BlockchainClient, privateKey, walletAddress, tokenAddress string, startNonce uint64) *Swarm {
+	s := NewSwarm(0, pool)
+
+	// all bots share the same nonce manager
+	s.nonceManager = nonce.NewManager(startNonce)
+	s.client = client
+	s.privateKey = privateKey
+	s.walletAddress = walletAddress
+	s.tokenAddress = tokenAddress
+	s.wallets = map[string]*nonce.Manager{strings.ToLower(walletAddress): s.nonceManager}
 
-	bots := make([]*Bot, botCount)
 	for i := 0; i < botCount; i++ {
-		// all bots share the same nonce manager
-		bots[i] = NewBotWithClient(i+1, pool, client, privateKey, walletAddress, tokenAddress, nm)
+		s.bots = append(s.bots, s.newBot())
 	}
-	return &Swarm{
-		bots:         bots,
-		pool:         pool,
-		nonceManager: nm,
+	return s
+}
+
+// newBot creates the next bot with the swarm settings
+// Callers must hold s.mu once the swarm is shared
+func (s *Swarm) newBot() *Bot {
+	s.nextID++
+	pool := s.poolFor(s.nextID)
+
+	var b *Bot
+	if s.client != nil {
+		b = NewBotWithClient(s.nextID, pool.amm, s.client, s.privateKey, s.walletAddress, s.tokenAddress, s.nonceManager)
+	} else {
+		b = NewBot(s.nextID, pool.amm)
+	}
+	b.receipts = s.receipts
+	b.Fund(pool.balanceA, pool.balanceB)
+	b.SetObserver(s.observer)
+	b.SetLogger(s.logger, s.swapLogEvery)
+	b.SetTxInterval(s.txInterval)
+	b.SetTransferAmount(s.transferFor(b.ID))
+	b.SetSwapInterval(s.swapInterval)
+	if s.strategy != "" {
+		// the name was checked by SetDefaultStrategy
+		strategy, _ := NewStrategy(s.strategy, nil)
+		s.attachHistory(b.ID, strategy)
+		b.SetStrategy(strategy)
 	}
+	return b
+}
+
+// poolFor returns the pool of a bot: bots take the pools in turn by ID,
+// bot 1 the first pool, bot 2 the second one...
+func (s *Swarm) poolFor(botID int) *tradedPool {
+	n := len(s.pools)
+	return s.pools[((botID-1)%n+n)%n]
 }
 
 // Start launches all bots and returns a channel for errors
-// The channel is closed when all bots have stopped
-func (s *Swarm) Start(ctx context.Context) <-chan error {
-	// buffered channel to collect errors from all bots
-	errCh := make(chan error, len(s.bots))
+// The channel stays open while the swarm runs (bots may be added or removed)
+// and is closed once ctx is done (or Stop is called) and every bot has stopped
+// Callers must drain it
+// A swarm runs once: later calls return ErrSwarmStarted, even after Stop
+func (s *Swarm) Start(ctx context.Context) (<-chan error, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	var wg sync.WaitGroup
-	wg.Add(len(s.bots))
+	if s.ctx != nil {
+		return nil, ErrSwarmStarted
+	}
 
-	log.Printf("Starting swarm with %d bots", len(s.bots))
+	// buffered channel to collect errors from all bots
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.errCh = make(chan error, len(s.bots))
+
+	s.logger.Info("starting swarm", "bots", len(s.bots))
 
 	for _, bot := range s.bots {
-		go func(b *Bot) {
-			defer wg.Done()
-
-			// each bot gets its own error channel
-			botErrCh := make(chan error, 1)
-			go b.Run(ctx, botErrCh)
-
-			// wait for bot to finish and forward error
-			for err := range botErrCh {
-				if err != nil {
-					errCh <- err
-				}
-			}
-		}(bot)
+		s.startBot(bot)
 	}
 
-	// close errCh when all bots are done
+	// close errCh once the swarm is cancelled and all bots are done
+	// stopped is set under the lock so no bot is added after Wait starts
+	go func() {
+		<-s.ctx.Done()
+		s.mu.Lock()
+		s.stopped = true
+		s.mu.Unlock()
+
+		s.wg.Wait()
+		close(s.errCh)
+		close(s.done)
+		s.logger.Info("swarm stopped")
+	}()
+
+	return s.errCh, nil
+}
+
+// startBot runs a bot with its own cancelable context
+// Callers must hold s.mu
+func (s *Swarm) startBot(b *Bot) {
+	botCtx, cancel := context.WithCancel(s.ctx)
+	run := &botRun{cancel: cancel, done: make(chan struct{})}
+	s.running[b.ID] = run
+
+	s.wg.Add(1)
 	go func() {
-		wg.Wait()
-		close(errCh)
-		log.Println("Swarm stopped")
+		defer s.wg.Done()
+		defer close(run.done)
+
+		// each bot gets its own error channel
+		botErrCh := make(chan error, 1)
+		go b.Run(botCtx, botErrCh)
+
+		// wait for bot to finish and forward error
+		// a bot removed from a running swarm is not an error
+		for err := range botErrCh {
+			if err == nil || (errors.Is(err, context.Canceled) && s.ctx.Err() == nil) {
+				continue
+			}
+			s.errCh <- err
+		}
 	}()
+}
+
+// AddBot creates a bot with the swarm settings and starts it if the swarm runs
+// strategy may be nil to keep the default
+func (s *Swarm) AddBot(strategy Strategy) (*Bot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil, ErrSwarmStopped
+	}
+	if s.maxBots > 0 && len(s.bots) >= s.maxBots {
+		return nil, fmt.Errorf("%w: the limit is %d", ErrTooManyBots, s.maxBots)
+	}
+
+	b := s.newBot()
+	if strategy != nil {
+		s.attachHistory(b.ID, strategy)
+		b.SetStrategy(strategy)
+	}
+	s.bots = append(s.bots, b)
+
+	if s.ctx != nil {
+		s.startBot(b)
+		s.logger.Info("bot added", "bot_id", b.ID, "bots", len(s.bots))
+	}
+	return b, nil
+}
+
+// RemoveBot stops a bot and removes it from the swarm
+// Blocks until the bot's loop has exited
+func (s *Swarm) RemoveBot(id int) error {
+	s.mu.Lock()
+	idx := -1
+	for i, b := range s.bots {
+		if b.ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		s.mu.Unlock()
+		return fmt.Errorf("bot %d: %w", id, ErrBotNotFound)
+	}
+	s.bots = append(s.bots[:idx:idx], s.bots[idx+1:]...)
+	run := s.running[id]
+	delete(s.running, id)
+	s.mu.Unlock()
+
+	if run != nil {
+		run.cancel()
+		<-run.done
+	}
+	s.logger.Info("bot removed", "bot_id", id)
+	return nil
+}
+
+// Scale adds or removes bots until the swarm has n bots
+// New bots use the default strategy; removal stops the newest bots first
+// Returns the number of bots after scaling
+func (s *Swarm) Scale(n int) (int, error) {
+	if n < 0 {
+		return 0, fmt.Errorf("bot count must not be negative")
+	}
+	if s.maxBots > 0 && n > s.maxBots {
+		return s.BotCount(), fmt.Errorf("%w: %d requested, the limit is %d", ErrTooManyBots, n, s.maxBots)
+	}
+
+	s.scaleMu.Lock()
+	defer s.scaleMu.Unlock()
+
+	for {
+		bots := s.Bots()
+		switch {
+		case len(bots) < n:
+			if _, err := s.AddBot(nil); err != nil {
+				return len(bots), err
+			}
+		case len(bots) > n:
+			// ignore ErrBotNotFound: the bot may have been removed concurrently
+			newest := bots[len(bots)-1]
+			if err := s.RemoveBot(newest.ID); err != nil && !errors.Is(err, ErrBotNotFound) {
+				return len(bots), err
+			}
+		default:
+			return n, nil
+		}
+	}
+}
+
+// TrackReceipts polls the receipts of txs sent before a restart
+// Outcomes reach the swarm observer like those of any other tx
+// Must be called before Stop
+func (s *Swarm) TrackReceipts(txs []TxInfo) {
+	if s.client == nil {
+		return
+	}
+	for _, tx := range txs {
+		logger := s.logger.With("bot_id", tx.BotID)
+		s.receipts.track(func(ctx context.Context) {
+			waitReceipt(ctx, s.client, s.observer, logger, tx)
+		})
+	}
+}
+
+// Stop shuts the swarm down gracefully and returns its final snapshot
+// Bots stop issuing swaps and txs, a send in progress is completed, and
+// with waitReceipts the receipts of sent txs are awaited
+// ctx bounds the whole wait: on expiry the receipt polls are abandoned and
+// the snapshot is returned with ctx.Err() (PendingTxs counts the abandoned)
+func (s *Swarm) Stop(ctx context.Context, waitReceipts bool) (*Snapshot, error) {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil, fmt.Errorf("swarm not started")
+	}
+
+	s.logger.Info("stopping swarm", "wait_receipts", waitReceipts, "pending_txs", s.receipts.count())
+	cancel()
+
+	err := s.Wait(ctx)
+	if err == nil && waitReceipts {
+		err = s.receipts.wait(ctx)
+	}
+
+	// snapshot before abandoning polls so PendingTxs reports them
+	snap := s.Snapshot()
+	s.receipts.stop()
+	if err != nil {
+		s.logger.Warn("swarm stop timed out", "pending_txs", snap.PendingTxs, "error", err)
+	}
+	return snap, err
+}
+
+// Wait blocks until every bot loop has exited after the swarm context is
+// done (or Stop was called), or until ctx expires
+// Returns immediately if the swarm was never started
+func (s *Swarm) Wait(ctx context.Context) error {
+	s.mu.Lock()
+	started := s.ctx != nil
+	s.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Snapshot returns a consistent view of the pool and the bots
+func (s *Swarm) Snapshot() *Snapshot {
+	snap := &Snapshot{
+		Time:       time.Now(),
+		Pool:       s.pool.Snapshot(),
+		Bots:       s.BotStatuses(),
+		PendingTxs: s.receipts.count(),
+	}
+	for _, pool := range s.pools {
+		snap.Pools = append(snap.Pools, pool.amm.Snapshot())
+	}
+	return snap
+}
+
+// Bot returns a bot by ID
+func (s *Swarm) Bot(id int) (*Bot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.bots {
+		if b.ID == id {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("bot %d: %w", id, ErrBotNotFound)
+}
+
+// PauseBot stops a bot from trading without removing it
+func (s *Swarm) PauseBot(id int) error {
+	b, err := s.Bot(id)
+	if err != nil {
+		return err
+	}
+	b.Pause()
+	s.logger.Info("bot paused", "bot_id", id)
+	return nil
+}
+
+// ResumeBot lets a paused bot trade again
+func (s *Swarm) ResumeBot(id int) error {
+	b, err := s.Bot(id)
+	if err != nil {
+		return err
+	}
+	b.Resume()
+	s.logger.Info("bot resumed", "bot_id", id)
+	return nil
+}
+
+// SetBotStrategy replaces the strategy of a bot
+func (s *Swarm) SetBotStrategy(id int, strategy Strategy) error {
+	b, err := s.Bot(id)
+	if err != nil {
+		return err
+	}
+	s.attachHistory(b.ID, strategy)
+	b.SetStrategy(strategy)
+	s.logger.Info("bot strategy changed", "bot_id", id, "strategy", strategy.Name())
+	return nil
+}
+
+// SetTxInterval changes the real TX cadence of every bot, including future ones
+func (s *Swarm) SetTxInterval(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("tx interval must be positive")
+	}
+
+	s.mu.Lock()
+	s.txInterval = d
+	bots := append([]*Bot(nil), s.bots...)
+	s.mu.Unlock()
+
+	for _, b := range bots {
+		b.SetTxInterval(d)
+	}
+	s.logger.Info("tx interval changed", "tx_interval", d)
+	return nil
+}
+
+// TxInterval returns the real TX cadence applied to bots
+func (s *Swarm) TxInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.txInterval
+}
+
+// BotStatuses returns the status of every bot, in creation order
+func (s *Swarm) BotStatuses() []BotStatus {
+	s.mu.Lock()
+	bots := append([]*Bot(nil), s.bots...)
+	running := make(map[int]bool, len(s.running))
+	for id := range s.running {
+		running[id] = true
+	}
+	s.mu.Unlock()
+
+	statuses := make([]BotStatus, len(bots))
+	for i, b := range bots {
+		tokenA, tokenB := b.pool.Tokens()
+		statuses[i] = BotStatus{
+			ID:         b.ID,
+			Pool:       tokenA + "/" + tokenB,
+			Strategy:   b.Strategy().Name(),
+			Running:    running[b.ID],
+			Paused:     b.Paused(),
+			RealTX:     b.CanSendRealTX(),
+			TxInterval: b.TxInterval().String(),
+			Portfolio:  b.Portfolio().Snapshot(b.pool.PriceAInB()),
+		}
+	}
+	return statuses
+}
+
+// SetTransferAmount sets the amount of each ERC20 transfer, for every bot
+// including future ones
+// Must be called before Start
+func (s *Swarm) SetTransferAmount(amount domain.TokenAmount) error {
+	return s.SetTransferAmounts(amount)
+}
+
+// SetTransferAmounts spreads ERC20 transfers of several tokens over the bots:
+// bot N transfers amounts[(N-1) % len(amounts)], including future bots
+// Amounts of tokens with an address switch the bots to that token
+// Must be called before Start
+func (s *Swarm) SetTransferAmounts(amounts ...domain.TokenAmount) error {
+	if len(amounts) == 0 {
+		return fmt.Errorf("no transfer amount")
+	}
+	for _, amount := range amounts {
+		if amount.Sign() <= 0 {
+			return fmt.Errorf("transfer amount must be positive, got %s", amount)
+		}
+	}
+	s.transfers = append([]domain.TokenAmount(nil), amounts...)
+	for _, bot := range s.bots {
+		bot.SetTransferAmount(s.transferFor(bot.ID))
+	}
+	return nil
+}
+
+// transferFor returns the transfer amount of a bot
+func (s *Swarm) transferFor(id int) domain.TokenAmount {
+	return s.transfers[(id-1)%len(s.transfers)]
+}
+
+// SetSwapInterval sets the delay between simulated swaps of every bot,
+// including future ones
+// Must be called before Start
+func (s *Swarm) SetSwapInterval(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("swap interval must be positive")
+	}
+	s.swapInterval = d
+	for _, bot := range s.bots {
+		bot.SetSwapInterval(d)
+	}
+	return nil
+}
+
+// SetDefaultStrategy gives every bot, including future ones, a new strategy
+// built by NewStrategy. Bots can still be changed with SetBotStrategy
+// Must be called before Start
+func (s *Swarm) SetDefaultStrategy(name string) error {
+	if _, err := NewStrategy(name, nil); err != nil {
+		return err
+	}
+	s.strategy = name
+	for _, bot := range s.bots {
+		strategy, _ := NewStrategy(name, nil)
+		s.attachHistory(bot.ID, strategy)
+		bot.SetStrategy(strategy)
+	}
+	return nil
+}
+
+// SetMaxBots caps the number of bots AddBot and Scale can reach (0 = no limit)
+// Bots already in the swarm are kept
+// Must be called before Start
+func (s *Swarm) SetMaxBots(n int) error {
+	if n < 0 {
+		return fmt.Errorf("max bots must not be negative")
+	}
+	s.maxBots = n
+	return nil
+}
+
+// SetBotWallet makes a bot send its real txs from its own wallet
+// Bots of the same wallet share a nonce manager: startNonce is only used the
+// first time a wallet is seen. Bots added later use the swarm wallet
+// Must be called before Start
+func (s *Swarm) SetBotWallet(id int, privateKey, walletAddress string, startNonce uint64) error {
+	if s.client == nil {
+		return fmt.Errorf("bot wallets need a swarm created with a client")
+	}
+	b, err := s.Bot(id)
+	if err != nil {
+		return err
+	}
+	manager, ok := s.wallets[strings.ToLower(walletAddress)]
+	if !ok {
+		manager = nonce.NewManager(startNonce)
+		s.wallets[strings.ToLower(walletAddress)] = manager
+	}
+	b.SetWallet(privateKey, walletAddress, manager)
+	return nil
+}
+
+// SetPools spreads the bots over several pools: they take them in turn by
+// ID, bot 1 the first pool, bot 2 the second one... Bots added later follow
+// the same order. The first pool is the swarm pool (Pool, Snapshot.Pool)
+// Bots already created get a fresh portfolio with the default balances
+// Must be called before Start, FundBots and SetPriceHistory
+func (s *Swarm) SetPools(pools ...domain.AMM) error {
+	if len(pools) == 0 {
+		return fmt.Errorf("no pool to trade")
+	}
+	s.pool = pools[0]
+	s.pools = make([]*tradedPool, len(pools))
+	for i, pool := range pools {
+		s.pools[i] = newTradedPool(pool)
+	}
+	for _, bot := range s.bots {
+		pool := s.poolFor(bot.ID)
+		bot.pool = pool.amm
+		bot.Fund(pool.balanceA, pool.balanceB)
+	}
+	return nil
+}
+
+// Pools returns the pools traded by the bots, the swarm pool first
+func (s *Swarm) Pools() []domain.AMM {
+	pools := make([]domain.AMM, len(s.pools))
+	for i, pool := range s.pools {
+		pools[i] = pool.amm
+	}
+	return pools
+}
+
+// FundBots gives every bot a fresh portfolio with the given balances
+// They must be amounts of the pool tokens: same symbols and decimals. With
+// several pools, use FundPoolBots unless every pool has the same tokens
+// Must be called before Start
+func (s *Swarm) FundBots(balanceA, balanceB domain.TokenAmount) error {
+	for _, pool := range s.pools {
+		if err := checkFunding(pool.amm, balanceA, balanceB); err != nil {
+			return err
+		}
+	}
+	for _, pool := range s.pools {
+		s.fund(pool, balanceA, balanceB)
+	}
+	return nil
+}
+
+// FundPoolBots gives the bots trading pool a fresh portfolio with the given
+// balances of the pool tokens
+// Must be called before Start
+func (s *Swarm) FundPoolBots(pool domain.AMM, balanceA, balanceB domain.TokenAmount) error {
+	traded, err := s.tradedPool(pool)
+	if err != nil {
+		return err
+	}
+	if err := checkFunding(pool, balanceA, balanceB); err != nil {
+		return err
+	}
+	s.fund(traded, balanceA, balanceB)
+	return nil
+}
+
+// fund sets the starting balances of the bots of a pool, current and future
+func (s *Swarm) fund(pool *tradedPool, balanceA, balanceB domain.TokenAmount) {
+	pool.balanceA, pool.balanceB = balanceA.Raw(), balanceB.Raw()
+	for _, bot := range s.bots {
+		if s.poolFor(bot.ID) == pool {
+			bot.Fund(pool.balanceA, pool.balanceB)
+		}
+	}
+}
+
+// checkFunding checks bot balances are non-negative amounts of the pool tokens
+func checkFunding(pool domain.AMM, balanceA, balanceB domain.TokenAmount) error {
+	tokenA, tokenB := pool.Snapshot().TokenInfo()
+	if !samePoolToken(balanceA.Token, tokenA) || !samePoolToken(balanceB.Token, tokenB) {
+		return fmt.Errorf("%w: balances in %s (%d decimals) and %s (%d decimals) for the %s (%d decimals) / %s (%d decimals) pool",
+			domain.ErrTokenMismatch, balanceA.Token.Symbol, balanceA.Token.Decimals, balanceB.Token.Symbol, balanceB.Token.Decimals,
+			tokenA.Symbol, tokenA.Decimals, tokenB.Symbol, tokenB.Decimals)
+	}
+	if balanceA.Sign() < 0 || balanceB.Sign() < 0 {
+		return fmt.Errorf("balances must not be negative, got %s and %s", balanceA, balanceB)
+	}
+	return nil
+}
+
+// samePoolToken reports whether an amount of token can fund the pool token
+func samePoolToken(token, poolToken domain.Token) bool {
+	return strings.EqualFold(token.Symbol, poolToken.Symbol) && token.Decimals == poolToken.Decimals
+}
+
+// tradedPool returns the settings of a pool of the swarm
+func (s *Swarm) tradedPool(pool domain.AMM) (*tradedPool, error) {
+	for _, traded := range s.pools {
+		if traded.amm == pool {
+			return traded, nil
+		}
+	}
+	tokenA, tokenB := pool.Tokens()
+	return nil, fmt.Errorf("pool %s/%s is not traded by the swarm", tokenA, tokenB)
+}
+
+// SetPriceHistory sets the candle series read by HistoryAware strategies
+// trading the swarm pool, including the strategies of bots added later
+// Must be called before Start
+func (s *Swarm) SetPriceHistory(series *candles.Series) {
+	s.setHistory(s.pools[0], series)
+}
+
+// SetPoolPriceHistory is SetPriceHistory for the bots trading pool
+// Must be called before Start
+func (s *Swarm) SetPoolPriceHistory(pool domain.AMM, series *candles.Series) error {
+	traded, err := s.tradedPool(pool)
+	if err != nil {
+		return err
+	}
+	s.setHistory(traded, series)
+	return nil
+}
+
+func (s *Swarm) setHistory(pool *tradedPool, series *candles.Series) {
+	pool.history = series
+	for _, bot := range s.bots {
+		if s.poolFor(bot.ID) == pool {
+			s.attachHistory(bot.ID, bot.Strategy())
+		}
+	}
+}
+
+// attachHistory hands the price history of the bot's pool to strategies
+// that use it
+func (s *Swarm) attachHistory(botID int, strategy Strategy) {
+	history := s.poolFor(botID).history
+	if h, ok := strategy.(HistoryAware); ok && history != nil {
+		h.SetHistory(history)
+	}
+}
+
+// SetObserver registers an observer on every bot (metrics, reports...)
+// Must be called before Start
+func (s *Swarm) SetObserver(o Observer) {
+	s.observer = o
+	for _, bot := range s.bots {
+		bot.SetObserver(o)
+	}
+}
+
+// SetLogger sets the structured logger of the swarm and every bot
+// swapLogEvery samples simulated swap records (1 every N swaps per bot)
+// Must be called before Start
+func (s *Swarm) SetLogger(logger *slog.Logger, swapLogEvery int) {
+	s.logger = logger
+	s.swapLogEvery = swapLogEvery
+	for _, bot := range s.bots {
+		bot.SetLogger(logger, swapLogEvery)
+	}
+}
+
+// PoolObserver returns an observer that forwards to o only the events of the
+// bots trading pool, e.g. to report on each pool separately
+func (s *Swarm) PoolObserver(pool domain.AMM, o Observer) Observer {
+	return &poolObserver{swarm: s, pool: pool, next: o}
+}
+
+// poolObserver filters the events of a swarm by the pool of their bot
+// Bots never change pool once the swarm runs, so no lock is needed
+type poolObserver struct {
+	swarm *Swarm
+	pool  domain.AMM
+	next  Observer
+}
+
+func (o *poolObserver) trades(botID int) bool {
+	return o.swarm.poolFor(botID).amm == o.pool
+}
+
+func (o *poolObserver) SwapExecuted(botID int, order *Order, amountOut *big.Int) {
+	if o.trades(botID) {
+		o.next.SwapExecuted(botID, order, amountOut)
+	}
+}
+
+func (o *poolObserver) SwapRejected(botID int, order *Order, err error) {
+	if o.trades(botID) {
+		o.next.SwapRejected(botID, order, err)
+	}
+}
+
+func (o *poolObserver) TxSent(tx TxInfo) {
+	if o.trades(tx.BotID) {
+		o.next.TxSent(tx)
+	}
+}
+
+func (o *poolObserver) TxFailed(tx TxInfo, err error) {
+	if o.trades(tx.BotID) {
+		o.next.TxFailed(tx, err)
+	}
+}
+
+func (o *poolObserver) TxConfirmed(tx TxInfo, receipt *ports.Receipt) {
+	if o.trades(tx.BotID) {
+		o.next.TxConfirmed(tx, receipt)
+	}
+}
+
+func (o *poolObserver) NonceResynced(botID int, from, to uint64) {
+	if o.trades(botID) {
+		o.next.NonceResynced(botID, from, to)
+	}
+}
+
+// Bots returns a copy of the bot list
+func (s *Swarm) Bots() []*Bot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Bot(nil), s.bots...)
+}
 
-	return errCh
+// NonceManager returns the nonce manager shared by the bots, nil without a
+// client. Other senders of the same wallet (e.g. an on-chain pool) must use it
+func (s *Swarm) NonceManager() *nonce.Manager {
+	return s.nonceManager
 }
 
 // Pool returns the shared pool for inspection
-func (s *Swarm) Pool() *domain.Pool {
+func (s *Swarm) Pool() domain.AMM {
 	return s.pool
 }
 
 // BotCount returns the number of bots in the swarm
 func (s *Swarm) BotCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return len(s.bots)
 }
@@ -2,15 +2,19 @@ package swarm
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/nexus-bot-swarm/domain"
+	"github.com/nexus-bot-swarm/ports"
 )
 
 func TestNewSwarm(t *testing.T) {
-	pool := domain.NewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000))
 	swarm := NewSwarm(3, pool)
 
 	if len(swarm.bots) != 3 {
@@ -19,13 +23,16 @@ func TestNewSwarm(t *testing.T) {
 }
 
 func TestSwarm_Start_Stop(t *testing.T) {
-	pool := domain.NewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
 	swarm := NewSwarm(3, pool)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// start swarm
-	errCh := swarm.Start(ctx)
+	errCh, err := swarm.Start(ctx)
+	if err != nil {
+		t.Fatalf("failed to start swarm: %v", err)
+	}
 
 	// let it run
 	time.Sleep(100 * time.Millisecond)
@@ -60,13 +67,16 @@ loop:
 }
 
 func TestSwarm_ConcurrentSwaps(t *testing.T) {
-	pool := domain.NewPool("ETH", "USDC", big.NewInt(10000000), big.NewInt(20000000))
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(10000000), big.NewInt(20000000))
 	swarm := NewSwarm(5, pool)
 
-	initialReserveA := new(big.Int).Set(pool.ReserveA)
+	initialReserveA := pool.Snapshot().ReserveA
 
 	ctx, cancel := context.WithCancel(context.Background())
-	errCh := swarm.Start(ctx)
+	errCh, err := swarm.Start(ctx)
+	if err != nil {
+		t.Fatalf("failed to start swarm: %v", err)
+	}
 
 	// let bots swap (500ms ticker, 5 bots, wait 1.5s for multiple swaps)
 	time.Sleep(1500 * time.Millisecond)
@@ -78,10 +88,445 @@ func TestSwarm_ConcurrentSwaps(t *testing.T) {
 	}
 
 	// verify swaps happened
-	if pool.ReserveA.Cmp(initialReserveA) == 0 {
+	final := pool.Snapshot()
+	if final.ReserveA.Cmp(initialReserveA) == 0 {
 		t.Error("expected reserves to change from concurrent swaps")
 	}
 
 	t.Logf("after concurrent swaps: ReserveA=%s, ReserveB=%s",
-		pool.ReserveA.String(), pool.ReserveB.String())
+		final.ReserveA.String(), final.ReserveB.String())
+}
+
+func TestSwarm_AddRemoveBot_WhileRunning(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(10000000), big.NewInt(20000000))
+	swarm := NewSwarm(1, pool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh, err := swarm.Start(ctx)
+	if err != nil {
+		t.Fatalf("failed to start swarm: %v", err)
+	}
+
+	added, err := swarm.AddBot(nil)
+	if err != nil {
+		t.Fatalf("unexpected error adding bot: %v", err)
+	}
+	if added.ID != 2 {
+		t.Errorf("expected new bot ID 2, got %d", added.ID)
+	}
+
+	// let the new bot trade
+	time.Sleep(700 * time.Millisecond)
+	if trades := added.Portfolio().Snapshot(pool.PriceAInB()).Trades; trades == 0 {
+		t.Error("bot added at runtime should trade")
+	}
+
+	// removing every bot must not close the error channel
+	if err := swarm.RemoveBot(1); err != nil {
+		t.Fatalf("unexpected error removing bot: %v", err)
+	}
+	if err := swarm.RemoveBot(2); err != nil {
+		t.Fatalf("unexpected error removing bot: %v", err)
+	}
+	if err := swarm.RemoveBot(2); !errors.Is(err, ErrBotNotFound) {
+		t.Errorf("expected ErrBotNotFound, got %v", err)
+	}
+
+	select {
+	case err, ok := <-errCh:
+		if !ok {
+			t.Fatal("error channel closed while swarm still running")
+		}
+		t.Fatalf("removed bots should not report errors, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// the swarm still accepts bots after being emptied
+	if _, err := swarm.AddBot(nil); err != nil {
+		t.Fatalf("unexpected error re-adding bot: %v", err)
+	}
+
+	cancel()
+	for range errCh {
+	}
+
+	if _, err := swarm.AddBot(nil); !errors.Is(err, ErrSwarmStopped) {
+		t.Errorf("expected ErrSwarmStopped after shutdown, got %v", err)
+	}
+}
+
+func TestSwarm_Scale(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(10000000), big.NewInt(20000000))
+	swarm := NewSwarm(2, pool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh, err := swarm.Start(ctx)
+	if err != nil {
+		t.Fatalf("failed to start swarm: %v", err)
+	}
+
+	// concurrent ramps must converge to the last requested count
+	var wg sync.WaitGroup
+	for _, n := range []int{10, 4, 7} {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			if _, err := swarm.Scale(n); err != nil {
+				t.Errorf("unexpected error scaling to %d: %v", n, err)
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	if got, err := swarm.Scale(5); err != nil || got != 5 {
+		t.Fatalf("expected 5 bots, got %d (%v)", got, err)
+	}
+	if swarm.BotCount() != 5 {
+		t.Errorf("expected BotCount 5, got %d", swarm.BotCount())
+	}
+	for _, status := range swarm.BotStatuses() {
+		if !status.Running {
+			t.Errorf("bot %d should be running", status.ID)
+		}
+	}
+
+	if _, err := swarm.Scale(-1); err == nil {
+		t.Error("expected error for negative count")
+	}
+
+	cancel()
+	for range errCh {
+	}
+}
+
+func TestSwarm_Start_Twice(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	swarm := NewSwarm(1, pool)
+
+	// Wait on a swarm that never started returns at once
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Second)
+	defer waitCancel()
+	if err := swarm.Wait(waitCtx); err != nil {
+		t.Fatalf("expected Wait to return before Start, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh, err := swarm.Start(ctx)
+	if err != nil {
+		t.Fatalf("failed to start swarm: %v", err)
+	}
+	if _, err := swarm.Start(ctx); !errors.Is(err, ErrSwarmStarted) {
+		t.Errorf("expected ErrSwarmStarted, got %v", err)
+	}
+
+	cancel()
+	for range errCh {
+	}
+	if err := swarm.Wait(waitCtx); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+
+	// a stopped swarm does not start again
+	if _, err := swarm.Start(context.Background()); !errors.Is(err, ErrSwarmStarted) {
+		t.Errorf("expected ErrSwarmStarted after stop, got %v", err)
+	}
+}
+
+// txCounter counts sent and confirmed txs
+type txCounter struct {
+	NopObserver
+	sent, confirmed atomic.Int64
+}
+
+func (c *txCounter) TxSent(TxInfo)                      { c.sent.Add(1) }
+func (c *txCounter) TxConfirmed(TxInfo, *ports.Receipt) { c.confirmed.Add(1) }
+
+func newRealTxSwarm(t *testing.T) (*Swarm, *txCounter, <-chan error) {
+	t.Helper()
+
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	s := NewSwarmWithClient(2, pool, &fakeClient{}, "key", "0xwallet", "", 0)
+	counter := &txCounter{}
+	s.SetObserver(counter)
+	s.SetTxInterval(50 * time.Millisecond)
+
+	errCh, err := s.Start(context.Background())
+	if err != nil {
+		t.Fatalf("failed to start swarm: %v", err)
+	}
+	go func() {
+		for range errCh {
+		}
+	}()
+
+	// let the bots send a few txs, receipts are polled every 2s
+	time.Sleep(200 * time.Millisecond)
+	return s, counter, errCh
+}
+
+func TestSwarm_Stop_WaitsForReceipts(t *testing.T) {
+	s, counter, _ := newRealTxSwarm(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	snap, err := s.Stop(ctx, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if counter.sent.Load() == 0 {
+		t.Fatal("expected txs to be sent")
+	}
+	if counter.confirmed.Load() != counter.sent.Load() {
+		t.Errorf("every sent tx should be confirmed: %d sent, %d confirmed", counter.sent.Load(), counter.confirmed.Load())
+	}
+	if snap.PendingTxs != 0 || len(snap.Bots) != 2 || snap.Pool.ReserveA == nil {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+
+	// no tx is sent after Stop
+	sent := counter.sent.Load()
+	time.Sleep(150 * time.Millisecond)
+	if counter.sent.Load() != sent {
+		t.Error("bots kept sending after Stop")
+	}
+}
+
+func TestSwarm_Stop_Timeout(t *testing.T) {
+	s, _, _ := newRealTxSwarm(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	snap, err := s.Stop(ctx, true)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if snap.PendingTxs == 0 {
+		t.Error("snapshot should report the abandoned receipts")
+	}
+}
+
+func TestSwarm_Stop_NotStarted(t *testing.T) {
+	s := NewSwarm(1, domain.MustNewPool("ETH", "USDC", big.NewInt(1000), big.NewInt(2000)))
+	if _, err := s.Stop(context.Background(), false); err == nil {
+		t.Error("expected error when stopping a swarm that never started")
+	}
+}
+
+func TestSwarm_SetPriceHistory(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	s := NewSwarm(0, pool)
+
+	before := NewMomentumStrategy(2, 4, 10)
+	if _, err := s.AddBot(before); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history := priceHistory(1, 1, 2, 2)
+	s.SetPriceHistory(history)
+
+	after := NewMomentumStrategy(2, 4, 10)
+	if _, err := s.AddBot(after); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, strategy := range map[string]*MomentumStrategy{"existing": before, "added": after} {
+		if strategy.history != history {
+			t.Errorf("%s bot strategy did not get the price history", name)
+		}
+	}
+}
+
+func TestSwarm_ConfiguredSettings(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	s := NewSwarm(2, pool)
+	s.SetPriceHistory(priceHistory(1, 1, 2, 2))
+
+	if err := s.SetDefaultStrategy("grid"); err == nil {
+		t.Error("expected error for an unknown strategy")
+	}
+	if err := s.SetDefaultStrategy("momentum"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.SetSwapInterval(0); err == nil {
+		t.Error("expected error for a zero swap interval")
+	}
+	if err := s.SetSwapInterval(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.SetMaxBots(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	added, err := s.AddBot(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, bot := range s.Bots() {
+		strategy, ok := bot.Strategy().(*MomentumStrategy)
+		if !ok || strategy.history == nil {
+			t.Errorf("bot %d: expected a momentum strategy with history, got %s", bot.ID, bot.Strategy().Name())
+		}
+		if bot.swapInterval != time.Second {
+			t.Errorf("bot %d: expected a 1s swap interval, got %s", bot.ID, bot.swapInterval)
+		}
+	}
+	if s.Bots()[0].Strategy() == added.Strategy() {
+		t.Error("bots must not share a strategy instance")
+	}
+
+	if _, err := s.AddBot(nil); !errors.Is(err, ErrTooManyBots) {
+		t.Errorf("expected ErrTooManyBots, got %v", err)
+	}
+	if n, err := s.Scale(4); !errors.Is(err, ErrTooManyBots) || n != 3 {
+		t.Errorf("expected ErrTooManyBots at 3 bots, got %d (%v)", n, err)
+	}
+}
+
+func TestSwarm_FundBots(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	pool.SetDecimals(18, 6)
+	s := NewSwarm(2, pool)
+
+	eth, usdc := domain.Token{Symbol: "ETH", Decimals: 18}, domain.Token{Symbol: "USDC", Decimals: 6}
+	balanceA, _ := domain.ParseTokenAmount("1.5", eth)
+	balanceB, _ := domain.ParseTokenAmount("3000", usdc)
+	if err := s.FundBots(balanceA, balanceB); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	added, _ := s.AddBot(nil)
+	for _, bot := range append(s.Bots(), added) {
+		snap := bot.Portfolio().Snapshot(pool.PriceAInB())
+		if snap.BalanceA.Cmp(balanceA.Raw()) != 0 || snap.BalanceB.Cmp(balanceB.Raw()) != 0 {
+			t.Errorf("bot %d: unexpected balances %s, %s", bot.ID, snap.BalanceA, snap.BalanceB)
+		}
+	}
+
+	// USDC with 18 decimals is not the pool's USDC
+	wrong := domain.NewTokenAmount(domain.Token{Symbol: "USDC", Decimals: 18}, big.NewInt(1))
+	if err := s.FundBots(balanceA, wrong); !errors.Is(err, domain.ErrTokenMismatch) {
+		t.Errorf("expected ErrTokenMismatch, got %v", err)
+	}
+	if err := s.FundBots(balanceB, balanceA); !errors.Is(err, domain.ErrTokenMismatch) {
+		t.Errorf("expected ErrTokenMismatch for swapped tokens, got %v", err)
+	}
+}
+
+func TestSwarm_SetPools(t *testing.T) {
+	eth := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	btc := domain.MustNewPool("BTC", "DAI", big.NewInt(1000000), big.NewInt(50000000))
+	s := NewSwarm(3, eth)
+	if err := s.SetPools(); err == nil {
+		t.Error("expected error without pools")
+	}
+	if err := s.SetPools(eth, btc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	added, _ := s.AddBot(nil)
+
+	// bots take the pools in turn, added bots included
+	for _, bot := range append(s.Bots(), added) {
+		want := domain.AMM(eth)
+		if bot.ID%2 == 0 {
+			want = btc
+		}
+		if bot.pool != want {
+			t.Errorf("bot %d trades the wrong pool", bot.ID)
+		}
+	}
+	statuses := s.BotStatuses()
+	if statuses[0].Pool != "ETH/USDC" || statuses[1].Pool != "BTC/DAI" {
+		t.Errorf("unexpected bot pools: %+v", statuses)
+	}
+	if snap := s.Snapshot(); snap.Pool.TokenA != "ETH" || len(snap.Pools) != 2 || snap.Pools[1].TokenA != "BTC" {
+		t.Errorf("unexpected snapshot pools: %+v", snap.Pools)
+	}
+
+	// each pool funds its own bots
+	btcBalance := domain.Token{Symbol: "BTC"}.Amount(big.NewInt(7))
+	daiBalance := domain.Token{Symbol: "DAI"}.Amount(big.NewInt(9))
+	if err := s.FundBots(btcBalance, daiBalance); !errors.Is(err, domain.ErrTokenMismatch) {
+		t.Errorf("expected ErrTokenMismatch funding every pool in BTC, got %v", err)
+	}
+	if err := s.FundPoolBots(btc, btcBalance, daiBalance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.FundPoolBots(domain.MustNewPool("SOL", "DAI", big.NewInt(1000), big.NewInt(1000)), btcBalance, daiBalance); err == nil {
+		t.Error("expected error for a pool outside the swarm")
+	}
+	for _, bot := range s.Bots() {
+		balanceA := bot.Portfolio().Snapshot(1).BalanceA.Int64()
+		if (bot.ID%2 == 0) != (balanceA == 7) {
+			t.Errorf("bot %d: unexpected balance %d", bot.ID, balanceA)
+		}
+	}
+
+	// candles of a pool only reach the strategies trading it
+	history := priceHistory(1, 1, 2, 2)
+	for _, id := range []int{1, 2} {
+		s.SetBotStrategy(id, NewMomentumStrategy(2, 4, 10))
+	}
+	if err := s.SetPoolPriceHistory(btc, history); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	one, _ := s.Bot(1)
+	two, _ := s.Bot(2)
+	if one.Strategy().(*MomentumStrategy).history != nil || two.Strategy().(*MomentumStrategy).history != history {
+		t.Error("the BTC/DAI history should reach bot 2 only")
+	}
+}
+
+func TestSwarm_PoolObserver(t *testing.T) {
+	eth := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	btc := domain.MustNewPool("BTC", "DAI", big.NewInt(1000000), big.NewInt(50000000))
+	s := NewSwarm(2, eth)
+	s.SetPools(eth, btc)
+
+	counter := &countingObserver{}
+	observer := s.PoolObserver(btc, counter)
+	order := &Order{Direction: AToB, AmountIn: big.NewInt(1)}
+	for id := 1; id <= 5; id++ {
+		observer.SwapExecuted(id, order, big.NewInt(1))
+	}
+	if counter.swaps != 2 {
+		t.Errorf("expected only the swaps of bots 2 and 4, got %d", counter.swaps)
+	}
+}
+
+func TestSwarm_SetBotWallet(t *testing.T) {
+	pool := domain.MustNewPool("ETH", "USDC", big.NewInt(1000000), big.NewInt(2000000))
+	if err := NewSwarm(1, pool).SetBotWallet(1, "key2", "0xother", 0); err == nil {
+		t.Error("expected error without a client")
+	}
+
+	s := NewSwarmWithClient(3, pool, &fakeClient{}, "key", "0xWallet", "", 5)
+	if err := s.SetBotWallet(9, "key2", "0xother", 0); !errors.Is(err, ErrBotNotFound) {
+		t.Errorf("expected ErrBotNotFound, got %v", err)
+	}
+	for _, id := range []int{2, 3} {
+		if err := s.SetBotWallet(id, "key2", "0xOther", 40); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	bots := s.Bots()
+	if bots[0].WalletAddress() != "0xWallet" || bots[1].WalletAddress() != "0xOther" {
+		t.Errorf("unexpected wallets: %s, %s", bots[0].WalletAddress(), bots[1].WalletAddress())
+	}
+	if bots[1].nonceManager != bots[2].nonceManager || bots[1].nonceManager == s.NonceManager() {
+		t.Error("bots of the same wallet must share its own nonce manager")
+	}
+	if n := bots[1].nonceManager.Current(); n != 40 {
+		t.Errorf("expected the bot wallet to start at nonce 40, got %d", n)
+	}
+
+	// the swarm wallet keeps the swarm nonce manager, whatever its case
+	if err := s.SetBotWallet(1, "key", "0xwallet", 99); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bots[0].nonceManager != s.NonceManager() || s.NonceManager().Current() != 5 {
+		t.Error("expected the swarm wallet to keep the swarm nonce manager")
+	}
 }